@@ -0,0 +1,102 @@
+package common
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrCalibrationTooShort is returned when the calibration table has less than two points.
+	ErrCalibrationTooShort = errors.New("calibration table must have at least two points")
+	// ErrCalibrationDuplicate is returned when the calibration table has several points with the same raw value.
+	ErrCalibrationDuplicate = errors.New("duplicate raw value in calibration table")
+)
+
+// CalibrationPoint maps a raw sensor reading to the calibrated value.
+type CalibrationPoint struct {
+	Raw   float64 `json:"raw"`
+	Value float64 `json:"value"`
+}
+
+// CalibrationTable is a piecewise-linear calibration table (e.g. of a fuel tank) sorted by raw readings.
+type CalibrationTable []CalibrationPoint
+
+// NewCalibrationTable returns the table of the given points sorted by raw readings.
+func NewCalibrationTable(points []CalibrationPoint) (CalibrationTable, error) {
+	if len(points) < 2 { //nolint:gomnd
+		return nil, ErrCalibrationTooShort
+	}
+
+	t := make(CalibrationTable, len(points))
+	copy(t, points)
+	sort.Slice(t, func(i, j int) bool { return t[i].Raw < t[j].Raw })
+	for i := 1; i < len(t); i++ {
+		if t[i].Raw == t[i-1].Raw {
+			return nil, fmt.Errorf("raw value %v: %w", t[i].Raw, ErrCalibrationDuplicate)
+		}
+	}
+	return t, nil
+}
+
+// Value returns the calibrated value of the raw reading using linear interpolation between the nearest points.
+// Readings outside the table are clamped to its first and last values.
+func (t CalibrationTable) Value(raw float64) float64 {
+	if len(t) == 0 {
+		return raw
+	}
+	i := sort.Search(len(t), func(i int) bool { return t[i].Raw >= raw })
+	switch {
+	case i == 0:
+		return t[0].Value
+	case i == len(t):
+		return t[len(t)-1].Value
+	}
+	lo, hi := t[i-1], t[i]
+	return lo.Value + (raw-lo.Raw)*(hi.Value-lo.Value)/(hi.Raw-lo.Raw)
+}
+
+// ParseCalibrationCSV reads the calibration table from CSV with "raw,value" records.
+// Empty lines, lines starting with # and a non-numeric header line are skipped.
+func ParseCalibrationCSV(r io.Reader) (CalibrationTable, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = 2
+	cr.TrimLeadingSpace = true
+
+	var points []CalibrationPoint
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read calibration csv: %w", err)
+		}
+
+		raw, errRaw := strconv.ParseFloat(strings.TrimSpace(rec[0]), 64)
+		value, errValue := strconv.ParseFloat(strings.TrimSpace(rec[1]), 64)
+		if errRaw != nil || errValue != nil {
+			if line == 1 {
+				continue // header
+			}
+			return nil, fmt.Errorf("parse calibration csv record %d: %w", line, errors.Join(errRaw, errValue))
+		}
+		points = append(points, CalibrationPoint{Raw: raw, Value: value})
+	}
+	return NewCalibrationTable(points)
+}
+
+// ParseCalibrationJSON reads the calibration table from JSON array of {"raw": x, "value": y} objects.
+func ParseCalibrationJSON(r io.Reader) (CalibrationTable, error) {
+	var points []CalibrationPoint
+	if err := json.NewDecoder(r).Decode(&points); err != nil {
+		return nil, fmt.Errorf("read calibration json: %w", err)
+	}
+	return NewCalibrationTable(points)
+}
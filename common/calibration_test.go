@@ -0,0 +1,107 @@
+package common
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCalibrationTable_Value(t *testing.T) {
+	table, err := NewCalibrationTable([]CalibrationPoint{
+		{Raw: 4095, Value: 60},
+		{Raw: 0, Value: 0},
+		{Raw: 1000, Value: 10},
+	})
+	if err != nil {
+		t.Fatalf("NewCalibrationTable() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		raw  float64
+		want float64
+	}{
+		{name: "first point", raw: 0, want: 0},
+		{name: "interpolation", raw: 500, want: 5},
+		{name: "table point", raw: 1000, want: 10},
+		{name: "upper segment", raw: 2547.5, want: 35},
+		{name: "below table", raw: -10, want: 0},
+		{name: "above table", raw: 5000, want: 60},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := table.Value(tt.raw); got != tt.want {
+				t.Errorf("Value() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCalibrationTable(t *testing.T) {
+	if _, err := NewCalibrationTable([]CalibrationPoint{{Raw: 1, Value: 1}}); !errors.Is(err, ErrCalibrationTooShort) {
+		t.Errorf("NewCalibrationTable() error = %v, want %v", err, ErrCalibrationTooShort)
+	}
+	_, err := NewCalibrationTable([]CalibrationPoint{{Raw: 1, Value: 1}, {Raw: 1, Value: 2}})
+	if !errors.Is(err, ErrCalibrationDuplicate) {
+		t.Errorf("NewCalibrationTable() error = %v, want %v", err, ErrCalibrationDuplicate)
+	}
+}
+
+func TestParseCalibrationCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    CalibrationTable
+		wantErr bool
+	}{
+		{
+			name: "with header and comments",
+			data: "raw,litres\n# empty tank\n0,0\n\n1000, 10.5\n4095,60\n",
+			want: CalibrationTable{{Raw: 0, Value: 0}, {Raw: 1000, Value: 10.5}, {Raw: 4095, Value: 60}},
+		},
+		{
+			name: "no header",
+			data: "10,1\n0,0\n",
+			want: CalibrationTable{{Raw: 0, Value: 0}, {Raw: 10, Value: 1}},
+		},
+		{
+			name:    "bad value",
+			data:    "0,0\n10,x\n",
+			wantErr: true,
+		},
+		{
+			name:    "bad fields count",
+			data:    "0,0,0\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCalibrationCSV(strings.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCalibrationCSV() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(got) != len(tt.want) {
+				t.Fatalf("ParseCalibrationCSV() = %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("ParseCalibrationCSV() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCalibrationJSON(t *testing.T) {
+	got, err := ParseCalibrationJSON(strings.NewReader(`[{"raw": 100, "value": 20}, {"raw": 0, "value": 0}]`))
+	if err != nil {
+		t.Fatalf("ParseCalibrationJSON() error = %v", err)
+	}
+	if got.Value(50) != 10 {
+		t.Errorf("Value() = %v, want 10", got.Value(50))
+	}
+	if _, err = ParseCalibrationJSON(strings.NewReader(`{`)); err == nil {
+		t.Errorf("ParseCalibrationJSON() expected error")
+	}
+}
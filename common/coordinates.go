@@ -95,6 +95,30 @@ func ParsePointWGS84(lon string, cardLon CardinalAxis, lat string, cardLat Cardi
 	}, nil
 }
 
+// NewPointWGS84 creates a PointWGS84 from longitude and latitude in decimal degrees.
+// It is the inverse of Float64: -37.661390 longitude becomes 3739.6834;W.
+func NewPointWGS84(lon, lat float64) PointWGS84 {
+	cardLon, cardLat := East, North
+	if lon < 0 {
+		cardLon = West
+	}
+	if lat < 0 {
+		cardLat = South
+	}
+	return PointWGS84{
+		Lon:   AxisWGS84{Coordinate: degreesToDM(lon), Cardinal: cardLon},
+		Lat:   AxisWGS84{Coordinate: degreesToDM(lat), Cardinal: cardLat},
+		Valid: true,
+	}
+}
+
+// degreesToDM converts the decimal degrees into dddmm.mmmm form.
+func degreesToDM(deg float64) float64 {
+	deg = math.Abs(deg)
+	intDeg := math.Trunc(deg)
+	return intDeg*100 + (deg-intDeg)*60 //nolint:gomnd
+}
+
 // LocationXY is the XY location of a coordinate system.
 func (w PointWGS84) LocationXY() Location {
 	return Location{
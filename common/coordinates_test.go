@@ -1,6 +1,7 @@
 package common
 
 import (
+	"math"
 	"reflect"
 	"testing"
 
@@ -622,3 +623,46 @@ func TestPointWGS84_LocationXYZ(t *testing.T) {
 		})
 	}
 }
+
+func TestNewPointWGS84(t *testing.T) {
+	tests := []struct {
+		name     string
+		lon, lat float64
+		want     PointWGS84
+	}{
+		{
+			name: "North East",
+			lon:  37.66139,
+			lat:  55.74337,
+			want: PointWGS84{
+				Lon:   AxisWGS84{Coordinate: 3739.6834, Cardinal: East},
+				Lat:   AxisWGS84{Coordinate: 5544.6022, Cardinal: North},
+				Valid: true,
+			},
+		},
+		{
+			name: "South West",
+			lon:  -0.5,
+			lat:  -33.25,
+			want: PointWGS84{
+				Lon:   AxisWGS84{Coordinate: 30, Cardinal: West},
+				Lat:   AxisWGS84{Coordinate: 3315, Cardinal: South},
+				Valid: true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewPointWGS84(tt.lon, tt.lat)
+			if math.Abs(got.Lon.Coordinate-tt.want.Lon.Coordinate) > 1e-6 ||
+				math.Abs(got.Lat.Coordinate-tt.want.Lat.Coordinate) > 1e-6 ||
+				got.Lon.Cardinal != tt.want.Lon.Cardinal || got.Lat.Cardinal != tt.want.Lat.Cardinal ||
+				got.Valid != tt.want.Valid {
+				t.Errorf("NewPointWGS84() = %v, want %v", got, tt.want)
+			}
+			if math.Abs(got.Lon.Float64()-tt.lon) > 1e-9 || math.Abs(got.Lat.Float64()-tt.lat) > 1e-9 {
+				t.Errorf("NewPointWGS84() = %v, %v, want %v, %v", got.Lon.Float64(), got.Lat.Float64(), tt.lon, tt.lat)
+			}
+		})
+	}
+}
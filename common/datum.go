@@ -0,0 +1,136 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrUnknownDatum is returned when the geodetic datum is not supported.
+var ErrUnknownDatum = errors.New("unknown geodetic datum")
+
+// GeodeticDatum is the reference frame (ellipsoid and its placement) the coordinates are given in.
+type GeodeticDatum string
+
+const (
+	// WGS84 is the World Geodetic System 1984 used by GPS.
+	WGS84 GeodeticDatum = "WGS-84"
+	// PZ9002 is the Russian state geocentric system PZ-90.02 (ПЗ-90.02).
+	PZ9002 GeodeticDatum = "PZ-90.02"
+	// PZ9011 is the Russian state geocentric system PZ-90.11 (ПЗ-90.11) used by GLONASS since 2014.
+	PZ9011 GeodeticDatum = "PZ-90.11"
+)
+
+const arcSecond = math.Pi / (180 * 3600)
+
+// ellipsoid is the reference ellipsoid defined by semi-major axis (m) and flattening.
+type ellipsoid struct {
+	a, f float64
+}
+
+var (
+	ellipsoidWGS84 = ellipsoid{a: 6378137, f: 1 / 298.257223563}
+	ellipsoidPZ90  = ellipsoid{a: 6378136, f: 1 / 298.25784}
+)
+
+// helmert is the 7-parameter transformation into WGS-84: shifts in metres,
+// rotations in arc seconds and scale difference in parts (not ppm).
+type helmert struct {
+	dx, dy, dz float64
+	rx, ry, rz float64
+	m          float64
+}
+
+// datums holds the ellipsoids and the parameters of transformation into WGS-84
+// according to GOST 32453-2017.
+var datums = map[GeodeticDatum]struct {
+	ellipsoid
+	toWGS84 helmert
+}{
+	WGS84:  {ellipsoid: ellipsoidWGS84},
+	PZ9002: {ellipsoid: ellipsoidPZ90, toWGS84: helmert{dx: -0.36, dy: 0.08, dz: 0.18}},
+	PZ9011: {ellipsoid: ellipsoidPZ90, toWGS84: helmert{
+		dx: -0.013, dy: 0.106, dz: 0.022,
+		rx: -0.00230, ry: 0.00354, rz: -0.00421,
+		m: -0.008e-6,
+	}},
+}
+
+// Transform converts geodetic coordinates (degrees and metres above the ellipsoid) from the datum d
+// into the datum to. Transformation between two non WGS-84 datums goes through WGS-84.
+func (d GeodeticDatum) Transform(lon, lat, h float64, to GeodeticDatum) (float64, float64, float64, error) {
+	from, ok := datums[d]
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("transform from %q: %w", d, ErrUnknownDatum)
+	}
+	target, ok := datums[to]
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("transform to %q: %w", to, ErrUnknownDatum)
+	}
+	if d == to {
+		return lon, lat, h, nil
+	}
+
+	x, y, z := from.toECEF(lon, lat, h)
+	x, y, z = from.toWGS84.apply(x, y, z, 1)
+	x, y, z = target.toWGS84.apply(x, y, z, -1)
+	lon, lat, h = target.fromECEF(x, y, z)
+	return lon, lat, h, nil
+}
+
+// apply applies the transformation in forward (sign = 1) or inverse (sign = -1) direction.
+// The inverse uses negated parameters, which is accurate for the small rotations of geodetic datums.
+func (t helmert) apply(x, y, z, sign float64) (float64, float64, float64) {
+	rx, ry, rz := sign*t.rx*arcSecond, sign*t.ry*arcSecond, sign*t.rz*arcSecond
+	s := 1 + sign*t.m
+	return s*(x+rz*y-ry*z) + sign*t.dx,
+		s*(-rz*x+y+rx*z) + sign*t.dy,
+		s*(ry*x-rx*y+z) + sign*t.dz
+}
+
+// toECEF converts geodetic coordinates into the Earth-centered Earth-fixed cartesian ones.
+func (e ellipsoid) toECEF(lon, lat, h float64) (x, y, z float64) {
+	e2 := e.f * (2 - e.f)
+	sinLat, cosLat := math.Sincos(lat * math.Pi / 180) //nolint:gomnd
+	sinLon, cosLon := math.Sincos(lon * math.Pi / 180) //nolint:gomnd
+	n := e.a / math.Sqrt(1-e2*sinLat*sinLat)
+	return (n + h) * cosLat * cosLon, (n + h) * cosLat * sinLon, (n*(1-e2) + h) * sinLat
+}
+
+// fromECEF converts Earth-centered Earth-fixed cartesian coordinates into the geodetic ones.
+func (e ellipsoid) fromECEF(x, y, z float64) (lon, lat, h float64) {
+	const iterations = 10
+
+	e2 := e.f * (2 - e.f)
+	p := math.Hypot(x, y)
+	if p == 0 {
+		b := e.a * (1 - e.f)
+		return 0, math.Copysign(90, z), math.Abs(z) - b //nolint:gomnd
+	}
+	phi := math.Atan2(z, p*(1-e2))
+	for i := 0; i < iterations; i++ {
+		sinPhi := math.Sin(phi)
+		n := e.a / math.Sqrt(1-e2*sinPhi*sinPhi)
+		h = p/math.Cos(phi) - n
+		phi = math.Atan2(z, p*(1-e2*n/(n+h)))
+	}
+	return math.Atan2(y, x) * 180 / math.Pi, phi * 180 / math.Pi, h //nolint:gomnd
+}
+
+// Transform returns the location converted from the datum from into the datum to.
+// Height is taken into account only for the XYZ locations.
+func (l Location) Transform(from, to GeodeticDatum) (Location, error) {
+	var h float64
+	if l.Type.Is3D() {
+		h = l.Z
+	}
+	lon, lat, h, err := from.Transform(l.X, l.Y, h, to)
+	if err != nil {
+		return l, err
+	}
+	l.X, l.Y = lon, lat
+	if l.Type.Is3D() {
+		l.Z = h
+	}
+	return l, nil
+}
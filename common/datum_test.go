@@ -0,0 +1,105 @@
+package common
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+// distance returns the approximate distance in metres between two close points given in degrees.
+func distance(lon1, lat1, lon2, lat2 float64) float64 {
+	const earthRadius = 6371000
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180 * math.Cos(lat1*math.Pi/180)
+	return earthRadius * math.Hypot(dLat, dLon)
+}
+
+func TestGeodeticDatum_Transform(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    GeodeticDatum
+		to      GeodeticDatum
+		lon     float64
+		lat     float64
+		h       float64
+		minDist float64
+		maxDist float64
+		maxDH   float64
+	}{
+		{
+			name: "WGS-84 identity",
+			from: WGS84, to: WGS84,
+			lon: 37.61556, lat: 55.75222, h: 150,
+		},
+		{
+			name: "PZ-90.02 to WGS-84 Moscow",
+			from: PZ9002, to: WGS84,
+			lon: 37.61556, lat: 55.75222, h: 150,
+			minDist: 0.05, maxDist: 1, maxDH: 2,
+		},
+		{
+			name: "PZ-90.11 to WGS-84 Novosibirsk",
+			from: PZ9011, to: WGS84,
+			lon: 82.92043, lat: 55.03020, h: 160,
+			minDist: 0.01, maxDist: 0.5, maxDH: 2,
+		},
+		{
+			name: "PZ-90.11 to PZ-90.02 Vladivostok",
+			from: PZ9011, to: PZ9002,
+			lon: 131.88535, lat: 43.11981, h: 10,
+			minDist: 0.05, maxDist: 1, maxDH: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lon, lat, h, err := tt.from.Transform(tt.lon, tt.lat, tt.h, tt.to)
+			if err != nil {
+				t.Fatalf("Transform() error = %v", err)
+			}
+			d := distance(tt.lon, tt.lat, lon, lat)
+			if d < tt.minDist || d > tt.maxDist {
+				t.Errorf("Transform() shift = %.3f m, want within [%.3f, %.3f]", d, tt.minDist, tt.maxDist)
+			}
+			if math.Abs(h-tt.h) > tt.maxDH {
+				t.Errorf("Transform() height = %.3f, want %.3f ± %.3f", h, tt.h, tt.maxDH)
+			}
+
+			// the inverse transformation must return to the origin
+			lon, lat, h, err = tt.to.Transform(lon, lat, h, tt.from)
+			if err != nil {
+				t.Fatalf("Transform() inverse error = %v", err)
+			}
+			if d = distance(tt.lon, tt.lat, lon, lat); d > 1e-6 || math.Abs(h-tt.h) > 1e-6 {
+				t.Errorf("Transform() round trip diverged by %g m horizontally and %g m vertically", d, h-tt.h)
+			}
+		})
+	}
+}
+
+func TestGeodeticDatum_TransformUnknown(t *testing.T) {
+	if _, _, _, err := GeodeticDatum("SK-42").Transform(0, 0, 0, WGS84); !errors.Is(err, ErrUnknownDatum) {
+		t.Errorf("Transform() error = %v, want %v", err, ErrUnknownDatum)
+	}
+	if _, _, _, err := WGS84.Transform(0, 0, 0, "SK-42"); !errors.Is(err, ErrUnknownDatum) {
+		t.Errorf("Transform() error = %v, want %v", err, ErrUnknownDatum)
+	}
+}
+
+func TestLocation_Transform(t *testing.T) {
+	l := Location{
+		Coordinates: geom.Coordinates{XY: geom.XY{X: 37.61556, Y: 55.75222}, Type: geom.DimXY},
+		Valid:       true,
+	}
+	got, err := l.Transform(PZ9002, WGS84)
+	if err != nil {
+		t.Fatalf("Transform() error = %v", err)
+	}
+	if got.Type != geom.DimXY || got.Z != 0 || !got.Valid {
+		t.Errorf("Transform() = %v, want 2D valid location", got)
+	}
+	if got.XY == l.XY {
+		t.Errorf("Transform() did not change coordinates")
+	}
+}
@@ -20,6 +20,46 @@ const (
 	DigInput   = "dinput"
 	DigOutput  = "doutput"
 	AnInput    = "ainput"
+	// Datum is the geodetic datum the position was originally reported in.
+	Datum = "datum"
+	// Event is the name of the event that initiated sending of the position.
+	Event = "event"
+	// EventData is the data characterizing the event.
+	EventData = "event_data"
+	// EventInput is the number of the input which state change is the event.
+	EventInput = "event_input"
+	// EventOutput is the number of the output which overload is the event.
+	EventOutput = "event_output"
+	// Ignition is the ignition state: 1 - on, 0 - off.
+	Ignition = "ignition"
+	// Alarm is set to 1 when the position reports an alarm.
+	Alarm = "alarm"
+	// State is the operating mode of the device.
+	State = "state"
+	// Power is the main (external) power supply voltage, V.
+	Power = "power"
+	// Battery is the backup battery voltage, V.
+	Battery = "battery"
+	// InternalBattery is the internal battery voltage, V.
+	InternalBattery = "int_battery"
+	// FuelLevel is the calibrated fuel level, l. Per sensor values are keyed as fuel_level_N.
+	FuelLevel = "fuel_level"
+	// FuelUsed is the total fuel consumption, l.
+	FuelUsed = "fuel_used"
+	// Temperature is the temperature sensor value, °C. Per sensor values are keyed as temp_N.
+	Temperature = "temp"
+	// Counter is the counting input value. Per sensor values are keyed as counter_N.
+	Counter = "counter"
+	// RPM is the engine speed, rpm.
+	RPM = "rpm"
+	// EngineHours is the total engine operating time, h.
+	EngineHours = "engine_hours"
+	// EngineTemperature is the engine coolant temperature, °C.
+	EngineTemperature = "engine_temp"
+	// AxleLoad is the axle load, kg. Per axle values are keyed as axle_load_N.
+	AxleLoad = "axle_load"
+	// DriverID is the identifier of the driver, e.g. the iButton key code.
+	DriverID = "driver_id"
 )
 
 var _ zerolog.LogObjectMarshaler = (*Position)(nil)
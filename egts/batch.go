@@ -0,0 +1,191 @@
+package egts
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxFrameDataLength is the maximum length of the SFRD field: the whole packet is limited to 65535 bytes
+// including the longest header (16 bytes) and SFRCS (2 bytes).
+const MaxFrameDataLength = 65517
+
+var (
+	// ErrFrameDataTooLarge is returned when the encoded SFRD does not fit into FDL limit.
+	ErrFrameDataTooLarge = errors.New("frame data length exceeds the limit")
+	// ErrRecordTooLarge is returned when a single record does not fit into a packet.
+	ErrRecordTooLarge = errors.New("record does not fit into a packet")
+	// ErrBatchHeader is returned when the header template of BatchEncoder sets encryption or compression:
+	// the size of the encrypted or compressed SFRD is not known before encoding.
+	ErrBatchHeader = errors.New("batch header must not be encrypted or compressed")
+)
+
+// Batch is an encoded APPDATA packet with the numbers of the records it carries.
+// Use it to match EGTS_PT_RESPONSE and EGTS_SR_RECORD_RESPONSE acknowledgements with the sent records.
+type Batch struct {
+	PacketIdentifier uint16
+	RecordNumbers    []uint16
+	Data             []byte
+}
+
+// BatchEncoder packs service data records into as many APPDATA packets as needed.
+type BatchEncoder struct {
+	// Header is the template of the packets (version, key, flags and routing fields).
+	// PID, PT, FDL and SFRD are set by the encoder. Encryption and compression are rejected with ErrBatchHeader.
+	Header Packet
+	// BufferSize limits the size of the whole packet. It is the receiver's buffer size
+	// (see SrTermIdentity.BufferSize), 0 means that only FDL limit is applied.
+	BufferSize uint16
+
+	seq sequence
+}
+
+// NewBatchEncoder returns encoder of unrouted and unencrypted packets for the receiver with the given buffer size.
+func NewBatchEncoder(bufferSize uint16) *BatchEncoder {
+	return &BatchEncoder{
+		Header:     defaultHeader(),
+		BufferSize: bufferSize,
+	}
+}
+
+// defaultHeader returns the header template of unrouted and unencrypted packets.
+func defaultHeader() Packet {
+	return Packet{
+		ProtocolVersion: 1,
+		Prefix:          "00",
+		Route:           "0",
+		EncryptionAlg:   "00",
+		Compression:     "0",
+		Priority:        "00",
+	}
+}
+
+// Encode assigns record numbers to the records, packs them into packets in the given order
+// and assigns packet identifiers. The records are updated with the assigned numbers.
+// The size of every record is checked before the numbers are taken, on error the records are left unchanged.
+func (b *BatchEncoder) Encode(records []ServiceDataRecord, opt ...func(*Options)) ([]Batch, error) {
+	limit, err := b.frameDataLimit()
+	if err != nil {
+		return nil, err
+	}
+	sizes, err := b.recordSizes(records, limit, opt...)
+	if err != nil {
+		return nil, err
+	}
+
+	numbered := append([]ServiceDataRecord(nil), records...)
+	var (
+		result []Batch
+		start  int
+		size   int
+	)
+	for i := range numbered {
+		if size+sizes[i] > limit {
+			batch, err := b.encodePacket(numbered[start:i], opt...)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, batch)
+			start, size = i, 0
+		}
+		numbered[i].RecordNumber = b.sequence().nextRecordNumber()
+		size += sizes[i]
+	}
+	if start < len(numbered) {
+		batch, err := b.encodePacket(numbered[start:], opt...)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, batch)
+	}
+
+	for i := range records {
+		records[i].RecordNumber = numbered[i].RecordNumber
+	}
+	return result, nil
+}
+
+// recordSizes returns the encoded sizes of the records checking that each one fits into the limit.
+// The records are validated if the options ask for it.
+func (b *BatchEncoder) recordSizes(records []ServiceDataRecord, limit int, opt ...func(*Options)) ([]int, error) {
+	options := &Options{}
+	for _, o := range opt {
+		o(options)
+	}
+
+	sizes := make([]int, len(records))
+	for i := range records {
+		if options.Validate {
+			if err := records[i].Validate(); err != nil {
+				return nil, fmt.Errorf("failed to validate record #%d: %w", i, err)
+			}
+		}
+		one := ServiceDataSet{records[i]}
+		rec, err := one.Encode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode record #%d: %w", i, err)
+		}
+		if len(rec) > limit {
+			return nil, fmt.Errorf("record #%d of %d bytes, limit %d: %w", i, len(rec), limit, ErrRecordTooLarge)
+		}
+		sizes[i] = len(rec)
+	}
+	return sizes, nil
+}
+
+// sequence returns the sequence of the encoder, the global one by default.
+func (b *BatchEncoder) sequence() sequence {
+	if b.seq == nil {
+		return globalSequence{}
+	}
+	return b.seq
+}
+
+// frameDataLimit returns the maximum SFRD length for the header template and buffer size.
+func (b *BatchEncoder) frameDataLimit() (int, error) {
+	if b.Header.EncryptionAlg != "00" || b.Header.Compression != "0" {
+		return 0, fmt.Errorf("ENA %s, CMP %s: %w", b.Header.EncryptionAlg, b.Header.Compression, ErrBatchHeader)
+	}
+
+	limit := MaxFrameDataLength
+	if b.BufferSize == 0 {
+		return limit, nil
+	}
+
+	headerLen := int(b.Header.HeaderLength)
+	if headerLen == 0 {
+		headerLen = DefaultHeaderLen
+		if b.Header.Route == "1" {
+			headerLen += 5
+		}
+	}
+	if bufLimit := int(b.BufferSize) - headerLen - 2; bufLimit < limit {
+		limit = bufLimit
+	}
+	if limit <= 0 {
+		return 0, fmt.Errorf("buffer size %d: %w", b.BufferSize, ErrRecordTooLarge)
+	}
+	return limit, nil
+}
+
+// encodePacket encodes the records into APPDATA packet.
+func (b *BatchEncoder) encodePacket(set ServiceDataSet, opt ...func(*Options)) (Batch, error) {
+	p := b.Header
+	p.PacketIdentifier = b.sequence().nextPacketIdentifier()
+	p.PacketType = PtAppdataPacket
+	p.ServicesFrameData = &set
+
+	data, err := p.Encode(opt...)
+	if err != nil {
+		return Batch{}, fmt.Errorf("failed to encode packet %d: %w", p.PacketIdentifier, err)
+	}
+
+	batch := Batch{
+		PacketIdentifier: p.PacketIdentifier,
+		RecordNumbers:    make([]uint16, 0, len(set)),
+		Data:             data,
+	}
+	for _, r := range set {
+		batch.RecordNumbers = append(batch.RecordNumbers, r.RecordNumber)
+	}
+	return batch, nil
+}
@@ -0,0 +1,123 @@
+package egts
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testBatchRecords(n int) []ServiceDataRecord {
+	records := make([]ServiceDataRecord, 0, n)
+	for i := 0; i < n; i++ {
+		records = append(records, ServiceDataRecord{
+			SourceServiceOnDevice:    "1",
+			RecipientServiceOnDevice: "0",
+			Group:                    "0",
+			RecordProcessingPriority: "10",
+			TimeFieldExists:          "0",
+			EventIDFieldExists:       "0",
+			ObjectIDFieldExists:      "1",
+			ObjectIdentifier:         133552,
+			SourceServiceType:        TeledataService,
+			RecipientServiceType:     TeledataService,
+			RecordDataSet: RecordDataSet{
+				RecordData{
+					SubrecordType: SrAbsCntrDataType,
+					SubrecordData: &SrAbsCntrData{CounterNumber: uint8(i), CounterValue: uint32(i)},
+				},
+			},
+		})
+	}
+	return records
+}
+
+func TestBatchEncoder_Encode(t *testing.T) {
+	const bufferSize = 128
+	records := testBatchRecords(20)
+
+	batches, err := NewBatchEncoder(bufferSize).Encode(records)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Greater(t, len(batches), 1)
+
+	var rns []uint16
+	for _, b := range batches {
+		assert.LessOrEqual(t, len(b.Data), bufferSize)
+
+		p := Packet{}
+		if !assert.NoError(t, p.Decode(b.Data)) {
+			return
+		}
+		assert.Equal(t, b.PacketIdentifier, p.PacketIdentifier)
+		assert.Equal(t, PtAppdataPacket, p.PacketType)
+
+		set, ok := p.ServicesFrameData.(*ServiceDataSet)
+		if assert.True(t, ok) && assert.Len(t, *set, len(b.RecordNumbers)) {
+			for i, r := range *set {
+				assert.Equal(t, b.RecordNumbers[i], r.RecordNumber)
+			}
+		}
+		rns = append(rns, b.RecordNumbers...)
+	}
+
+	if assert.Len(t, rns, len(records)) {
+		for i := range records {
+			assert.Equal(t, records[i].RecordNumber, rns[i])
+		}
+	}
+}
+
+func TestBatchEncoder_EncodeNoLimit(t *testing.T) {
+	batches, err := NewBatchEncoder(0).Encode(testBatchRecords(20))
+	if assert.NoError(t, err) && assert.Len(t, batches, 1) {
+		assert.Len(t, batches[0].RecordNumbers, 20)
+	}
+}
+
+func TestBatchEncoder_EncodeRecordTooLarge(t *testing.T) {
+	_, err := NewBatchEncoder(30).Encode(testBatchRecords(1))
+	assert.True(t, errors.Is(err, ErrRecordTooLarge))
+}
+
+func TestPacket_EncodeFrameDataTooLarge(t *testing.T) {
+	// every record takes 19 bytes, 5000 of them exceed FDL limit
+	set := ServiceDataSet(testBatchRecords(5000))
+	p := NewBatchEncoder(0).Header
+	p.PacketType = PtAppdataPacket
+	p.ServicesFrameData = &set
+
+	_, err := p.Encode()
+	assert.True(t, errors.Is(err, ErrFrameDataTooLarge))
+}
+
+func TestBatchEncoder_EncodeFailureKeepsRecords(t *testing.T) {
+	records := testBatchRecords(3)
+	records[2].RecordDataSet[0].SubrecordData = &SrRaw{Data: make([]byte, 200)}
+	seq := &streamSequence{}
+	enc := BatchEncoder{Header: defaultHeader(), BufferSize: 128, seq: seq}
+
+	batches, err := enc.Encode(records)
+	assert.True(t, errors.Is(err, ErrRecordTooLarge))
+	assert.Nil(t, batches)
+	for _, r := range records {
+		assert.Equal(t, uint16(0), r.RecordNumber)
+	}
+	assert.Equal(t, uint16(0), seq.nextRecordNumber(), "record numbers are spent")
+	assert.Equal(t, uint16(0), seq.nextPacketIdentifier(), "packet identifiers are spent")
+}
+
+func TestBatchEncoder_EncodeHeader(t *testing.T) {
+	for _, header := range []func(p *Packet){
+		func(p *Packet) { p.EncryptionAlg = "01" },
+		func(p *Packet) { p.Compression = "1" },
+	} {
+		enc := NewBatchEncoder(0)
+		header(&enc.Header)
+		records := testBatchRecords(1)
+		_, err := enc.Encode(records)
+		assert.True(t, errors.Is(err, ErrBatchHeader))
+		assert.Equal(t, uint16(0), records[0].RecordNumber)
+	}
+}
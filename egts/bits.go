@@ -0,0 +1,17 @@
+package egts
+
+import "fmt"
+
+// byteBits holds the binary representation of every byte as "%08b" formats it.
+var byteBits = func() (table [256]string) {
+	for i := range table {
+		table[i] = fmt.Sprintf("%08b", i)
+	}
+	return table
+}()
+
+// bitString returns the flags byte as the string of 8 bits, the most significant first.
+// Unlike fmt.Sprintf it does not allocate, the flags are decoded for every record.
+func bitString(b byte) string {
+	return byteBits[b]
+}
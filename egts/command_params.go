@@ -0,0 +1,379 @@
+package egts
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ParamType is the type of the terminal parameter value.
+type ParamType uint8
+
+// Types of the terminal parameter values. Numbers are little-endian, strings are sent without terminator.
+const (
+	ParamBool   ParamType = iota + 1 // ParamBool is BOOLEAN value of 1 byte.
+	ParamByte                        // ParamByte is BYTE value.
+	ParamUshort                      // ParamUshort is USHORT value of 2 bytes.
+	ParamUint                        // ParamUint is UINT (INT) value of 4 bytes.
+	ParamString                      // ParamString is STRING value.
+)
+
+// String returns the name of the type.
+func (t ParamType) String() string {
+	switch t {
+	case ParamBool:
+		return "BOOLEAN"
+	case ParamByte:
+		return "BYTE"
+	case ParamUshort:
+		return "USHORT"
+	case ParamUint:
+		return "UINT"
+	case ParamString:
+		return "STRING"
+	default:
+		return fmt.Sprintf("type_%d", uint8(t))
+	}
+}
+
+// Codes of the terminal commands (CCD field of CT_COM with ActParams action).
+const (
+	CmdRawData     uint16 = 0x0000 // CmdRawData (EGTS_RAW_DATA) transmits raw data to the module.
+	CmdTestMode    uint16 = 0x0001 // CmdTestMode (EGTS_TEST_MODE) starts the test mode.
+	CmdConfigReset uint16 = 0x0006 // CmdConfigReset (EGTS_CONFIG_RESET) restores the default settings.
+	CmdSetAuthCode uint16 = 0x0007 // CmdSetAuthCode (EGTS_SET_AUTH_CODE) sets the authorization code.
+	CmdRestart     uint16 = 0x0008 // CmdRestart (EGTS_RESTART) restarts the terminal.
+)
+
+// Codes of the terminal parameters (CCD field of CT_COM with ActGet and ActSet actions).
+const (
+	ParamRadioMuteDelay                 uint16 = 0x0201
+	ParamRadioUnmuteDelay               uint16 = 0x0202
+	ParamGprsAPN                        uint16 = 0x0203
+	ParamServerAddress                  uint16 = 0x0204
+	ParamSimPin                         uint16 = 0x0205
+	ParamIntMemTransmitInterval         uint16 = 0x0206
+	ParamIntMemTransmitAttempts         uint16 = 0x0207
+	ParamTestModeEndDistance            uint16 = 0x020A
+	ParamGarageModeEndDistance          uint16 = 0x020B
+	ParamGarageModePin                  uint16 = 0x020C
+	ParamECallTestNumber                uint16 = 0x020D
+	ParamECallOn                        uint16 = 0x0210
+	ParamECallCrashSignalInternal       uint16 = 0x0211
+	ParamECallCrashSignalExternal       uint16 = 0x0212
+	ParamECallSosButtonTime             uint16 = 0x0213
+	ParamECallNoAutomaticTriggering     uint16 = 0x0214
+	ParamASI15Threshold                 uint16 = 0x0215
+	ParamECallModePin                   uint16 = 0x0216
+	ParamECallCCFT                      uint16 = 0x0217
+	ParamECallInvitationSignalDuration  uint16 = 0x0218
+	ParamECallSendMsgPeriod             uint16 = 0x0219
+	ParamECallAlAckPeriod               uint16 = 0x021A
+	ParamECallMsdMaxTransmissionTime    uint16 = 0x021B
+	ParamECallNadDeregistrationTimer    uint16 = 0x021D
+	ParamECallDialDuration              uint16 = 0x021E
+	ParamECallAutoDialAttempts          uint16 = 0x021F
+	ParamECallManualDialAttempts        uint16 = 0x0220
+	ParamECallManualCanCancel           uint16 = 0x0222
+	ParamECallSmsFallbackNumber         uint16 = 0x0223
+	ParamIgnitionOffFollowUpTime1       uint16 = 0x0224
+	ParamIgnitionOffFollowUpTime2       uint16 = 0x0225
+	ParamTestRegistrationPeriod         uint16 = 0x0242
+	ParamCrashRecordTime                uint16 = 0x0251
+	ParamCrashRecordResolution          uint16 = 0x0252
+	ParamCrashPreRecordTime             uint16 = 0x0253
+	ParamCrashPreRecordResolution       uint16 = 0x0254
+	ParamGnssPowerOffTime               uint16 = 0x0301
+	ParamGnssDataRate                   uint16 = 0x0302
+	ParamGnssMinElevation               uint16 = 0x0303
+	ParamVehicleVIN                     uint16 = 0x0311
+	ParamVehicleType                    uint16 = 0x0312
+	ParamVehiclePropulsionStorageType   uint16 = 0x0313
+	ParamUnitID                         uint16 = 0x0404
+	ParamUnitIMEI                       uint16 = 0x0405
+	ParamUnitRS485BaudRate              uint16 = 0x0406
+	ParamUnitRS485StopBits              uint16 = 0x0407
+	ParamUnitRS485Parity                uint16 = 0x0408
+	ParamUnitHomeDispatcherID           uint16 = 0x0411
+	ParamServiceAuthMethod              uint16 = 0x0412
+	ParamServerCheckInPeriod            uint16 = 0x0413
+	ParamServerCheckInAttempts          uint16 = 0x0414
+	ParamServerPacketTimeout            uint16 = 0x0415
+	ParamServerPacketRetransmitAttempts uint16 = 0x0416
+	ParamUnitMicLevel                   uint16 = 0x0417
+	ParamUnitSpkLevel                   uint16 = 0x0418
+)
+
+var (
+	// ErrUnknownParameter is returned when the parameter code is not in the catalog.
+	ErrUnknownParameter = errors.New("unknown terminal parameter")
+	// ErrParamValue is returned when the parameter value does not match its type.
+	ErrParamValue = errors.New("invalid terminal parameter value")
+	// ErrCommandNotConfirmed is returned when the command confirmation reports a failure.
+	ErrCommandNotConfirmed = errors.New("command is not confirmed")
+)
+
+// Parameter describes the terminal parameter of the catalog.
+type Parameter struct {
+	Code uint16
+	Name string
+	Type ParamType
+}
+
+// parameters is the catalog of the terminal parameters of GOST 33472 and GOST 54619.
+var parameters = map[uint16]Parameter{}
+
+func init() {
+	for _, p := range []Parameter{
+		{ParamRadioMuteDelay, "EGTS_RADIO_MUTE_DELAY", ParamUint},
+		{ParamRadioUnmuteDelay, "EGTS_RADIO_UNMUTE_DELAY", ParamUint},
+		{ParamGprsAPN, "EGTS_GPRS_APN", ParamString},
+		{ParamServerAddress, "EGTS_SERVER_ADDRESS", ParamString},
+		{ParamSimPin, "EGTS_SIM_PIN", ParamUint},
+		{ParamIntMemTransmitInterval, "EGTS_INT_MEM_TRANSMIT_INTERVAL", ParamUint},
+		{ParamIntMemTransmitAttempts, "EGTS_INT_MEM_TRANSMIT_ATTEMPTS", ParamUint},
+		{ParamTestModeEndDistance, "EGTS_TEST_MODE_END_DISTANCE", ParamUint},
+		{ParamGarageModeEndDistance, "EGTS_GARAGE_MODE_END_DISTANCE", ParamUint},
+		{ParamGarageModePin, "EGTS_GARAGE_MODE_PIN", ParamUint},
+		{ParamECallTestNumber, "EGTS_ECALL_TEST_NUMBER", ParamString},
+		{ParamECallOn, "EGTS_ECALL_ON", ParamBool},
+		{ParamECallCrashSignalInternal, "EGTS_ECALL_CRASH_SIGNAL_INTERNAL", ParamBool},
+		{ParamECallCrashSignalExternal, "EGTS_ECALL_CRASH_SIGNAL_EXTERNAL", ParamBool},
+		{ParamECallSosButtonTime, "EGTS_ECALL_SOS_BUTTON_TIME", ParamUint},
+		{ParamECallNoAutomaticTriggering, "EGTS_ECALL_NO_AUTOMATIC_TRIGGERING", ParamBool},
+		{ParamASI15Threshold, "EGTS_ASI15_TRESHOLD", ParamUint},
+		{ParamECallModePin, "EGTS_ECALL_MODE_PIN", ParamUint},
+		{ParamECallCCFT, "EGTS_ECALL_CCFT", ParamUint},
+		{ParamECallInvitationSignalDuration, "EGTS_ECALL_INVITATION_SIGNAL_DURATION", ParamUint},
+		{ParamECallSendMsgPeriod, "EGTS_ECALL_SEND_MSG_PERIOD", ParamUint},
+		{ParamECallAlAckPeriod, "EGTS_ECALL_AL_ACK_PERIOD", ParamUint},
+		{ParamECallMsdMaxTransmissionTime, "EGTS_ECALL_MSD_MAX_TRANSMISSION_TIME", ParamUint},
+		{ParamECallNadDeregistrationTimer, "EGTS_ECALL_NAD_DEREGISTRATION_TIMER", ParamUint},
+		{ParamECallDialDuration, "EGTS_ECALL_DIAL_DURATION", ParamUint},
+		{ParamECallAutoDialAttempts, "EGTS_ECALL_AUTO_DIAL_ATTEMPTS", ParamUint},
+		{ParamECallManualDialAttempts, "EGTS_ECALL_MANUAL_DIAL_ATTEMPTS", ParamUint},
+		{ParamECallManualCanCancel, "EGTS_ECALL_MANUAL_CAN_CANCEL", ParamBool},
+		{ParamECallSmsFallbackNumber, "EGTS_ECALL_SMS_FALLBACK_NUMBER", ParamString},
+		{ParamIgnitionOffFollowUpTime1, "EGTS_IGNITION_OFF_FOLLOW_UP_TIME1", ParamUint},
+		{ParamIgnitionOffFollowUpTime2, "EGTS_IGNITION_OFF_FOLLOW_UP_TIME2", ParamUint},
+		{ParamTestRegistrationPeriod, "EGTS_TEST_REGISTRATION_PERIOD", ParamUint},
+		{ParamCrashRecordTime, "EGTS_CRASH_RECORD_TIME", ParamUint},
+		{ParamCrashRecordResolution, "EGTS_CRASH_RECORD_RESOLUTION", ParamUint},
+		{ParamCrashPreRecordTime, "EGTS_CRASH_PRE_RECORD_TIME", ParamUint},
+		{ParamCrashPreRecordResolution, "EGTS_CRASH_PRE_RECORD_RESOLUTION", ParamUint},
+		{ParamGnssPowerOffTime, "EGTS_GNSS_POWER_OFF_TIME", ParamUint},
+		{ParamGnssDataRate, "EGTS_GNSS_DATA_RATE", ParamUint},
+		{ParamGnssMinElevation, "EGTS_GNSS_MIN_ELEVATION", ParamUint},
+		{ParamVehicleVIN, "EGTS_VEHICLE_VIN", ParamString},
+		{ParamVehicleType, "EGTS_VEHICLE_TYPE", ParamUint},
+		{ParamVehiclePropulsionStorageType, "EGTS_VEHICLE_PROPULSION_STORAGE_TYPE", ParamUint},
+		{ParamUnitID, "EGTS_UNIT_ID", ParamUint},
+		{ParamUnitIMEI, "EGTS_UNIT_IMEI", ParamString},
+		{ParamUnitRS485BaudRate, "EGTS_UNIT_RS485_BAUD_RATE", ParamByte},
+		{ParamUnitRS485StopBits, "EGTS_UNIT_RS485_STOP_BITS", ParamByte},
+		{ParamUnitRS485Parity, "EGTS_UNIT_RS485_PARITY", ParamByte},
+		{ParamUnitHomeDispatcherID, "EGTS_UNIT_HOME_DISPATCHER_ID", ParamUint},
+		{ParamServiceAuthMethod, "EGTS_SERVICE_AUTH_METHOD", ParamByte},
+		{ParamServerCheckInPeriod, "EGTS_SERVER_CHECK_IN_PERIOD", ParamUint},
+		{ParamServerCheckInAttempts, "EGTS_SERVER_CHECK_IN_ATTEMPTS", ParamUint},
+		{ParamServerPacketTimeout, "EGTS_SERVER_PACKET_TOUT", ParamUint},
+		{ParamServerPacketRetransmitAttempts, "EGTS_SERVER_PACKET_RETRANSMIT_ATTEMPTS", ParamUint},
+		{ParamUnitMicLevel, "EGTS_UNIT_MIC_LEVEL", ParamUint},
+		{ParamUnitSpkLevel, "EGTS_UNIT_SPK_LEVEL", ParamUint},
+	} {
+		parameters[p.Code] = p
+	}
+}
+
+// LookupParameter returns the parameter of the catalog by its code.
+func LookupParameter(code uint16) (Parameter, bool) {
+	p, ok := parameters[code]
+	return p, ok
+}
+
+// ParameterByName returns the parameter of the catalog by its name, e.g. EGTS_GPRS_APN.
+func ParameterByName(name string) (Parameter, bool) {
+	for _, p := range parameters {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Parameter{}, false
+}
+
+// EncodeValue encodes the value according to the parameter type. The value must be bool for ParamBool,
+// string for ParamString or an integer for the numeric types.
+func (p Parameter) EncodeValue(v interface{}) ([]byte, error) {
+	if p.Type == ParamBool {
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s expects bool, got %T: %w", p.Name, v, ErrParamValue)
+		}
+		if b {
+			return []byte{1}, nil
+		}
+		return []byte{0}, nil
+	}
+	if p.Type == ParamString {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s expects string, got %T: %w", p.Name, v, ErrParamValue)
+		}
+		return []byte(s), nil
+	}
+
+	n, ok := toUint64(v)
+	if !ok {
+		return nil, fmt.Errorf("%s expects integer, got %T: %w", p.Name, v, ErrParamValue)
+	}
+	switch p.Type {
+	case ParamByte:
+		if n > 0xFF {
+			return nil, fmt.Errorf("%s value %d overflows %s: %w", p.Name, n, p.Type, ErrParamValue)
+		}
+		return []byte{uint8(n)}, nil
+	case ParamUshort:
+		if n > 0xFFFF {
+			return nil, fmt.Errorf("%s value %d overflows %s: %w", p.Name, n, p.Type, ErrParamValue)
+		}
+		return binary.LittleEndian.AppendUint16(nil, uint16(n)), nil
+	case ParamUint:
+		if n > 0xFFFFFFFF {
+			return nil, fmt.Errorf("%s value %d overflows %s: %w", p.Name, n, p.Type, ErrParamValue)
+		}
+		return binary.LittleEndian.AppendUint32(nil, uint32(n)), nil
+	default:
+		return nil, fmt.Errorf("%s has %s: %w", p.Name, p.Type, ErrParamValue)
+	}
+}
+
+// DecodeValue decodes the value according to the parameter type into bool, uint8, uint16, uint32 or string.
+func (p Parameter) DecodeValue(b []byte) (interface{}, error) {
+	size := map[ParamType]int{ParamBool: 1, ParamByte: 1, ParamUshort: 2, ParamUint: 4}[p.Type]
+	if p.Type != ParamString && len(b) != size {
+		return nil, fmt.Errorf("%s of %s has %d bytes: %w", p.Name, p.Type, len(b), ErrParamValue)
+	}
+
+	switch p.Type {
+	case ParamBool:
+		return b[0] != 0, nil
+	case ParamByte:
+		return b[0], nil
+	case ParamUshort:
+		return binary.LittleEndian.Uint16(b), nil
+	case ParamUint:
+		return binary.LittleEndian.Uint32(b), nil
+	case ParamString:
+		return string(b), nil
+	default:
+		return nil, fmt.Errorf("%s has %s: %w", p.Name, p.Type, ErrParamValue)
+	}
+}
+
+// NewParamQuery returns CT_COM command requesting the value of the terminal parameter.
+func NewParamQuery(cid uint32, code uint16) (*SrCommandData, error) {
+	if _, ok := LookupParameter(code); !ok {
+		return nil, fmt.Errorf("code 0x%04X: %w", code, ErrUnknownParameter)
+	}
+	return newCommand(cid, &CommandDetails{Action: ActGet, Code: code}), nil
+}
+
+// NewParamSet returns CT_COM command setting the value of the terminal parameter.
+func NewParamSet(cid uint32, code uint16, value interface{}) (*SrCommandData, error) {
+	p, ok := LookupParameter(code)
+	if !ok {
+		return nil, fmt.Errorf("code 0x%04X: %w", code, ErrUnknownParameter)
+	}
+	data, err := p.EncodeValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return newCommand(cid, &CommandDetails{Action: ActSet, Code: code, Data: data}), nil
+}
+
+// NewCommandRecord wraps the commands into COMMANDS_SERVICE record addressed to the object.
+// Records are numbered when packed by BatchEncoder.
+func NewCommandRecord(oid uint32, commands ...*SrCommandData) ServiceDataRecord {
+	rds := make(RecordDataSet, 0, len(commands))
+	for _, c := range commands {
+		rds = append(rds, RecordData{SubrecordType: SrCommandDataType, SubrecordData: c})
+	}
+	return ServiceDataRecord{
+		SourceServiceOnDevice:    "0",
+		RecipientServiceOnDevice: "1",
+		Group:                    "0",
+		RecordProcessingPriority: "10",
+		TimeFieldExists:          "0",
+		EventIDFieldExists:       "0",
+		ObjectIDFieldExists:      "1",
+		ObjectIdentifier:         oid,
+		SourceServiceType:        CommandsService,
+		RecipientServiceType:     CommandsService,
+		RecordDataSet:            rds,
+	}
+}
+
+// ParamValue parses the CT_COMCONF confirmation of the parameter query or setting into the typed value.
+// The value is nil if the confirmation carries no data.
+func (e *SrCommandData) ParamValue() (Parameter, interface{}, error) {
+	if e.CommandType != CtComConf || e.Command == nil {
+		return Parameter{}, nil, fmt.Errorf("command type %d: %w", e.CommandType, ErrParamValue)
+	}
+	p, ok := LookupParameter(e.Command.Code)
+	if !ok {
+		return Parameter{}, nil, fmt.Errorf("code 0x%04X: %w", e.Command.Code, ErrUnknownParameter)
+	}
+	if e.CommandConfirmationType != CcOk {
+		return p, nil, fmt.Errorf("%s confirmation type %d: %w", p.Name, e.CommandConfirmationType,
+			ErrCommandNotConfirmed)
+	}
+	if len(e.Command.Data) == 0 {
+		return p, nil, nil
+	}
+	v, err := p.DecodeValue(e.Command.Data)
+	if err != nil {
+		return p, nil, err
+	}
+	return p, v, nil
+}
+
+func newCommand(cid uint32, cd *CommandDetails) *SrCommandData {
+	return &SrCommandData{
+		CommandType:             CtCom,
+		CommandConfirmationType: CcOk,
+		CommandIdentifier:       cid,
+		ACFE:                    "0",
+		CHSFE:                   "0",
+		Command:                 cd,
+	}
+}
+
+// toUint64 converts non-negative integer of any type.
+func toUint64(v interface{}) (uint64, bool) {
+	var i int64
+	switch n := v.(type) {
+	case uint8:
+		return uint64(n), true
+	case uint16:
+		return uint64(n), true
+	case uint32:
+		return uint64(n), true
+	case uint64:
+		return n, true
+	case uint:
+		return uint64(n), true
+	case int8:
+		i = int64(n)
+	case int16:
+		i = int64(n)
+	case int32:
+		i = int64(n)
+	case int64:
+		i = n
+	case int:
+		i = int64(n)
+	default:
+		return 0, false
+	}
+	return uint64(i), i >= 0
+}
@@ -0,0 +1,109 @@
+package egts
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParameter_Value(t *testing.T) {
+	tests := []struct {
+		code  uint16
+		value interface{}
+		bytes []byte
+		want  interface{}
+	}{
+		{ParamGprsAPN, "internet", []byte("internet"), "internet"},
+		{ParamECallOn, true, []byte{0x01}, true},
+		{ParamUnitRS485Parity, 2, []byte{0x02}, uint8(2)},
+		{ParamServerCheckInPeriod, uint32(600), []byte{0x58, 0x02, 0x00, 0x00}, uint32(600)},
+	}
+	for _, tt := range tests {
+		p, ok := LookupParameter(tt.code)
+		if !assert.True(t, ok) {
+			continue
+		}
+		b, err := p.EncodeValue(tt.value)
+		if assert.NoError(t, err) {
+			assert.Equal(t, tt.bytes, b)
+		}
+		v, err := p.DecodeValue(tt.bytes)
+		if assert.NoError(t, err) {
+			assert.Equal(t, tt.want, v)
+		}
+	}
+}
+
+func TestParameter_InvalidValue(t *testing.T) {
+	p, _ := LookupParameter(ParamUnitRS485Parity)
+	_, err := p.EncodeValue(256)
+	assert.True(t, errors.Is(err, ErrParamValue))
+	_, err = p.EncodeValue(-1)
+	assert.True(t, errors.Is(err, ErrParamValue))
+	_, err = p.EncodeValue("1")
+	assert.True(t, errors.Is(err, ErrParamValue))
+	_, err = p.DecodeValue([]byte{1, 2})
+	assert.True(t, errors.Is(err, ErrParamValue))
+}
+
+func TestParameterByName(t *testing.T) {
+	p, ok := ParameterByName("EGTS_SERVER_ADDRESS")
+	if assert.True(t, ok) {
+		assert.Equal(t, ParamServerAddress, p.Code)
+		assert.Equal(t, ParamString, p.Type)
+	}
+	_, ok = ParameterByName("EGTS_UNKNOWN")
+	assert.False(t, ok)
+}
+
+func TestNewParamSet(t *testing.T) {
+	cmd, err := NewParamSet(7, ParamServerAddress, "10.0.0.1:8002")
+	if assert.NoError(t, err) {
+		assert.Equal(t, CtCom, cmd.CommandType)
+		assert.Equal(t, uint32(7), cmd.CommandIdentifier)
+		assert.Equal(t, ActSet, cmd.Command.Action)
+		assert.Equal(t, []byte("10.0.0.1:8002"), cmd.Command.Data)
+	}
+
+	_, err = NewParamSet(7, 0xFFFF, 1)
+	assert.True(t, errors.Is(err, ErrUnknownParameter))
+
+	query, err := NewParamQuery(8, ParamGprsAPN)
+	if assert.NoError(t, err) {
+		assert.Equal(t, ActGet, query.Command.Action)
+	}
+}
+
+func TestCommandRecord_Packet(t *testing.T) {
+	query, err := NewParamQuery(8, ParamGprsAPN)
+	if !assert.NoError(t, err) {
+		return
+	}
+	records := []ServiceDataRecord{NewCommandRecord(133552, query)}
+	batches, err := NewBatchEncoder(0).Encode(records)
+	if !assert.NoError(t, err) || !assert.Len(t, batches, 1) {
+		return
+	}
+
+	p := Packet{}
+	if assert.NoError(t, p.Decode(batches[0].Data)) {
+		rec := (*p.ServicesFrameData.(*ServiceDataSet))[0]
+		assert.Equal(t, CommandsService, rec.RecipientServiceType)
+		query.Command.Data = []byte{}
+		assert.Equal(t, query, rec.RecordDataSet[0].SubrecordData)
+	}
+}
+
+func TestSrCommandData_ParamValue(t *testing.T) {
+	p, v, err := testEgtsSrCommandConf.ParamValue()
+	if assert.NoError(t, err) {
+		assert.Equal(t, ParamGprsAPN, p.Code)
+		assert.Equal(t, "internet", v)
+	}
+
+	failed := testEgtsSrCommandConf
+	failed.CommandConfirmationType = CcError
+	_, _, err = failed.ParamValue()
+	assert.True(t, errors.Is(err, ErrCommandNotConfirmed))
+}
@@ -23,6 +23,7 @@ const (
 	SrAbsLoopinDataType      byte = 26 // SrAbsLoopinDataType is subrecord code of SR_ABS_LOOPIN_DATA.
 	SrLiquidLevelSensorType  byte = 27 // SrLiquidLevelSensorType код is subrecord code of SR_LIQUID_LEVEL_SENSOR.
 	SrPassengersCountersType byte = 28 // SrPassengersCountersType is subrecord code of SR_PASSENGERS_COUNTERS.
+	SrCommandDataType        byte = 51 // SrCommandDataType is subrecord code of SR_COMMAND_DATA.
 )
 
 // Packet types.
@@ -42,4 +43,7 @@ const (
 	AuthService
 	// TeledataService is service type of TELEDATA_SERVICE.
 	TeledataService
+	_
+	// CommandsService is service type of COMMANDS_SERVICE.
+	CommandsService
 )
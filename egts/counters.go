@@ -27,3 +27,38 @@ func nextRecordNumber() uint16 {
 	}
 	return uint16(atomic.LoadUint32(&cntRecordNumber))
 }
+
+// sequence issues packet identifiers and record numbers for the outgoing packets.
+type sequence interface {
+	nextPacketIdentifier() uint16
+	nextRecordNumber() uint16
+}
+
+// globalSequence is the sequence shared by all the packets of the process.
+type globalSequence struct{}
+
+func (globalSequence) nextPacketIdentifier() uint16 {
+	return nextPacketIdentifier()
+}
+
+func (globalSequence) nextRecordNumber() uint16 {
+	return nextRecordNumber()
+}
+
+// streamSequence is the sequence of a single stream, it starts from zero.
+type streamSequence struct {
+	packetIdentifier uint16
+	recordNumber     uint16
+}
+
+func (s *streamSequence) nextPacketIdentifier() uint16 {
+	pid := s.packetIdentifier
+	s.packetIdentifier++
+	return pid
+}
+
+func (s *streamSequence) nextRecordNumber() uint16 {
+	rn := s.recordNumber
+	s.recordNumber++
+	return rn
+}
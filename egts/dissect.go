@@ -0,0 +1,886 @@
+package egts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dissectRawLimit is the number of raw bytes shown in the line of the dissection tree.
+const dissectRawLimit = 16
+
+// dissectTimeLayout is the layout of the times in the dissection.
+const dissectTimeLayout = "2006-01-02 15:04:05 UTC"
+
+// Field is a node of the packet dissection: the field of the transport, service or subrecord layer
+// with its position in the packet and interpreted value.
+type Field struct {
+	// Name is the field name from the specification, e.g. PRV, RL, SRT.
+	Name string `json:"name"`
+	// Offset is the position of the field from the beginning of the packet, -1 if it is unknown.
+	// Offsets inside the encrypted SFRD are counted on the decrypted data.
+	Offset int `json:"offset"`
+	// Raw is the bytes of the field.
+	Raw []byte `json:"raw,omitempty"`
+	// Value is the interpreted value of the field.
+	Value string `json:"value"`
+	// Fields are the nested fields.
+	Fields []Field `json:"fields,omitempty"`
+}
+
+// String renders the dissection as an indented tree, one field per line.
+func (f *Field) String() string {
+	var b strings.Builder
+	f.write(&b, 0)
+	return b.String()
+}
+
+func (f *Field) write(b *strings.Builder, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(f.Name)
+	if f.Value != "" {
+		b.WriteString(": ")
+		b.WriteString(f.Value)
+	}
+	if f.Offset >= 0 {
+		fmt.Fprintf(b, " [@%d", f.Offset)
+		if len(f.Raw) > 0 {
+			if len(f.Raw) > dissectRawLimit {
+				fmt.Fprintf(b, ": % X ... (%d bytes)", f.Raw[:dissectRawLimit], len(f.Raw))
+			} else {
+				fmt.Fprintf(b, ": % X", f.Raw)
+			}
+		}
+		b.WriteString("]")
+	}
+	b.WriteString("\n")
+	for i := range f.Fields {
+		f.Fields[i].write(b, depth+1)
+	}
+}
+
+// add appends the nested field.
+func (f *Field) add(child Field) *Field {
+	f.Fields = append(f.Fields, child)
+	return &f.Fields[len(f.Fields)-1]
+}
+
+// Dissect decodes the packet bytes into the dissection tree. The packet is decoded in lenient mode,
+// the problems found are added as the "problem" fields of the tree.
+func Dissect(content []byte, opt ...func(*Options)) (*Field, error) {
+	options := &Options{}
+	for _, o := range opt {
+		o(options)
+	}
+
+	p := Packet{}
+	if err := p.Decode(content, append(opt, func(o *Options) { o.Lenient = true })...); err != nil {
+		return nil, fmt.Errorf("failed to decode packet: %w", err)
+	}
+
+	hl := int(p.HeaderLength)
+	root := &Field{
+		Name:   "EGTS",
+		Offset: 0,
+		Raw:    content,
+		Value:  fmt.Sprintf("%s, PID %d", packetTypeName(p.PacketType), p.PacketIdentifier),
+	}
+
+	header := root.add(Field{Name: "Header", Offset: 0, Raw: content[:hl], Value: fmt.Sprintf("%d bytes", hl)})
+	header.add(byteField("PRV", content, 0, fmt.Sprint(p.ProtocolVersion)))
+	header.add(byteField("SKID", content, 1, fmt.Sprint(p.SecurityKeyID)))
+	flg := header.add(byteField("FLG", content, 2, bitString(content[2])))
+	flg.add(bitField("PRF", content, 2, p.Prefix, "prefix"))
+	flg.add(bitField("RTE", content, 2, p.Route, yesNo(p.Route, "routed", "not routed")))
+	ena := "not encrypted"
+	if p.EncryptionAlg != "00" {
+		ena = "encrypted"
+	}
+	flg.add(bitField("ENA", content, 2, p.EncryptionAlg, ena))
+	flg.add(bitField("CMP", content, 2, p.Compression, yesNo(p.Compression, "compressed", "not compressed")))
+	flg.add(bitField("PR", content, 2, p.Priority, priorityName(p.Priority)))
+	header.add(byteField("HL", content, 3, fmt.Sprint(p.HeaderLength)))
+	header.add(byteField("HE", content, 4, fmt.Sprint(p.HeaderEncoding)))
+	header.add(sliceField("FDL", content, 5, 2, fmt.Sprint(p.FrameDataLength)))
+	header.add(sliceField("PID", content, 7, 2, fmt.Sprint(p.PacketIdentifier)))
+	header.add(byteField("PT", content, 9, packetTypeName(p.PacketType)))
+	if p.Route == "1" {
+		header.add(sliceField("PRA", content, 10, 2, fmt.Sprint(p.PeerAddress)))
+		header.add(sliceField("RCA", content, 12, 2, fmt.Sprint(p.RecipientAddress)))
+		header.add(byteField("TTL", content, 14, fmt.Sprint(p.TimeToLive)))
+	}
+	header.add(byteField("HCS", content, hl-1, checksumValue(uint32(p.HeaderCheckSum),
+		uint32(CRC8(content[:hl-1])))))
+
+	end := hl + int(p.FrameDataLength)
+	if end > len(content) {
+		end = len(content)
+	}
+	sfrd := content[hl:end]
+	if p.FrameDataLength > 0 {
+		node := root.add(Field{Name: "SFRD", Offset: hl, Raw: sfrd, Value: fmt.Sprintf("%d bytes", len(sfrd))})
+		dissectFrameData(node, &p, sfrd, options.Secret)
+	}
+
+	if end+2 <= len(content) {
+		root.add(sliceField("SFRCS", content, end, 2, checksumValue(uint32(p.ServicesFrameDataCheckSum),
+			uint32(CRC16(sfrd)))))
+	}
+
+	for _, problem := range p.Problems {
+		root.add(Field{Name: "problem", Offset: problem.Offset, Value: fmt.Sprintf("%s: %v", problem.Field,
+			problem.Err)})
+	}
+	return root, nil
+}
+
+// dissectFrameData adds the fields of the services frame data.
+func dissectFrameData(node *Field, p *Packet, sfrd []byte, secret SecretKey) {
+	if p.EncryptionAlg != "00" {
+		if secret == nil {
+			node.Value += ", encrypted"
+			return
+		}
+		decrypted, err := secret.Decode(sfrd)
+		if err != nil {
+			node.Value += ", failed to decrypt"
+			return
+		}
+		node.Value += ", decrypted"
+		sfrd = decrypted
+	}
+
+	base := node.Offset
+	switch p.PacketType {
+	case PtAppdataPacket:
+		node.Fields = append(node.Fields, dissectRecords(sfrd, base)...)
+	case PtResponsePacket:
+		if len(sfrd) < 3 {
+			return
+		}
+		node.add(sliceField("RPID", sfrd, 0, 2, fmt.Sprint(binary.LittleEndian.Uint16(sfrd))).shift(base))
+		node.add(byteField("PR", sfrd, 2, resultCodeName(sfrd[2])).shift(base))
+		node.Fields = append(node.Fields, dissectRecords(sfrd[3:], base+3)...)
+	}
+}
+
+// dissectRecords returns the fields of the service data records.
+func dissectRecords(data []byte, base int) []Field {
+	var result []Field
+	pos := 0
+	for pos < len(data) {
+		rec := data[pos:]
+		if len(rec) < 7 {
+			return append(result, Field{Name: "malformed", Offset: base + pos, Raw: rec, Value: "truncated record"})
+		}
+		rl := int(binary.LittleEndian.Uint16(rec))
+		flags := bitString(rec[4])
+		hdrLen := 7
+		for _, f := range []byte{flags[7], flags[6], flags[5]} {
+			if f == '1' {
+				hdrLen += 4
+			}
+		}
+		if len(rec) < hdrLen {
+			return append(result, Field{Name: "malformed", Offset: base + pos, Raw: rec, Value: "truncated record"})
+		}
+		recLen := hdrLen + rl
+		if recLen > len(rec) {
+			recLen = len(rec)
+		}
+
+		sdr := Field{
+			Name:   "SDR",
+			Offset: base + pos,
+			Raw:    rec[:recLen],
+			Value:  fmt.Sprintf("RN %d", binary.LittleEndian.Uint16(rec[2:])),
+		}
+		sdr.add(sliceField("RL", rec, 0, 2, fmt.Sprint(rl)).shift(base + pos))
+		sdr.add(sliceField("RN", rec, 2, 2, fmt.Sprint(binary.LittleEndian.Uint16(rec[2:]))).shift(base + pos))
+		sdr.add(flagsField("RFL", rec, 4, []flagBit{
+			{"SSOD", flags[:1], yesNo(flags[:1], "source service on device", "source service on platform")},
+			{"RSOD", flags[1:2], yesNo(flags[1:2], "recipient service on device", "recipient service on platform")},
+			{"GRP", flags[2:3], yesNo(flags[2:3], "group record", "single record")},
+			{"RPP", flags[3:5], priorityName(flags[3:5])},
+			{"TMFE", flags[5:6], yesNo(flags[5:6], "TM present", "TM absent")},
+			{"EVFE", flags[6:7], yesNo(flags[6:7], "EVID present", "EVID absent")},
+			{"OBFE", flags[7:], yesNo(flags[7:], "OID present", "OID absent")},
+		}).shift(base + pos))
+
+		off := 5
+		if flags[7] == '1' {
+			sdr.add(sliceField("OID", rec, off, 4, fmt.Sprint(binary.LittleEndian.Uint32(rec[off:]))).shift(base + pos))
+			off += 4
+		}
+		if flags[6] == '1' {
+			sdr.add(sliceField("EVID", rec, off, 4, fmt.Sprint(binary.LittleEndian.Uint32(rec[off:]))).shift(base + pos))
+			off += 4
+		}
+		if flags[5] == '1' {
+			tm := timeOffset.Add(time.Duration(binary.LittleEndian.Uint32(rec[off:])) * time.Second)
+			sdr.add(sliceField("TM", rec, off, 4, tm.Format(dissectTimeLayout)).shift(base + pos))
+			off += 4
+		}
+		sdr.add(byteField("SST", rec, off, serviceName(rec[off])).shift(base + pos))
+		sdr.add(byteField("RST", rec, off+1, serviceName(rec[off+1])).shift(base + pos))
+		off += 2
+
+		rd := sdr.add(Field{Name: "RD", Offset: base + pos + off, Raw: rec[off:recLen],
+			Value: fmt.Sprintf("%d bytes", recLen-off)})
+		rd.Fields = dissectSubrecords(rec[off:recLen], base+pos+off)
+
+		result = append(result, sdr)
+		pos += recLen
+	}
+	return result
+}
+
+// dissectSubrecords returns the fields of the subrecords.
+func dissectSubrecords(data []byte, base int) []Field {
+	var result []Field
+	pos := 0
+	for pos < len(data) {
+		sr := data[pos:]
+		if len(sr) < 3 {
+			return append(result, Field{Name: "malformed", Offset: base + pos, Raw: sr, Value: "truncated subrecord"})
+		}
+		srt := sr[0]
+		srl := int(binary.LittleEndian.Uint16(sr[1:]))
+		srLen := 3 + srl
+		if srLen > len(sr) {
+			srLen = len(sr)
+		}
+
+		rds := RecordDataSet{}
+		_ = rds.decode(sr[:srLen], &decodeState{}, 0)
+		var data BinaryData
+		if len(rds) > 0 {
+			data = rds[0].SubrecordData
+		}
+
+		node := Field{Name: "SR", Offset: base + pos, Raw: sr[:srLen], Value: subrecordName(srt, srl)}
+		node.add(byteField("SRT", sr, 0, fmt.Sprintf("%d (%s)", srt, subrecordName(srt, srl))).shift(base + pos))
+		node.add(sliceField("SRL", sr, 1, 2, fmt.Sprint(srl)).shift(base + pos))
+		srd := node.add(Field{Name: "SRD", Offset: base + pos + 3, Raw: sr[3:srLen],
+			Value: fmt.Sprintf("%d bytes", srLen-3)})
+		srd.Fields = dissectSubrecordData(data, sr[3:srLen], base+pos+3)
+
+		result = append(result, node)
+		pos += srLen
+	}
+	return result
+}
+
+// dissectSubrecordData returns the fields of the subrecord data. The known subrecords are dissected
+// field by field, the fields of the others are listed without offsets.
+func dissectSubrecordData(data BinaryData, raw []byte, base int) []Field {
+	var fields []Field
+	switch sr := data.(type) {
+	case *SrPosData:
+		fields = dissectPosData(sr, raw)
+	case *SrExtPosData:
+		fields = dissectExtPosData(sr, raw)
+	case *SrAdSensorsData:
+		fields = dissectAdSensorsData(raw)
+	case *SrLiquidLevelSensor:
+		fields = dissectLiquidLevelSensor(sr, raw)
+	case *SrCountersData:
+		fields = dissectCountersData(raw)
+	case *SrTermIdentity:
+		fields = dissectTermIdentity(sr, raw)
+	case *SrAuthInfo:
+		fields = dissectAuthInfo(raw)
+	case *SrCommandData:
+		fields = dissectCommandData(sr, raw)
+	case *SrAccelData:
+		fields = dissectAccelData(sr, raw)
+	case *SrResponse:
+		fields = []Field{
+			sliceField("CRN", raw, 0, 2, fmt.Sprint(sr.ConfirmedRecordNumber)),
+			byteField("RST", raw, 2, resultCodeName(sr.RecordStatus)),
+		}
+	case *SrResultCode:
+		fields = []Field{byteField("RCD", raw, 0, resultCodeName(sr.ResultCode))}
+	case *SrStateData:
+		fields = []Field{
+			byteField("ST", raw, 0, fmt.Sprintf("%d (%s)", sr.State, sr.State)),
+			byteField("MPSV", raw, 1, fmt.Sprintf("%.1f V", sr.MainPowerSourceVolts())),
+			byteField("BBV", raw, 2, fmt.Sprintf("%.1f V", sr.BackUpBatteryVolts())),
+			byteField("IBV", raw, 3, fmt.Sprintf("%.1f V", sr.InternalBatteryVolts())),
+			byteField("FLG", raw, 4, fmt.Sprintf("NMS=%s IBU=%s BBU=%s", sr.NMS, sr.IBU, sr.BBU)),
+		}
+	case *SrRaw, nil:
+		return nil
+	default:
+		return structFields(data)
+	}
+
+	for i := range fields {
+		fields[i] = fields[i].shift(base)
+	}
+	return fields
+}
+
+// dissectPosData returns the fields of EGTS_SR_POS_DATA.
+func dissectPosData(sr *SrPosData, raw []byte) []Field {
+	lat, long := sr.Latitude, sr.Longitude
+	if sr.LAHS == "1" {
+		lat = -lat
+	}
+	if sr.LOHS == "1" {
+		long = -long
+	}
+
+	fields := []Field{
+		sliceField("NTM", raw, 0, 4, sr.NavigationTime.UTC().Format(dissectTimeLayout)),
+		sliceField("LAT", raw, 4, 4, fmt.Sprintf("%.6f°", lat)),
+		sliceField("LONG", raw, 8, 4, fmt.Sprintf("%.6f°", long)),
+	}
+	fields = append(fields,
+		flagsField("FLG", raw, 12, []flagBit{
+			{"ALTE", sr.ALTE, yesNo(sr.ALTE, "ALT present", "ALT absent")},
+			{"LOHS", sr.LOHS, yesNo(sr.LOHS, "west", "east")},
+			{"LAHS", sr.LAHS, yesNo(sr.LAHS, "south", "north")},
+			{"MV", sr.MV, yesNo(sr.MV, "moving", "parking")},
+			{"BB", sr.BB, yesNo(sr.BB, "black box", "actual")},
+			{"CS", sr.CS, yesNo(sr.CS, "PZ-90.02", "WGS-84")},
+			{"FIX", sr.FIX, yesNo(sr.FIX, "3D", "2D")},
+			{"VLD", sr.VLD, yesNo(sr.VLD, "valid", "invalid")},
+		}),
+		sliceField("SPD", raw, 13, 2, fmt.Sprintf("%d km/h, DIRH=%d, ALTS=%d", sr.Speed, sr.DirectionHighestBit,
+			sr.AltitudeSign)),
+		byteField("DIR", raw, 15, fmt.Sprintf("%d°", sr.course())),
+		sliceField("ODM", raw, 16, 3, fmt.Sprintf("%.1f km", float64(sr.Odometer)/10)),
+		byteField("DIN", raw, 19, bitString(sr.DigitalInputs)),
+		byteField("SRC", raw, 20, fmt.Sprintf("%d (%s)", sr.Source, sr.Source)),
+	)
+	off := 21
+	if sr.ALTE == "1" {
+		alt := int64(sr.Altitude)
+		if sr.AltitudeSign == 1 {
+			alt = -alt
+		}
+		fields = append(fields, sliceField("ALT", raw, off, 3, fmt.Sprintf("%d m", alt)))
+		off += 3
+	}
+	if sr.HasSourceData {
+		fields = append(fields, sliceField("SRCD", raw, off, 2, fmt.Sprint(sr.SourceData)))
+	}
+	return fields
+}
+
+// dissectExtPosData returns the fields of EGTS_SR_EXT_POS_DATA.
+func dissectExtPosData(sr *SrExtPosData, raw []byte) []Field {
+	fields := []Field{flagsField("FLG", raw, 0, []flagBit{
+		{"NSFE", sr.NavigationSystemFieldExists, yesNo(sr.NavigationSystemFieldExists, "NS present", "NS absent")},
+		{"SFE", sr.SatellitesFieldExists, yesNo(sr.SatellitesFieldExists, "SAT present", "SAT absent")},
+		{"PFE", sr.PdopFieldExists, yesNo(sr.PdopFieldExists, "PDOP present", "PDOP absent")},
+		{"HFE", sr.HdopFieldExists, yesNo(sr.HdopFieldExists, "HDOP present", "HDOP absent")},
+		{"VFE", sr.VdopFieldExists, yesNo(sr.VdopFieldExists, "VDOP present", "VDOP absent")},
+	})}
+
+	off := 1
+	for _, f := range []struct {
+		name   string
+		exists string
+		value  uint16
+	}{
+		{"VDOP", sr.VdopFieldExists, sr.VerticalDilutionOfPrecision},
+		{"HDOP", sr.HdopFieldExists, sr.HorizontalDilutionOfPrecision},
+		{"PDOP", sr.PdopFieldExists, sr.PositionDilutionOfPrecision},
+	} {
+		if f.exists == "1" {
+			fields = append(fields, sliceField(f.name, raw, off, 2, fmt.Sprintf("%.1f", float64(f.value)/10)))
+			off += 2
+		}
+	}
+	if sr.SatellitesFieldExists == "1" {
+		fields = append(fields, byteField("SAT", raw, off, fmt.Sprint(sr.Satellites)))
+		off++
+	}
+	if sr.NavigationSystemFieldExists == "1" {
+		fields = append(fields, sliceField("NS", raw, off, 2, fmt.Sprintf("%016b", sr.NavigationSystem)))
+	}
+	return fields
+}
+
+// dissectAdSensorsData returns the fields of EGTS_SR_AD_SENSORS_DATA.
+func dissectAdSensorsData(raw []byte) []Field {
+	dioe, asfe := bitString(byteAt(raw, 0)), bitString(byteAt(raw, 2))
+	fields := []Field{
+		flagsField("DIOE", raw, 0, octetFlags("DIOE", dioe, "ADIO")),
+		byteField("DOUT", raw, 1, bitString(byteAt(raw, 1))),
+		flagsField("ASFE", raw, 2, octetFlags("ASFE", asfe, "ANS")),
+	}
+
+	off := 3
+	for i := 1; i <= 8; i++ {
+		if dioe[8-i] == '1' {
+			fields = append(fields, byteField(fmt.Sprintf("ADIO%d", i), raw, off, bitString(byteAt(raw, off))))
+			off++
+		}
+	}
+	for i := 1; i <= 8; i++ {
+		if asfe[8-i] == '1' {
+			fields = append(fields, sliceField(fmt.Sprintf("ANS%d", i), raw, off, 3, fmt.Sprint(uint24At(raw, off))))
+			off += 3
+		}
+	}
+	return fields
+}
+
+// dissectLiquidLevelSensor returns the fields of EGTS_SR_LIQUID_LEVEL_SENSOR.
+func dissectLiquidLevelSensor(sr *SrLiquidLevelSensor, raw []byte) []Field {
+	var units string
+	switch sr.LiquidLevelSensorValueUnit {
+	case LLSVURaw:
+		units = "uncalibrated"
+	case LLSVUPercent:
+		units = "percent"
+	case LLSVULitres:
+		units = "0.1 l"
+	default:
+		units = "unknown"
+	}
+
+	value := fmt.Sprint(sr.LiquidLevelSensorData)
+	switch {
+	case sr.RawDataFlag == "1":
+		value += " (raw port data)"
+	case sr.LiquidLevelSensorValueUnit == LLSVULitres:
+		value = fmt.Sprintf("%.1f l", float64(sr.LiquidLevelSensorData)/10)
+	case sr.LiquidLevelSensorValueUnit == LLSVUPercent:
+		value += " %"
+	}
+
+	return []Field{
+		flagsField("FLG", raw, 0, []flagBit{
+			{"LLSEF", sr.LiquidLevelSensorErrorFlag, yesNo(sr.LiquidLevelSensorErrorFlag, "error", "no error")},
+			{"LLSVU", sr.LiquidLevelSensorValueUnit, units},
+			{"RDF", sr.RawDataFlag, yesNo(sr.RawDataFlag, "raw port data", "LLSD")},
+			{"LLSN", fmt.Sprintf("%03b", sr.LiquidLevelSensorNumber), fmt.Sprintf("sensor %d",
+				sr.LiquidLevelSensorNumber)},
+		}),
+		sliceField("MADDR", raw, 1, 2, fmt.Sprint(sr.ModuleAddress)),
+		sliceField("LLSD", raw, 3, 4, value),
+	}
+}
+
+// dissectCountersData returns the fields of EGTS_SR_COUNTERS_DATA.
+func dissectCountersData(raw []byte) []Field {
+	cfe := bitString(byteAt(raw, 0))
+	fields := []Field{flagsField("FLG", raw, 0, octetFlags("CFE", cfe, "CN"))}
+
+	off := 1
+	for i := 1; i <= 8; i++ {
+		if cfe[8-i] == '1' {
+			fields = append(fields, sliceField(fmt.Sprintf("CN%d", i), raw, off, 3, fmt.Sprint(uint24At(raw, off))))
+			off += 3
+		}
+	}
+	return fields
+}
+
+// dissectTermIdentity returns the fields of EGTS_SR_TERM_IDENTITY.
+func dissectTermIdentity(sr *SrTermIdentity, raw []byte) []Field {
+	fields := []Field{
+		sliceField("TID", raw, 0, 4, fmt.Sprint(sr.TerminalIdentifier)),
+		flagsField("FLG", raw, 4, []flagBit{
+			{"MNE", sr.MNE, yesNo(sr.MNE, "MSISDN present", "MSISDN absent")},
+			{"BSE", sr.BSE, yesNo(sr.BSE, "BS present", "BS absent")},
+			{"NIDE", sr.NIDE, yesNo(sr.NIDE, "NID present", "NID absent")},
+			{"SSRA", sr.SSRA, yesNo(sr.SSRA, "simple response algorithm", "complex response algorithm")},
+			{"LNGCE", sr.LNGCE, yesNo(sr.LNGCE, "LNGC present", "LNGC absent")},
+			{"IMSIE", sr.IMSIE, yesNo(sr.IMSIE, "IMSI present", "IMSI absent")},
+			{"IMEIE", sr.IMEIE, yesNo(sr.IMEIE, "IMEI present", "IMEI absent")},
+			{"HDIDE", sr.HDIDE, yesNo(sr.HDIDE, "HDID present", "HDID absent")},
+		}),
+	}
+
+	off := 5
+	for _, f := range []struct {
+		name, exists string
+		n            int
+		value        string
+	}{
+		{"HDID", sr.HDIDE, 2, fmt.Sprint(sr.HomeDispatcherIdentifier)},
+		{"IMEI", sr.IMEIE, 15, sr.IMEI},
+		{"IMSI", sr.IMSIE, 16, sr.IMSI},
+		{"LNGC", sr.LNGCE, 3, sr.LanguageCode},
+		{"NID", sr.NIDE, 3, fmt.Sprintf("% X", sr.NetworkIdentifier)},
+		{"BS", sr.BSE, 2, fmt.Sprint(sr.BufferSize)},
+		{"MSISDN", sr.MNE, 15, sr.MobileNumber},
+	} {
+		if f.exists == "1" {
+			fields = append(fields, sliceField(f.name, raw, off, f.n, f.value))
+			off += f.n
+		}
+	}
+	return fields
+}
+
+// dissectAuthInfo returns the fields of EGTS_SR_AUTH_INFO, the strings are shown with their terminating zeros.
+func dissectAuthInfo(raw []byte) []Field {
+	var fields []Field
+	off := 0
+	for _, name := range []string{"UNM", "UPSW", "SS"} {
+		if off >= len(raw) {
+			break
+		}
+		n := bytes.IndexByte(raw[off:], 0) + 1
+		if n == 0 {
+			n = len(raw) - off
+		}
+		fields = append(fields, sliceField(name, raw, off, n, strconv.Quote(string(bytes.TrimSuffix(raw[off:off+n],
+			[]byte{0})))))
+		off += n
+	}
+	return fields
+}
+
+// dissectCommandData returns the fields of EGTS_SR_COMMAND_DATA.
+func dissectCommandData(sr *SrCommandData, raw []byte) []Field {
+	fields := []Field{
+		flagsField("CT/CCT", raw, 0, []flagBit{
+			{"CT", fmt.Sprintf("%04b", sr.CommandType), commandTypeName(sr.CommandType)},
+			{"CCT", fmt.Sprintf("%04b", sr.CommandConfirmationType), confirmationTypeName(sr.CommandConfirmationType)},
+		}),
+		sliceField("CID", raw, 1, 4, fmt.Sprint(sr.CommandIdentifier)),
+		sliceField("SID", raw, 5, 4, fmt.Sprint(sr.SourceIdentifier)),
+		flagsField("FLG", raw, 9, []flagBit{
+			{"ACFE", sr.ACFE, yesNo(sr.ACFE, "ACL and AC present", "ACL and AC absent")},
+			{"CHSFE", sr.CHSFE, yesNo(sr.CHSFE, "CHS present", "CHS absent")},
+		}),
+	}
+
+	off := 10
+	if sr.CHSFE == "1" {
+		fields = append(fields, byteField("CHS", raw, off, fmt.Sprint(sr.Charset)))
+		off++
+	}
+	if sr.ACFE == "1" {
+		fields = append(fields, byteField("ACL", raw, off, fmt.Sprint(sr.AuthorizationCodeLength)))
+		off++
+		n := int(sr.AuthorizationCodeLength)
+		fields = append(fields, sliceField("AC", raw, off, n, fmt.Sprintf("%d bytes", n)))
+		off += n
+	}
+	if off >= len(raw) {
+		return fields
+	}
+
+	if sr.Command == nil {
+		return append(fields, sliceField("MSG", raw, off, len(raw)-off, strconv.Quote(string(sr.Message))))
+	}
+	cd := sliceField("CD", raw, off, len(raw)-off, fmt.Sprintf("%d bytes", len(raw)-off))
+	cd.add(sliceField("ADR", raw, off, 2, fmt.Sprint(sr.Command.Address)))
+	off += 2
+	if sr.CommandType == CtCom {
+		cd.add(flagsField("SZ/ACT", raw, off, []flagBit{
+			{"SZ", fmt.Sprintf("%04b", sr.Command.Size), fmt.Sprintf("%d bytes", sr.Command.Size)},
+			{"ACT", fmt.Sprintf("%04b", sr.Command.Action), fmt.Sprint(sr.Command.Action)},
+		}))
+		off++
+	}
+	cd.add(sliceField("CCD", raw, off, 2, fmt.Sprintf("0x%04X", sr.Command.Code)))
+	off += 2
+	if off < len(raw) {
+		cd.add(sliceField("DT", raw, off, len(raw)-off, fmt.Sprintf("%d bytes", len(raw)-off)))
+	}
+	return append(fields, cd)
+}
+
+// dissectAccelData returns the fields of EGTS_SR_ACCEL_DATA.
+func dissectAccelData(sr *SrAccelData, raw []byte) []Field {
+	fields := []Field{
+		byteField("SA", raw, 0, fmt.Sprint(sr.StructuresAmount)),
+		sliceField("ATM", raw, 1, 4, sr.AbsoluteTime.UTC().Format(dissectTimeLayout)),
+	}
+
+	const adsLen = 8
+	off := 5
+	for _, ads := range sr.AccelDataStructures {
+		node := sliceField("ADS", raw, off, adsLen, fmt.Sprintf("RTM %d ms", ads.RelativeTime))
+		node.add(sliceField("RTM", raw, off, 2, fmt.Sprintf("%d ms", ads.RelativeTime)))
+		node.add(sliceField("XAAV", raw, off+2, 2, fmt.Sprintf("%.1f m/s²", float64(ads.XAxisAccelerationValue)/10)))
+		node.add(sliceField("YAAV", raw, off+4, 2, fmt.Sprintf("%.1f m/s²", float64(ads.YAxisAccelerationValue)/10)))
+		node.add(sliceField("ZAAV", raw, off+6, 2, fmt.Sprintf("%.1f m/s²", float64(ads.ZAxisAccelerationValue)/10)))
+		fields = append(fields, node)
+		off += adsLen
+	}
+	return fields
+}
+
+// structFields lists the fields of the decoded structure by their JSON names.
+func structFields(data interface{}) []Field {
+	v := reflect.Indirect(reflect.ValueOf(data))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var result []Field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if !sf.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		value := v.Field(i).Interface()
+		if tm, ok := value.(time.Time); ok {
+			value = tm.UTC().Format(dissectTimeLayout)
+		}
+		result = append(result, Field{Name: name, Offset: -1, Value: fmt.Sprintf("%v", value)})
+	}
+	return result
+}
+
+// shift moves the field and its nested fields by the offset.
+func (f Field) shift(base int) Field {
+	if f.Offset >= 0 {
+		f.Offset += base
+	}
+	for i := range f.Fields {
+		f.Fields[i] = f.Fields[i].shift(base)
+	}
+	return f
+}
+
+// sliceField returns the field of n bytes at the offset, it has no raw bytes if the data is too short.
+func sliceField(name string, data []byte, offset, n int, value string) Field {
+	f := Field{Name: name, Offset: offset, Value: value}
+	if offset+n <= len(data) {
+		f.Raw = data[offset : offset+n]
+	}
+	return f
+}
+
+func byteField(name string, data []byte, offset int, value string) Field {
+	return sliceField(name, data, offset, 1, value)
+}
+
+// flagBit is the bit field of the flags byte: its name, bits and meaning.
+type flagBit struct {
+	name, value, meaning string
+}
+
+// flagsField returns the field of the flags byte at the offset with the bit fields nested.
+func flagsField(name string, data []byte, offset int, bits []flagBit) Field {
+	f := byteField(name, data, offset, bitString(byteAt(data, offset)))
+	for _, b := range bits {
+		f.add(bitField(b.name, data, offset, b.value, b.meaning))
+	}
+	return f
+}
+
+// octetFlags returns the bit fields of the flags byte enabling eight optional fields, from the highest bit.
+func octetFlags(prefix, flags, field string) []flagBit {
+	bits := make([]flagBit, 0, 8)
+	for i := 8; i >= 1; i-- {
+		value := flags[8-i : 9-i]
+		name := fmt.Sprintf("%s%d", field, i)
+		bits = append(bits, flagBit{fmt.Sprintf("%s%d", prefix, i), value,
+			yesNo(value, name+" present", name+" absent")})
+	}
+	return bits
+}
+
+// bitField returns the bit field of the flags byte at the offset.
+func bitField(name string, data []byte, offset int, bits, meaning string) Field {
+	return byteField(name, data, offset, fmt.Sprintf("%s (%s)", bits, meaning))
+}
+
+func byteAt(data []byte, offset int) byte {
+	if offset < len(data) {
+		return data[offset]
+	}
+	return 0
+}
+
+// uint24At returns the 3 bytes little-endian number at the offset, 0 if the data is too short.
+func uint24At(data []byte, offset int) uint32 {
+	if offset+3 > len(data) {
+		return 0
+	}
+	return uint32(data[offset]) | uint32(data[offset+1])<<8 | uint32(data[offset+2])<<16
+}
+
+func yesNo(flag, yes, no string) string {
+	if flag == "1" {
+		return yes
+	}
+	return no
+}
+
+func checksumValue(got, want uint32) string {
+	if got == want {
+		return fmt.Sprintf("0x%X (correct)", got)
+	}
+	return fmt.Sprintf("0x%X (incorrect, expected 0x%X)", got, want)
+}
+
+func priorityName(bits string) string {
+	switch bits {
+	case "00":
+		return "highest"
+	case "01":
+		return "high"
+	case "10":
+		return "average"
+	default:
+		return "low"
+	}
+}
+
+func packetTypeName(pt byte) string {
+	switch pt {
+	case PtResponsePacket:
+		return "EGTS_PT_RESPONSE"
+	case PtAppdataPacket:
+		return "EGTS_PT_APPDATA"
+	case PtSignedAppdataPacket:
+		return "EGTS_PT_SIGNED_APPDATA"
+	default:
+		return fmt.Sprintf("unknown packet type %d", pt)
+	}
+}
+
+func serviceName(st byte) string {
+	switch st {
+	case AuthService:
+		return "EGTS_AUTH_SERVICE"
+	case TeledataService:
+		return "EGTS_TELEDATA_SERVICE"
+	case CommandsService:
+		return "EGTS_COMMANDS_SERVICE"
+	default:
+		return fmt.Sprintf("service %d", st)
+	}
+}
+
+var commandTypeNames = map[uint8]string{
+	CtComConf: "CT_COMCONF",
+	CtMsgConf: "CT_MSGCONF",
+	CtMsgFrom: "CT_MSGFROM",
+	CtMsgTo:   "CT_MSGTO",
+	CtCom:     "CT_COM",
+	CtDelCom:  "CT_DELCOM",
+	CtSubReq:  "CT_SUBREQ",
+	CtDeliv:   "CT_DELIV",
+}
+
+func commandTypeName(ct uint8) string {
+	if name, ok := commandTypeNames[ct]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+var confirmationTypeNames = map[uint8]string{
+	CcOk:     "CC_OK",
+	CcError:  "CC_ERROR",
+	CcIll:    "CC_ILL",
+	CcDel:    "CC_DEL",
+	CcNFound: "CC_NFOUND",
+	CcNConf:  "CC_NCONF",
+	CcInProg: "CC_INPROG",
+}
+
+func confirmationTypeName(cct uint8) string {
+	if name, ok := confirmationTypeNames[cct]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+var subrecordNames = map[byte]string{
+	SrRecordResponseType:     "EGTS_SR_RECORD_RESPONSE",
+	SrTermIdentityType:       "EGTS_SR_TERM_IDENTITY",
+	SrModuleDataType:         "EGTS_SR_MODULE_DATA",
+	SrDispatcherIdentityType: "EGTS_SR_DISPATCHER_IDENTITY",
+	SrAuthInfoType:           "EGTS_SR_AUTH_INFO",
+	SrResultCodeType:         "EGTS_SR_RESULT_CODE",
+	SrEgtsPlusDataType:       "EGTS_SR_EGTSPLUS_DATA",
+	SrPosDataType:            "EGTS_SR_POS_DATA",
+	SrExtPosDataType:         "EGTS_SR_EXT_POS_DATA",
+	SrAdSensorsDataType:      "EGTS_SR_AD_SENSORS_DATA",
+	SrCountersDataType:       "EGTS_SR_COUNTERS_DATA",
+	SrStateDataType:          "EGTS_SR_STATE_DATA",
+	SrLoopinDataType:         "EGTS_SR_LOOPIN_DATA",
+	SrAbsDigSensDataType:     "EGTS_SR_ABS_DIG_SENS_DATA",
+	SrAbsAnSensDataType:      "EGTS_SR_ABS_AN_SENS_DATA",
+	SrAbsCntrDataType:        "EGTS_SR_ABS_CNTR_DATA",
+	SrAbsLoopinDataType:      "EGTS_SR_ABS_LOOPIN_DATA",
+	SrLiquidLevelSensorType:  "EGTS_SR_LIQUID_LEVEL_SENSOR",
+	SrPassengersCountersType: "EGTS_SR_PASSENGERS_COUNTERS",
+	SrCommandDataType:        "EGTS_SR_COMMAND_DATA",
+}
+
+func subrecordName(srt byte, srl int) string {
+	if srt == SrType20 {
+		if srl == 5 {
+			return "EGTS_SR_STATE_DATA"
+		}
+		return "EGTS_SR_ACCEL_DATA"
+	}
+	if name, ok := subrecordNames[srt]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+var resultCodeNames = map[uint8]string{
+	EgtsPcOk:             "EGTS_PC_OK",
+	EgtsPcInProgress:     "EGTS_PC_IN_PROGRESS",
+	EgtsPcUnsProtocol:    "EGTS_PC_UNS_PROTOCOL",
+	EgtsPcDecryptError:   "EGTS_PC_DECRYPT_ERROR",
+	EgtsPcProcDenied:     "EGTS_PC_PROC_DENIED",
+	EgtsPcIncHeaderform:  "EGTS_PC_INC_HEADERFORM",
+	EgtsPcIncDataform:    "EGTS_PC_INC_DATAFORM",
+	EgtsPcUnsType:        "EGTS_PC_UNS_TYPE",
+	EgtsPcNotenParams:    "EGTS_PC_NOTEN_PARAMS",
+	EgtsPcDblProc:        "EGTS_PC_DBL_PROC",
+	EgtsPcProcSrcDenied:  "EGTS_PC_PROC_SRC_DENIED",
+	EgtsPcHeaderCrcError: "EGTS_PC_HEADERCRC_ERROR",
+	EgtsPcDatacrcError:   "EGTS_PC_DATACRC_ERROR",
+	EgtsPcInvdatalen:     "EGTS_PC_INVDATALEN",
+	EgtsPcRouteNfound:    "EGTS_PC_ROUTE_NFOUND",
+	EgtsPcRouteClosed:    "EGTS_PC_ROUTE_CLOSED",
+	EgtsPcRouteDenied:    "EGTS_PC_ROUTE_DENIED",
+	EgtsPcInvaddr:        "EGTS_PC_INVADDR",
+	EgtsPcTtlexpired:     "EGTS_PC_TTLEXPIRED",
+	EgtsPcNoAck:          "EGTS_PC_NO_ACK",
+	EgtsPcObjNfound:      "EGTS_PC_OBJ_NFOUND",
+	EgtsPcEvntNfound:     "EGTS_PC_EVNT_NFOUND",
+	EgtsPcSrvcNfound:     "EGTS_PC_SRVC_NFOUND",
+	EgtsPcSrvcDenied:     "EGTS_PC_SRVC_DENIED",
+	EgtsPcSrvcUnkn:       "EGTS_PC_SRVC_UNKN",
+	EgtsPcAuthPenied:     "EGTS_PC_AUTH_DENIED",
+	EgtsPcAlreadyExists:  "EGTS_PC_ALREADY_EXISTS",
+	EgtsPcIDNfound:       "EGTS_PC_ID_NFOUND",
+	EgtsPcIncDatetime:    "EGTS_PC_INC_DATETIME",
+	EgtsPcIoError:        "EGTS_PC_IO_ERROR",
+	EgtsPcNoResAvail:     "EGTS_PC_NO_RES_AVAIL",
+	EgtsPcModuleFault:    "EGTS_PC_MODULE_FAULT",
+	EgtsPcModulePwrFlt:   "EGTS_PC_MODULE_PWR_FLT",
+	EgtsPcModuleProcFlt:  "EGTS_PC_MODULE_PROC_FLT",
+	EgtsPcModuleSwFlt:    "EGTS_PC_MODULE_SW_FLT",
+	EgtsPcModuleFwFlt:    "EGTS_PC_MODULE_FW_FLT",
+	EgtsPcModuleIoFlt:    "EGTS_PC_MODULE_IO_FLT",
+	EgtsPcModuleMemFlt:   "EGTS_PC_MODULE_MEM_FLT",
+	EgtsPcTestFailed:     "EGTS_PC_TEST_FAILED",
+}
+
+func resultCodeName(code uint8) string {
+	if name, ok := resultCodeNames[code]; ok {
+		return fmt.Sprintf("%d (%s)", code, name)
+	}
+	return fmt.Sprint(code)
+}
@@ -0,0 +1,254 @@
+package egts
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// findField returns the first field with the name in the depth-first order.
+// Several names are looked up one inside another.
+func findField(f *Field, names ...string) *Field {
+	if len(names) == 0 {
+		return f
+	}
+	if f.Name == names[0] {
+		return findField(f, names[1:]...)
+	}
+	for i := range f.Fields {
+		if found := findField(&f.Fields[i], names...); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func TestDissect(t *testing.T) {
+	root, err := Dissect(egtsPkgPosDataBytes)
+	if !assert.NoError(t, err) {
+		return
+	}
+	t.Log("\n" + root.String())
+
+	tests := []struct {
+		name   string
+		offset int
+		raw    []byte
+		value  string
+	}{
+		{"PT", 9, []byte{0x01}, "EGTS_PT_APPDATA"},
+		{"HCS", 10, []byte{0x49}, "0x49 (correct)"},
+		{"RN", 13, []byte{0x61, 0x00}, "97"},
+		{"OBFE", 15, []byte{0x99}, "1 (OID present)"},
+		{"OID", 16, []byte{0xB0, 0x09, 0x02, 0x00}, "133552"},
+		{"SST", 20, []byte{0x02}, "EGTS_TELEDATA_SERVICE"},
+		{"SRT", 22, []byte{0x10}, "16 (EGTS_SR_POS_DATA)"},
+		{"NTM", 25, []byte{0xD5, 0x3F, 0x01, 0x10}, "2018-07-05 20:08:53 UTC"},
+		{"LAT", 29, []byte{0x6F, 0x1C, 0x05, 0x9E}, "55.553894°"},
+		{"VLD", 37, []byte{0x01}, "1 (valid)"},
+		{"DIR", 40, []byte{0x2C}, "300°"},
+		{"SRC", 45, []byte{0x00}, "0 (ignition_on_timer)"},
+		{"SFRCS", 46, []byte{0xCC, 0x27}, "0x27CC (correct)"},
+	}
+	for _, tt := range tests {
+		f := findField(root, tt.name)
+		if assert.NotNil(t, f, tt.name) {
+			assert.Equal(t, tt.offset, f.Offset, tt.name)
+			assert.Equal(t, tt.raw, f.Raw, tt.name)
+			assert.Equal(t, tt.value, f.Value, tt.name)
+		}
+	}
+	assert.Nil(t, findField(root, "problem"))
+	assert.True(t, strings.Contains(root.String(), "    RN: 97 [@13: 61 00]\n"))
+}
+
+func TestDissect_Problems(t *testing.T) {
+	content := corruptPosData(10, 0x00)
+
+	root, err := Dissect(content)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "0x0 (incorrect, expected 0x49)", findField(root, "HCS").Value)
+		if problem := findField(root, "problem"); assert.NotNil(t, problem) {
+			assert.Equal(t, 10, problem.Offset)
+		}
+	}
+}
+
+func TestDissect_Response(t *testing.T) {
+	p := Packet{}
+	if !assert.NoError(t, p.Decode(egtsPkgPosDataBytes)) {
+		return
+	}
+	resp, err := p.Response()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	root, err := Dissect(resp)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "138", findField(root, "RPID").Value)
+		assert.Equal(t, "0 (EGTS_PC_OK)", findField(root, "SFRD", "PR").Value)
+		assert.Equal(t, "97", findField(root, "CRN").Value)
+		assert.Equal(t, "0 (EGTS_PC_OK)", findField(root, "SRD", "RST").Value)
+	}
+}
+
+// subrecordPacket returns the packet of the single record with the subrecord, its data begins at the offset 21.
+func subrecordPacket(srt byte, srd []byte) []byte {
+	sr := append([]byte{srt, byte(len(srd)), byte(len(srd) >> 8)}, srd...)
+	sfrd := append([]byte{byte(len(sr)), byte(len(sr) >> 8), 0x01, 0x00, 0x00, TeledataService, TeledataService},
+		sr...)
+	content := []byte{0x01, 0x00, 0x03, 0x0B, 0x00, byte(len(sfrd)), byte(len(sfrd) >> 8), 0x01, 0x00,
+		PtAppdataPacket}
+	content = append(content, CRC8(content))
+	content = append(content, sfrd...)
+	crc := CRC16(sfrd)
+	return append(content, byte(crc), byte(crc>>8))
+}
+
+func TestDissect_Subrecords(t *testing.T) {
+	const base = 21
+
+	type field struct {
+		name   string
+		offset int
+		raw    []byte
+		value  string
+	}
+	tests := []struct {
+		name   string
+		srt    byte
+		srd    []byte
+		fields []field
+	}{
+		{
+			name: "ext pos data",
+			srt:  SrExtPosDataType,
+			srd:  []byte{0x1F, 0x0A, 0x00, 0x0C, 0x00, 0x0F, 0x00, 0x09, 0x03, 0x00},
+			fields: []field{
+				{"FLG", 0, []byte{0x1F}, "00011111"},
+				{"VFE", 0, []byte{0x1F}, "1 (VDOP present)"},
+				{"NSFE", 0, []byte{0x1F}, "1 (NS present)"},
+				{"VDOP", 1, []byte{0x0A, 0x00}, "1.0"},
+				{"HDOP", 3, []byte{0x0C, 0x00}, "1.2"},
+				{"PDOP", 5, []byte{0x0F, 0x00}, "1.5"},
+				{"SAT", 7, []byte{0x09}, "9"},
+				{"NS", 8, []byte{0x03, 0x00}, "0000000000000011"},
+			},
+		},
+		{
+			name: "ad sensors data",
+			srt:  SrAdSensorsDataType,
+			srd:  []byte{0x01, 0x0F, 0x02, 0xA5, 0x10, 0x27, 0x00},
+			fields: []field{
+				{"DIOE1", 0, []byte{0x01}, "1 (ADIO1 present)"},
+				{"DIOE2", 0, []byte{0x01}, "0 (ADIO2 absent)"},
+				{"DOUT", 1, []byte{0x0F}, "00001111"},
+				{"ASFE2", 2, []byte{0x02}, "1 (ANS2 present)"},
+				{"ADIO1", 3, []byte{0xA5}, "10100101"},
+				{"ANS2", 4, []byte{0x10, 0x27, 0x00}, "10000"},
+			},
+		},
+		{
+			name: "liquid level sensor",
+			srt:  SrLiquidLevelSensorType,
+			srd:  []byte{0x22, 0x01, 0x00, 0xD2, 0x04, 0x00, 0x00},
+			fields: []field{
+				{"LLSEF", 0, []byte{0x22}, "0 (no error)"},
+				{"LLSVU", 0, []byte{0x22}, "10 (0.1 l)"},
+				{"RDF", 0, []byte{0x22}, "0 (LLSD)"},
+				{"LLSN", 0, []byte{0x22}, "010 (sensor 2)"},
+				{"MADDR", 1, []byte{0x01, 0x00}, "1"},
+				{"LLSD", 3, []byte{0xD2, 0x04, 0x00, 0x00}, "123.4 l"},
+			},
+		},
+		{
+			name: "counters data",
+			srt:  SrCountersDataType,
+			srd:  []byte{0x05, 0x01, 0x00, 0x00, 0x40, 0xE2, 0x01},
+			fields: []field{
+				{"CFE3", 0, []byte{0x05}, "1 (CN3 present)"},
+				{"CFE2", 0, []byte{0x05}, "0 (CN2 absent)"},
+				{"CN1", 1, []byte{0x01, 0x00, 0x00}, "1"},
+				{"CN3", 4, []byte{0x40, 0xE2, 0x01}, "123456"},
+			},
+		},
+		{
+			name: "term identity",
+			srt:  SrTermIdentityType,
+			srd: append(append([]byte{0xB0, 0x09, 0x02, 0x00, 0x53, 0x01, 0x00}, "352093089612345"...),
+				0x00, 0x04),
+			fields: []field{
+				{"TID", 0, []byte{0xB0, 0x09, 0x02, 0x00}, "133552"},
+				{"FLG", 4, []byte{0x53}, "01010011"},
+				{"BSE", 4, []byte{0x53}, "1 (BS present)"},
+				{"SSRA", 4, []byte{0x53}, "1 (simple response algorithm)"},
+				{"IMEIE", 4, []byte{0x53}, "1 (IMEI present)"},
+				{"HDID", 5, []byte{0x01, 0x00}, "1"},
+				{"IMEI", 7, []byte("352093089612345"), "352093089612345"},
+				{"BS", 22, []byte{0x00, 0x04}, "1024"},
+			},
+		},
+		{
+			name: "auth info",
+			srt:  SrAuthInfoType,
+			srd:  []byte("user\x00pass\x00"),
+			fields: []field{
+				{"UNM", 0, []byte("user\x00"), `"user"`},
+				{"UPSW", 5, []byte("pass\x00"), `"pass"`},
+			},
+		},
+		{
+			name: "command data",
+			srt:  SrCommandDataType,
+			srd:  testSrCommandDataBytes,
+			fields: []field{
+				{"CT", 0, []byte{0x50}, "0101 (CT_COM)"},
+				{"CCT", 0, []byte{0x50}, "0000 (CC_OK)"},
+				{"CID", 1, []byte{0x01, 0x00, 0x00, 0x00}, "1"},
+				{"ACFE", 9, []byte{0x02}, "1 (ACL and AC present)"},
+				{"CHSFE", 9, []byte{0x02}, "0 (CHS absent)"},
+				{"ACL", 10, []byte{0x02}, "2"},
+				{"AC", 11, []byte("12"), "2 bytes"},
+				{"ADR", 13, []byte{0x00, 0x00}, "0"},
+				{"ACT", 15, []byte{0x01}, "0001 (1)"},
+				{"CCD", 16, []byte{0x03, 0x02}, "0x0203"},
+			},
+		},
+		{
+			name: "accel data",
+			srt:  SrType20,
+			srd:  testSrAccelDataBytes,
+			fields: []field{
+				{"SA", 0, []byte{0x02}, "2"},
+				{"ATM", 1, []byte{0x55, 0x91, 0x02, 0x10}, "2018-07-06 20:08:53 UTC"},
+				{"RTM", 5, []byte{0x00, 0x00}, "0 ms"},
+				{"XAAV", 7, []byte{0x62, 0x00}, "9.8 m/s²"},
+				{"YAAV", 9, []byte{0xCF, 0xFF}, "-4.9 m/s²"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := Dissect(subrecordPacket(tt.srt, tt.srd))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Nil(t, findField(root, "problem"))
+
+			srd := findField(root, "SR", "SRD")
+			if !assert.NotNil(t, srd) {
+				return
+			}
+			for _, f := range tt.fields {
+				got := findField(srd, f.name)
+				if assert.NotNil(t, got, f.name) {
+					assert.Equal(t, base+f.offset, got.Offset, f.name)
+					assert.Equal(t, f.raw, got.Raw, f.name)
+					assert.Equal(t, f.value, got.Value, f.name)
+				}
+			}
+		})
+	}
+}
@@ -49,4 +49,12 @@ const (
 var (
 	// ErrSecretKey represents the error of secret key is nil.
 	ErrSecretKey = errors.New("package is encrypted but secret key is nil")
+	// ErrHeaderCheckSum represents the error of header checksum (HCS) mismatch.
+	ErrHeaderCheckSum = errors.New("incorrect checksum of header")
+	// ErrFrameCheckSum represents the error of services frame data checksum (SFRCS) mismatch.
+	ErrFrameCheckSum = errors.New("incorrect checksum of body")
+	// ErrRecordLength represents the error of record or subrecord length exceeding the data.
+	ErrRecordLength = errors.New("length exceeds the data")
+	// ErrUnknownSubrecord represents the error of unknown subrecord type.
+	ErrUnknownSubrecord = errors.New("unknown subrecord type")
 )
@@ -0,0 +1,140 @@
+package egts
+
+import (
+	"fmt"
+
+	"github.com/gotrackery/protocol/common"
+)
+
+// EventSource is the source (event) that initiated sending of the navigation data (SRC field of EGTS_SR_POS_DATA).
+type EventSource byte
+
+// Sources of EGTS_TELEDATA_SERVICE coordinate data parcels.
+const (
+	SrcIgnitionOnTimer         EventSource = 0  // timer when the ignition is on
+	SrcDistance                EventSource = 1  // covered distance
+	SrcCourseChange            EventSource = 2  // exceeding the set value of the rotation angle
+	SrcRequest                 EventSource = 3  // response to a request
+	SrcInputChange             EventSource = 4  // change in the state of input X
+	SrcIgnitionOffTimer        EventSource = 5  // timer when the ignition is off
+	SrcPeripheralDisconnect    EventSource = 6  // disconnecting peripheral equipment
+	SrcSpeedExceeded           EventSource = 7  // exceeding one of the preset speed thresholds
+	SrcRestart                 EventSource = 8  // restart of the central processor
+	SrcOutputOverload          EventSource = 9  // Y output overload
+	SrcTamper                  EventSource = 10 // tamper switch is triggered
+	SrcExternalPowerLost       EventSource = 11 // switching to backup power / disconnecting external power
+	SrcBackupBatteryLow        EventSource = 12 // the backup power supply voltage drops below the threshold
+	SrcPanicButton             EventSource = 13 // the "panic button" has been pressed
+	SrcVoiceCallRequest        EventSource = 14 // request to establish a voice connection with the operator
+	SrcEmergencyCall           EventSource = 15 // emergency call
+	SrcExternalService         EventSource = 16 // appearance of data from an external service
+	SrcBackupBatteryFailure    EventSource = 19 // backup battery failure
+	SrcHarshAcceleration       EventSource = 20 // rapid acceleration
+	SrcHarshBraking            EventSource = 21 // sudden braking
+	SrcNavModuleFailure        EventSource = 22 // disconnection or malfunction of the navigation module
+	SrcCrashSensorFailure      EventSource = 23 // disconnection or malfunction of the accident sensor
+	SrcGsmAntennaFailure       EventSource = 24 // GSM/UMTS antenna failure or malfunction
+	SrcNavAntennaFailure       EventSource = 25 // disconnection or malfunction of the navigation antenna
+	SrcSpeedDropped            EventSource = 27 // speed drop below one of the preset thresholds
+	SrcMovingIgnitionOff       EventSource = 28 // moving when the ignition is off
+	SrcEmergencyTrackingTimer  EventSource = 29 // timer in "emergency tracking" mode
+	SrcNavigationStartStop     EventSource = 30 // start/end of navigation
+	SrcUnstableNavigation      EventSource = 31 // exceeding the navigation interruption frequency threshold
+	SrcIPConnection            EventSource = 32 // setting up an IP connection
+	SrcUnstableGsmRegistration EventSource = 33 // unstable registration in the mobile radio telephone network
+	SrcUnstableCommunication   EventSource = 34 // exceeding the communication interruption frequency threshold
+	SrcModeChange              EventSource = 35 // change of operation mode
+)
+
+var eventSourceNames = map[EventSource]string{
+	SrcIgnitionOnTimer:         "ignition_on_timer",
+	SrcDistance:                "distance",
+	SrcCourseChange:            "course_change",
+	SrcRequest:                 "request",
+	SrcInputChange:             "input_change",
+	SrcIgnitionOffTimer:        "ignition_off_timer",
+	SrcPeripheralDisconnect:    "peripheral_disconnect",
+	SrcSpeedExceeded:           "speed_exceeded",
+	SrcRestart:                 "restart",
+	SrcOutputOverload:          "output_overload",
+	SrcTamper:                  "tamper",
+	SrcExternalPowerLost:       "external_power_lost",
+	SrcBackupBatteryLow:        "backup_battery_low",
+	SrcPanicButton:             "panic_button",
+	SrcVoiceCallRequest:        "voice_call_request",
+	SrcEmergencyCall:           "emergency_call",
+	SrcExternalService:         "external_service",
+	SrcBackupBatteryFailure:    "backup_battery_failure",
+	SrcHarshAcceleration:       "harsh_acceleration",
+	SrcHarshBraking:            "harsh_braking",
+	SrcNavModuleFailure:        "nav_module_failure",
+	SrcCrashSensorFailure:      "crash_sensor_failure",
+	SrcGsmAntennaFailure:       "gsm_antenna_failure",
+	SrcNavAntennaFailure:       "nav_antenna_failure",
+	SrcSpeedDropped:            "speed_dropped",
+	SrcMovingIgnitionOff:       "moving_ignition_off",
+	SrcEmergencyTrackingTimer:  "emergency_tracking_timer",
+	SrcNavigationStartStop:     "navigation_start_stop",
+	SrcUnstableNavigation:      "unstable_navigation",
+	SrcIPConnection:            "ip_connection",
+	SrcUnstableGsmRegistration: "unstable_gsm_registration",
+	SrcUnstableCommunication:   "unstable_communication",
+	SrcModeChange:              "mode_change",
+}
+
+// String returns the name of the event source. Reserved and unknown codes are named by their number.
+func (s EventSource) String() string {
+	if name, ok := eventSourceNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("source_%d", byte(s))
+}
+
+// IsAlarm reports whether the source signals an alarm (panic button or emergency call).
+func (s EventSource) IsAlarm() bool {
+	return s == SrcPanicButton || s == SrcEmergencyCall
+}
+
+// Ignition returns the ignition state implied by the source, ok is false if the source says nothing about it.
+func (s EventSource) Ignition() (on bool, ok bool) {
+	switch s { //nolint:exhaustive
+	case SrcIgnitionOnTimer:
+		return true, true
+	case SrcIgnitionOffTimer, SrcMovingIgnitionOff:
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// EventAttributes maps the source of the navigation data and its SRCD to common event attributes.
+// SRCD is kept as is and interpreted according to the source: the number of the input for the input change,
+// the number of the output for the output overload and the new operating mode of the terminal for the mode change.
+func (e *SrPosData) EventAttributes() common.Attributes {
+	attrs := common.Attributes{common.Event: e.Source.String()}
+	if on, ok := e.Source.Ignition(); ok {
+		attrs[common.Ignition] = boolToInt64(on)
+	}
+	if e.Source.IsAlarm() {
+		attrs[common.Alarm] = int64(1)
+	}
+	if e.HasSourceData {
+		attrs[common.EventData] = int64(e.SourceData)
+		switch e.Source { //nolint:exhaustive
+		case SrcInputChange:
+			attrs[common.EventInput] = int64(e.SourceData)
+		case SrcOutputOverload:
+			attrs[common.EventOutput] = int64(e.SourceData)
+		case SrcModeChange:
+			attrs[common.State] = TerminalState(e.SourceData).String()
+		}
+	}
+	return attrs
+}
+
+func boolToInt64(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
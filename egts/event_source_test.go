@@ -0,0 +1,81 @@
+package egts
+
+import (
+	"testing"
+
+	"github.com/gotrackery/protocol/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSource_String(t *testing.T) {
+	assert.Equal(t, "ignition_on_timer", SrcIgnitionOnTimer.String())
+	assert.Equal(t, "panic_button", SrcPanicButton.String())
+	assert.Equal(t, "mode_change", SrcModeChange.String())
+	assert.Equal(t, "source_17", EventSource(17).String())
+}
+
+func TestSrPosData_EventAttributes(t *testing.T) {
+	tests := []struct {
+		name string
+		data SrPosData
+		want common.Attributes
+	}{
+		{
+			name: "ignition on",
+			data: SrPosData{Source: SrcIgnitionOnTimer},
+			want: common.Attributes{common.Event: "ignition_on_timer", common.Ignition: int64(1)},
+		},
+		{
+			name: "moving with ignition off",
+			data: SrPosData{Source: SrcMovingIgnitionOff},
+			want: common.Attributes{common.Event: "moving_ignition_off", common.Ignition: int64(0)},
+		},
+		{
+			name: "panic button",
+			data: SrPosData{Source: SrcPanicButton},
+			want: common.Attributes{common.Event: "panic_button", common.Alarm: int64(1)},
+		},
+		{
+			name: "input change",
+			data: SrPosData{Source: SrcInputChange, SourceData: 3, HasSourceData: true},
+			want: common.Attributes{
+				common.Event:      "input_change",
+				common.EventData:  int64(3),
+				common.EventInput: int64(3),
+			},
+		},
+		{
+			name: "input change without SRCD",
+			data: SrPosData{Source: SrcInputChange},
+			want: common.Attributes{common.Event: "input_change"},
+		},
+		{
+			name: "output overload",
+			data: SrPosData{Source: SrcOutputOverload, SourceData: 2, HasSourceData: true},
+			want: common.Attributes{
+				common.Event:       "output_overload",
+				common.EventData:   int64(2),
+				common.EventOutput: int64(2),
+			},
+		},
+		{
+			name: "speed exceeded",
+			data: SrPosData{Source: SrcSpeedExceeded, SourceData: 1, HasSourceData: true},
+			want: common.Attributes{common.Event: "speed_exceeded", common.EventData: int64(1)},
+		},
+		{
+			name: "mode change",
+			data: SrPosData{Source: SrcModeChange, SourceData: 4, HasSourceData: true},
+			want: common.Attributes{
+				common.Event:     "mode_change",
+				common.EventData: int64(4),
+				common.State:     "emergency_tracking",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.data.EventAttributes())
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package egts
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gotrackery/protocol/common"
+)
+
+// Units of the liquid level sensor readings (LLSVU field of EGTS_SR_LIQUID_LEVEL_SENSOR).
+const (
+	// LLSVURaw is the uncalibrated reading.
+	LLSVURaw = "00"
+	// LLSVUPercent is the reading in percent of the tank capacity.
+	LLSVUPercent = "01"
+	// LLSVULitres is the reading in litres with a resolution of 0.1 l.
+	LLSVULitres = "10"
+)
+
+// Units of the EGTS+ fuel level sensor value (unit field of sens_fuel_level).
+const (
+	FuelUnitLitres      uint32 = 1
+	FuelUnitMillilitres uint32 = 2
+)
+
+var (
+	// ErrFuelSensorFault is returned when the liquid level sensor reports an error.
+	ErrFuelSensorFault = errors.New("liquid level sensor fault")
+	// ErrFuelNotCalibrated is returned when there is no calibration to convert the reading into litres.
+	ErrFuelNotCalibrated = errors.New("liquid level sensor is not calibrated")
+	// ErrFuelUnit is returned when the units of the reading are not supported.
+	ErrFuelUnit = errors.New("unsupported liquid level sensor units")
+)
+
+// FuelSensor is the calibration of a liquid level sensor.
+type FuelSensor struct {
+	// Table converts raw readings into litres.
+	Table common.CalibrationTable
+	// Capacity is the tank capacity in litres used for the readings in percent.
+	Capacity float64
+}
+
+// FuelCalibration holds the calibrations of liquid level sensors by the sensor number.
+type FuelCalibration map[uint32]FuelSensor
+
+// Litres converts the sensor reading into litres according to its units (LLSVU) and error flag (LLSEF).
+func (e *SrLiquidLevelSensor) Litres(c FuelCalibration) (float64, error) {
+	if e.LiquidLevelSensorErrorFlag == "1" {
+		return 0, fmt.Errorf("sensor %d: %w", e.LiquidLevelSensorNumber, ErrFuelSensorFault)
+	}
+	if e.RawDataFlag == "1" {
+		return 0, fmt.Errorf("sensor %d raw port data: %w", e.LiquidLevelSensorNumber, ErrFuelUnit)
+	}
+
+	sensor, ok := c[uint32(e.LiquidLevelSensorNumber)]
+	value := float64(e.LiquidLevelSensorData)
+	switch e.LiquidLevelSensorValueUnit {
+	case LLSVULitres:
+		return value / 10, nil
+	case LLSVUPercent:
+		if !ok || sensor.Capacity == 0 {
+			return 0, fmt.Errorf("sensor %d capacity: %w", e.LiquidLevelSensorNumber, ErrFuelNotCalibrated)
+		}
+		return value * sensor.Capacity / 100, nil
+	case LLSVURaw:
+		if !ok || len(sensor.Table) == 0 {
+			return 0, fmt.Errorf("sensor %d table: %w", e.LiquidLevelSensorNumber, ErrFuelNotCalibrated)
+		}
+		return sensor.Table.Value(value), nil
+	default:
+		return 0, fmt.Errorf("sensor %d units %s: %w", e.LiquidLevelSensorNumber, e.LiquidLevelSensorValueUnit,
+			ErrFuelUnit)
+	}
+}
+
+// Litres converts the EGTS+ fuel level into litres. The value is used if its unit is known,
+// otherwise the reading in conventional units (parrots) is calibrated by the sensor table.
+func (m *SensFuelLevel) Litres(c FuelCalibration) (float64, error) {
+	if m.Value != nil {
+		switch m.GetUnit() {
+		case FuelUnitLitres:
+			return float64(m.GetValue()), nil
+		case FuelUnitMillilitres:
+			return float64(m.GetValue()) / 1000, nil
+		}
+	}
+
+	sensor, ok := c[m.GetSensNum()]
+	if m.Parrots == nil || !ok || len(sensor.Table) == 0 {
+		return 0, fmt.Errorf("sensor %d: %w", m.GetSensNum(), ErrFuelNotCalibrated)
+	}
+	return sensor.Table.Value(float64(m.GetParrots())), nil
+}
+
+// addFuelLevel adds the level of the sensor to the attributes and sums it into the total fuel level.
+func addFuelLevel(attrs common.Attributes, sensor uint32, litres float64) {
+	attrs[fmt.Sprintf("%s_%d", common.FuelLevel, sensor)] = litres
+	total, _ := attrs[common.FuelLevel].(float64)
+	attrs[common.FuelLevel] = total + litres
+}
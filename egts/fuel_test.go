@@ -0,0 +1,137 @@
+package egts
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/gotrackery/protocol/common"
+	"github.com/stretchr/testify/assert"
+)
+
+var testFuelCalibration = FuelCalibration{
+	0: {Table: common.CalibrationTable{{Raw: 0, Value: 0}, {Raw: 4000, Value: 80}}},
+	1: {Capacity: 200},
+}
+
+func TestSrLiquidLevelSensor_Litres(t *testing.T) {
+	tests := []struct {
+		name    string
+		sensor  SrLiquidLevelSensor
+		want    float64
+		wantErr error
+	}{
+		{
+			name: "raw calibrated",
+			sensor: SrLiquidLevelSensor{LiquidLevelSensorErrorFlag: "0", LiquidLevelSensorValueUnit: LLSVURaw,
+				RawDataFlag: "0", LiquidLevelSensorNumber: 0, LiquidLevelSensorData: 1000},
+			want: 20,
+		},
+		{
+			name: "percent",
+			sensor: SrLiquidLevelSensor{LiquidLevelSensorErrorFlag: "0", LiquidLevelSensorValueUnit: LLSVUPercent,
+				RawDataFlag: "0", LiquidLevelSensorNumber: 1, LiquidLevelSensorData: 25},
+			want: 50,
+		},
+		{
+			name: "litres",
+			sensor: SrLiquidLevelSensor{LiquidLevelSensorErrorFlag: "0", LiquidLevelSensorValueUnit: LLSVULitres,
+				RawDataFlag: "0", LiquidLevelSensorNumber: 5, LiquidLevelSensorData: 1234},
+			want: 123.4,
+		},
+		{
+			name: "not calibrated",
+			sensor: SrLiquidLevelSensor{LiquidLevelSensorErrorFlag: "0", LiquidLevelSensorValueUnit: LLSVURaw,
+				RawDataFlag: "0", LiquidLevelSensorNumber: 2, LiquidLevelSensorData: 1000},
+			wantErr: ErrFuelNotCalibrated,
+		},
+		{
+			name: "sensor fault",
+			sensor: SrLiquidLevelSensor{LiquidLevelSensorErrorFlag: "1", LiquidLevelSensorValueUnit: LLSVULitres,
+				RawDataFlag: "0", LiquidLevelSensorNumber: 0, LiquidLevelSensorData: 1000},
+			wantErr: ErrFuelSensorFault,
+		},
+		{
+			name: "reserved units",
+			sensor: SrLiquidLevelSensor{LiquidLevelSensorErrorFlag: "0", LiquidLevelSensorValueUnit: "11",
+				RawDataFlag: "0", LiquidLevelSensorNumber: 0, LiquidLevelSensorData: 1000},
+			wantErr: ErrFuelUnit,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.sensor.Litres(testFuelCalibration)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.InDelta(t, tt.want, got, 1e-9)
+			}
+		})
+	}
+}
+
+func TestSensFuelLevel_Litres(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   SensFuelLevel
+		want    float64
+		wantErr bool
+	}{
+		{
+			name:  "litres",
+			level: SensFuelLevel{Value: proto.Float32(42.5), Unit: proto.Uint32(FuelUnitLitres)},
+			want:  42.5,
+		},
+		{
+			name:  "millilitres",
+			level: SensFuelLevel{Value: proto.Float32(1500), Unit: proto.Uint32(FuelUnitMillilitres)},
+			want:  1.5,
+		},
+		{
+			name:  "parrots",
+			level: SensFuelLevel{SensNum: proto.Uint32(0), Parrots: proto.Uint32(2000)},
+			want:  40,
+		},
+		{
+			name:    "not calibrated",
+			level:   SensFuelLevel{SensNum: proto.Uint32(3), Parrots: proto.Uint32(2000)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.level.Litres(testFuelCalibration)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrFuelNotCalibrated)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.InDelta(t, tt.want, got, 1e-9)
+			}
+		})
+	}
+}
+
+func TestServiceDataRecord_PositionsFuel(t *testing.T) {
+	sdr := ServiceDataRecord{
+		RecordDataSet: RecordDataSet{
+			{SubrecordType: SrPosDataType, SubrecordData: &testEgtsSrPosData},
+			{SubrecordType: SrLiquidLevelSensorType, SubrecordData: &SrLiquidLevelSensor{
+				LiquidLevelSensorErrorFlag: "0", LiquidLevelSensorValueUnit: LLSVURaw, RawDataFlag: "0",
+				LiquidLevelSensorNumber: 0, LiquidLevelSensorData: 1000,
+			}},
+			{SubrecordType: SrLiquidLevelSensorType, SubrecordData: &SrLiquidLevelSensor{
+				LiquidLevelSensorErrorFlag: "0", LiquidLevelSensorValueUnit: LLSVUPercent, RawDataFlag: "0",
+				LiquidLevelSensorNumber: 1, LiquidLevelSensorData: 10,
+			}},
+		},
+	}
+
+	positions := sdr.Positions(func(o *Options) { o.Fuel = testFuelCalibration })
+	if assert.Len(t, positions, 1) {
+		assert.InDelta(t, 20.0, positions[0].Attributes["fuel_level_0"], 1e-9)
+		assert.InDelta(t, 20.0, positions[0].Attributes["fuel_level_1"], 1e-9)
+		assert.InDelta(t, 40.0, positions[0].Attributes[common.FuelLevel], 1e-9)
+	}
+}
@@ -0,0 +1,69 @@
+package egts
+
+import (
+	"fmt"
+)
+
+// Problem is a violation of the packet structure found by the lenient decoder.
+type Problem struct {
+	// Offset is the position of the faulty field from the beginning of the packet.
+	// Offsets inside the encrypted SFRD are counted on the decrypted data.
+	Offset int `json:"offset"`
+	// Field is the name of the faulty field, e.g. HCS, SFRCS, RL, SRL, SRT.
+	Field string `json:"field"`
+	// Err describes the violation.
+	Err error `json:"-"`
+}
+
+// Error returns the description of the problem.
+func (p Problem) Error() string {
+	return fmt.Sprintf("offset %d, field %s: %v", p.Offset, p.Field, p.Err)
+}
+
+// Unwrap returns the underlying error.
+func (p Problem) Unwrap() error {
+	return p.Err
+}
+
+// decodeState collects the problems of the lenient decoding. Nil state is the strict mode.
+type decodeState struct {
+	problems []Problem
+}
+
+// report returns the error in strict mode. In lenient mode it records the problem and returns nil,
+// so the decoder goes on with the rest of the data.
+func (st *decodeState) report(offset int, field string, err error) error {
+	if st == nil {
+		return err
+	}
+	st.problems = append(st.problems, Problem{Offset: offset, Field: field, Err: err})
+	return nil
+}
+
+// warn records the problem in lenient mode only, strict mode tolerates it.
+func (st *decodeState) warn(offset int, field string, err error) {
+	if st != nil {
+		st.problems = append(st.problems, Problem{Offset: offset, Field: field, Err: err})
+	}
+}
+
+// SrRaw keeps the bytes of unknown subrecord or the subrecord which failed to decode in lenient mode.
+type SrRaw struct {
+	Data []byte `json:"DATA"`
+}
+
+// Decode keeps the set of bytes as is.
+func (e *SrRaw) Decode(content []byte) error {
+	e.Data = append([]byte(nil), content...)
+	return nil
+}
+
+// Encode returns the kept bytes.
+func (e *SrRaw) Encode() ([]byte, error) {
+	return e.Data, nil
+}
+
+// Length returns the length of the kept bytes.
+func (e *SrRaw) Length() uint16 {
+	return uint16(len(e.Data))
+}
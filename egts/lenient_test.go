@@ -0,0 +1,143 @@
+package egts
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func lenient(o *Options) { o.Lenient = true }
+
+// corruptPosData returns the copy of the position packet with the byte replaced. The body checksum is fixed
+// if the byte belongs to SFRD.
+func corruptPosData(offset int, b byte) []byte {
+	content := append([]byte(nil), egtsPkgPosDataBytes...)
+	content[offset] = b
+	if offset >= 11 && offset < 46 {
+		binary.LittleEndian.PutUint16(content[46:], CRC16(content[11:46]))
+	}
+	return content
+}
+
+func TestPacket_DecodeLenientChecksums(t *testing.T) {
+	content := corruptPosData(10, 0x00)
+	content[46] = 0x00
+
+	strict := Packet{}
+	assert.True(t, errors.Is(strict.Decode(content), ErrHeaderCheckSum))
+
+	p := Packet{}
+	if assert.NoError(t, p.Decode(content, lenient)) && assert.Len(t, p.Problems, 2) {
+		assert.Equal(t, 10, p.Problems[0].Offset)
+		assert.Equal(t, "HCS", p.Problems[0].Field)
+		assert.True(t, errors.Is(p.Problems[0], ErrHeaderCheckSum))
+		assert.Equal(t, 46, p.Problems[1].Offset)
+		assert.Equal(t, "SFRCS", p.Problems[1].Field)
+		assert.True(t, errors.Is(p.Problems[1], ErrFrameCheckSum))
+		assert.Equal(t, EgtsPcHeaderCrcError, p.ErrorCode)
+
+		sfrd := *p.ServicesFrameData.(*ServiceDataSet)
+		if assert.Len(t, sfrd, 1) {
+			assert.IsType(t, &SrPosData{}, sfrd[0].RecordDataSet[0].SubrecordData)
+		}
+	}
+}
+
+func TestPacket_DecodeLenientUnknownSubrecord(t *testing.T) {
+	content := corruptPosData(22, 0x63)
+
+	strict := Packet{}
+	assert.True(t, errors.Is(strict.Decode(content), ErrUnknownSubrecord))
+
+	p := Packet{}
+	if assert.NoError(t, p.Decode(content, lenient)) && assert.Len(t, p.Problems, 1) {
+		assert.Equal(t, 22, p.Problems[0].Offset)
+		assert.Equal(t, "SRT", p.Problems[0].Field)
+
+		rd := (*p.ServicesFrameData.(*ServiceDataSet))[0].RecordDataSet[0]
+		assert.Equal(t, byte(0x63), rd.SubrecordType)
+		assert.Equal(t, &SrRaw{Data: egtsPkgPosDataBytes[25:46]}, rd.SubrecordData)
+	}
+
+	// raw subrecord is encoded back as is
+	encoded, err := p.Encode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, content, encoded)
+	}
+}
+
+func TestPacket_DecodeLenientRecordLength(t *testing.T) {
+	content := corruptPosData(11, 0x30)
+
+	p := Packet{}
+	if assert.NoError(t, p.Decode(content, lenient)) && assert.Len(t, p.Problems, 1) {
+		assert.Equal(t, 11, p.Problems[0].Offset)
+		assert.Equal(t, "RL", p.Problems[0].Field)
+		assert.True(t, errors.Is(p.Problems[0], ErrRecordLength))
+
+		rd := (*p.ServicesFrameData.(*ServiceDataSet))[0].RecordDataSet[0]
+		assert.IsType(t, &SrPosData{}, rd.SubrecordData)
+	}
+}
+
+func TestPacket_DecodeLenientBrokenSubrecord(t *testing.T) {
+	// subrecord length covers only a part of SR_POS_DATA
+	content := corruptPosData(23, 0x04)
+
+	p := Packet{}
+	if assert.NoError(t, p.Decode(content, lenient)) && assert.NotEmpty(t, p.Problems) {
+		assert.Equal(t, 25, p.Problems[0].Offset)
+		assert.Equal(t, "SRD", p.Problems[0].Field)
+		assert.Equal(t, &SrRaw{Data: egtsPkgPosDataBytes[25:29]},
+			(*p.ServicesFrameData.(*ServiceDataSet))[0].RecordDataSet[0].SubrecordData)
+	}
+}
+
+// findProblem returns the first problem of the field.
+func findProblem(problems []Problem, field string) *Problem {
+	for i := range problems {
+		if problems[i].Field == field {
+			return &problems[i]
+		}
+	}
+	return nil
+}
+
+func TestPacket_DecodeLenientHeaderOffsets(t *testing.T) {
+	// the body is shorter than FDL
+	p := Packet{}
+	if assert.NoError(t, p.Decode(egtsPkgPosDataBytes[:30], lenient)) {
+		if problem := findProblem(p.Problems, "FDL"); assert.NotNil(t, problem) {
+			assert.Equal(t, 5, problem.Offset)
+		}
+	}
+
+	// unknown packet type
+	content := append([]byte(nil), egtsPkgPosDataBytes...)
+	content[9] = 0x05
+	content[10] = CRC8(content[:10])
+	p = Packet{}
+	if assert.NoError(t, p.Decode(content, lenient)) {
+		if problem := findProblem(p.Problems, "PT"); assert.NotNil(t, problem) {
+			assert.Equal(t, 9, problem.Offset)
+		}
+	}
+}
+
+func TestPacket_DecodeLenientHeaderLength(t *testing.T) {
+	// HL and FDL point past the end of the frame, HCS is wrong.
+	content := []byte("\x01\x00\x0300 \x00" + strings.Repeat("0", 41))
+
+	assert.NotPanics(t, func() {
+		p := Packet{}
+		_ = p.Decode(content, lenient)
+	})
+	assert.NotPanics(t, func() {
+		_, _ = Dissect(content)
+	})
+	strict := Packet{}
+	assert.Error(t, strict.Decode(content))
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"io"
 	"strconv"
 )
 
@@ -12,6 +13,8 @@ const (
 	// DefaultHeaderLen is default header length for EGTS protocol.
 	DefaultHeaderLen       = 11
 	allowedFirstByte1 byte = 0x01 // 1 - version
+	fdlOffset              = 5    // offset of FDL field in the header
+	ptOffset               = 9    // offset of PT field in the header
 )
 
 // Packet structure describes of the EGTS packet.
@@ -100,6 +103,8 @@ type Packet struct {
 	ServicesFrameDataCheckSum uint16 `json:"SFRCS"`
 	// ErrorCode contains result of decode package.
 	ErrorCode uint8 `json:"-"`
+	// Problems contains the violations found by the decoder in lenient mode.
+	Problems []Problem `json:"-"`
 }
 
 // SecretKey is interface for secret key.
@@ -111,6 +116,13 @@ type SecretKey interface {
 // Options is struct for options of decode/encode operations.
 type Options struct {
 	Secret SecretKey
+	// Validate makes the encoder check the packet with Validate before encoding.
+	Validate bool
+	// Lenient makes the decoder go on past checksum mismatches, bad lengths and unknown subrecords.
+	// Everything that could be parsed is kept, the violations are collected in Packet.Problems.
+	Lenient bool
+	// Fuel holds the calibrations of liquid level sensors used for conversion into positions.
+	Fuel FuelCalibration
 }
 
 // Decode parses the set of bytes into the packet structure.
@@ -121,6 +133,11 @@ func (p *Packet) Decode(content []byte, opt ...func(*Options)) error {
 	}
 
 	secretKey := options.Secret
+	var st *decodeState
+	if options.Lenient {
+		st = &decodeState{}
+	}
+	p.Problems = nil
 
 	var (
 		err   error
@@ -142,7 +159,7 @@ func (p *Packet) Decode(content []byte, opt ...func(*Options)) error {
 		p.ErrorCode = EgtsPcIncHeaderform
 		return fmt.Errorf("failed to read flags: %w", err)
 	}
-	flagBits := fmt.Sprintf("%08b", flags)
+	flagBits := bitString(flags)
 	p.Prefix = flagBits[:2]         // flags << 7, flags << 6
 	p.Route = flagBits[2:3]         // flags << 5
 	p.EncryptionAlg = flagBits[3:5] // flags << 4, flags << 3
@@ -203,15 +220,36 @@ func (p *Packet) Decode(content []byte, opt ...func(*Options)) error {
 		return fmt.Errorf("failed to get header crc: %w", err)
 	}
 
+	if int(p.HeaderLength) < DefaultHeaderLen || int(p.HeaderLength) > len(content) {
+		p.ErrorCode = EgtsPcIncHeaderform
+		return fmt.Errorf("incorrect header length: %d", p.HeaderLength)
+	}
 	if p.HeaderCheckSum != CRC8(content[:p.HeaderLength-1]) {
 		p.ErrorCode = EgtsPcHeaderCrcError
-		return fmt.Errorf("incorrect checksom of header: %d", p.HeaderCheckSum)
+		if err = st.report(int(p.HeaderLength)-1, "HCS",
+			fmt.Errorf("%w: %d", ErrHeaderCheckSum, p.HeaderCheckSum)); err != nil {
+			return err
+		}
+	}
+
+	// SFRD starts right after the header even if HL does not match the optional fields.
+	if _, err = buf.Seek(int64(p.HeaderLength), io.SeekStart); err != nil {
+		p.ErrorCode = EgtsPcIncHeaderform
+		return fmt.Errorf("failed to seek packet body: %w", err)
 	}
 
 	dataFrameBytes := make([]byte, p.FrameDataLength)
-	if _, err = buf.Read(dataFrameBytes); err != nil {
+	n, err := buf.Read(dataFrameBytes)
+	if err != nil && p.FrameDataLength > 0 {
 		p.ErrorCode = EgtsPcIncDataform
-		return fmt.Errorf("failed to read packet body: %w", err)
+		if err = st.report(int(p.HeaderLength), "SFRD", fmt.Errorf("failed to read packet body: %w", err)); err != nil {
+			return err
+		}
+	}
+	if n < len(dataFrameBytes) && st != nil {
+		st.warn(fdlOffset, "FDL", fmt.Errorf("frame data length %d, %d bytes left: %w",
+			p.FrameDataLength, n, ErrRecordLength))
+		dataFrameBytes = dataFrameBytes[:n]
 	}
 	switch p.PacketType {
 	case PtAppdataPacket:
@@ -220,38 +258,73 @@ func (p *Packet) Decode(content []byte, opt ...func(*Options)) error {
 		p.ServicesFrameData = &PtResponse{}
 	default:
 		p.ErrorCode = EgtsPcUnsType
-		return fmt.Errorf("unknown package type: %d", p.PacketType)
+		if err = st.report(ptOffset, "PT",
+			fmt.Errorf("unknown package type: %d", p.PacketType)); err != nil {
+			return err
+		}
+		p.ServicesFrameData = &SrRaw{}
 	}
 
 	if isEncrypted {
 		if secretKey == nil {
 			p.ErrorCode = EgtsPcDecryptError
-			return ErrSecretKey
-		}
-		dataFrameBytes, err = secretKey.Decode(dataFrameBytes)
-		if err != nil {
+			if err = st.report(int(p.HeaderLength), "SFRD", ErrSecretKey); err != nil {
+				return err
+			}
+			p.ServicesFrameData = &SrRaw{}
+		} else if dataFrameBytes, err = secretKey.Decode(dataFrameBytes); err != nil {
 			p.ErrorCode = EgtsPcDecryptError
-			return fmt.Errorf("failed to decrypt packet body: %w", err)
+			if err = st.report(int(p.HeaderLength), "SFRD",
+				fmt.Errorf("failed to decrypt packet body: %w", err)); err != nil {
+				return err
+			}
+			p.ServicesFrameData = &SrRaw{}
 		}
 	}
 
-	if err = p.ServicesFrameData.Decode(dataFrameBytes); err != nil {
+	switch sfrd := p.ServicesFrameData.(type) {
+	case *ServiceDataSet:
+		err = sfrd.decode(dataFrameBytes, st, int(p.HeaderLength))
+	case *PtResponse:
+		err = sfrd.decode(dataFrameBytes, st, int(p.HeaderLength))
+	default:
+		err = sfrd.Decode(dataFrameBytes)
+	}
+	if err != nil {
 		p.ErrorCode = EgtsPcDecryptError
 		return fmt.Errorf("failed to decode packet body: %w", err)
 	}
 
-	crcBytes := make([]byte, 2)
-	if _, err = buf.Read(crcBytes); err != nil {
+	crcOffset := int(p.HeaderLength) + int(p.FrameDataLength)
+	var crcBytes [2]byte
+	if n, _ := buf.Read(crcBytes[:]); n < len(crcBytes) {
 		p.ErrorCode = EgtsPcDecryptError
-		return fmt.Errorf("failed to read the CRC16 of the packet: %w", err)
+		if err = st.report(crcOffset, "SFRCS",
+			fmt.Errorf("failed to read the CRC16 of the packet: %w", io.ErrUnexpectedEOF)); err != nil {
+			return err
+		}
+		return p.lenientResult(st)
 	}
-	p.ServicesFrameDataCheckSum = binary.LittleEndian.Uint16(crcBytes)
+	p.ServicesFrameDataCheckSum = binary.LittleEndian.Uint16(crcBytes[:])
 
-	if p.ServicesFrameDataCheckSum != CRC16(content[p.HeaderLength:uint16(p.HeaderLength)+p.FrameDataLength]) {
+	if crcOffset > len(content) || p.ServicesFrameDataCheckSum != CRC16(content[p.HeaderLength:crcOffset]) {
 		p.ErrorCode = EgtsPcHeaderCrcError
-		return fmt.Errorf("incorrect checksom of body packer: %d", p.ServicesFrameDataCheckSum)
+		if err = st.report(crcOffset, "SFRCS",
+			fmt.Errorf("%w: %d", ErrFrameCheckSum, p.ServicesFrameDataCheckSum)); err != nil {
+			return err
+		}
 	}
-	p.ErrorCode = EgtsPcOk
+	return p.lenientResult(st)
+}
+
+// lenientResult completes the decoding: the problems found in lenient mode are kept in the packet
+// along with the error code of the last one, otherwise the error code is EgtsPcOk.
+func (p *Packet) lenientResult(st *decodeState) error {
+	if st == nil || len(st.problems) == 0 {
+		p.ErrorCode = EgtsPcOk
+		return nil
+	}
+	p.Problems = st.problems
 	return nil
 }
 
@@ -270,6 +343,12 @@ func (p *Packet) Encode(opt ...func(*Options)) ([]byte, error) {
 
 	secretKey := options.Secret
 
+	if options.Validate {
+		if err = p.Validate(); err != nil {
+			return result, fmt.Errorf("failed to validate packet: %w", err)
+		}
+	}
+
 	buf := new(bytes.Buffer)
 
 	if err = buf.WriteByte(p.ProtocolVersion); err != nil {
@@ -321,6 +400,9 @@ func (p *Packet) Encode(opt ...func(*Options)) ([]byte, error) {
 			}
 		}
 	}
+	if len(sfrd) > MaxFrameDataLength {
+		return result, fmt.Errorf("%d bytes: %w", len(sfrd), ErrFrameDataTooLarge)
+	}
 	p.FrameDataLength = uint16(len(sfrd))
 	if err = binary.Write(buf, binary.LittleEndian, p.FrameDataLength); err != nil {
 		return result, fmt.Errorf("failed to write the length of the data section: %w", err)
@@ -361,6 +443,55 @@ func (p *Packet) Encode(opt ...func(*Options)) ([]byte, error) {
 	return result, nil
 }
 
+// Validate checks the packet header and the services frame data against the constraints of the specification.
+// It returns all the violations joined, or nil if the packet is valid.
+func (p *Packet) Validate() error {
+	var v violations
+
+	if p.ProtocolVersion != allowedFirstByte1 {
+		v.add("PRV", p.ProtocolVersion, "must be 1")
+	}
+	if p.Prefix != "00" {
+		v.add("PRF", fmt.Sprintf("%q", p.Prefix), "must be 00")
+	}
+	v.flag("RTE", p.Route, 1)
+	v.flag("ENA", p.EncryptionAlg, 2)
+	v.flag("CMP", p.Compression, 1)
+	v.flag("PR", p.Priority, 2)
+
+	headerLen := byte(DefaultHeaderLen)
+	if p.Route == "1" {
+		headerLen += 5
+	}
+	if p.HeaderLength != 0 && p.HeaderLength != headerLen {
+		v.add("HL", p.HeaderLength, fmt.Sprintf("must be %d for RTE=%s", headerLen, p.Route))
+	}
+	if p.HeaderEncoding != 0 {
+		v.add("HE", p.HeaderEncoding, "must be 0")
+	}
+
+	switch p.PacketType {
+	case PtAppdataPacket:
+		if _, ok := p.ServicesFrameData.(*ServiceDataSet); !ok {
+			v.add("SFRD", fmt.Sprintf("%T", p.ServicesFrameData), "must be *ServiceDataSet for EGTS_PT_APPDATA")
+		}
+	case PtResponsePacket:
+		if _, ok := p.ServicesFrameData.(*PtResponse); !ok {
+			v.add("SFRD", fmt.Sprintf("%T", p.ServicesFrameData), "must be *PtResponse for EGTS_PT_RESPONSE")
+		}
+	default:
+		v.add("PT", p.PacketType, "is not supported")
+	}
+
+	if p.ServicesFrameData != nil {
+		if sfrd, err := p.ServicesFrameData.Encode(); err == nil {
+			v.max("FDL", uint64(len(sfrd)), MaxFrameDataLength)
+		}
+		v.validate("SFRD", p.ServicesFrameData)
+	}
+	return v.err()
+}
+
 // MarshalJSON translates the package into json. Use it to get simple text representation of the package content.
 func (p *Packet) MarshalJSON() ([]byte, error) {
 	return json.Marshal(p) //nolint:wrapcheck
@@ -368,6 +499,11 @@ func (p *Packet) MarshalJSON() ([]byte, error) {
 
 // Response prepares response for incoming packet.
 func (p *Packet) Response() ([]byte, error) {
+	return p.response(globalSequence{})
+}
+
+// response prepares response for incoming packet numbering it with the sequence.
+func (p *Packet) response(seq sequence) ([]byte, error) {
 	var (
 		resultCode []byte
 		err        error
@@ -392,12 +528,12 @@ func (p *Packet) Response() ([]byte, error) {
 			for _, subRec := range r.RecordDataSet {
 				switch subRec.SubrecordType {
 				case SrTermIdentityType:
-					resultCode, err = p.prepareSRResultCode() // ToDo move to sub record level code?
+					resultCode, err = p.prepareSRResultCode(seq) // ToDo move to sub record level code?
 					if err != nil {
 						return nil, fmt.Errorf("failed to prepare result code: %w", err)
 					}
 				case SrAuthInfoType:
-					resultCode, err = p.prepareSRResultCode() // ToDo move to sub record level code?
+					resultCode, err = p.prepareSRResultCode(seq) // ToDo move to sub record level code?
 					if err != nil {
 						return nil, fmt.Errorf("failed to prepare result code: %w", err)
 					}
@@ -415,7 +551,7 @@ func (p *Packet) Response() ([]byte, error) {
 		respSection.SDR = &ServiceDataSet{
 			ServiceDataRecord{
 				RecordLength:             dataSet.Length(),
-				RecordNumber:             nextRecordNumber(),
+				RecordNumber:             seq.nextRecordNumber(),
 				SourceServiceOnDevice:    "0",
 				RecipientServiceOnDevice: "0",
 				Group:                    "1",
@@ -441,7 +577,7 @@ func (p *Packet) Response() ([]byte, error) {
 		HeaderLength:      DefaultHeaderLen,
 		HeaderEncoding:    0,
 		FrameDataLength:   respSection.Length(),
-		PacketIdentifier:  nextPacketIdentifier(),
+		PacketIdentifier:  seq.nextPacketIdentifier(),
 		PacketType:        PtResponsePacket,
 		ServicesFrameData: &respSection,
 	}
@@ -454,7 +590,7 @@ func (p *Packet) Response() ([]byte, error) {
 }
 
 // prepareSRResultCode prepares result code (SR_Result_Code) for incoming packet.
-func (p *Packet) prepareSRResultCode() ([]byte, error) {
+func (p *Packet) prepareSRResultCode(seq sequence) ([]byte, error) {
 	data := RecordDataSet{
 		RecordData{
 			SubrecordType:   SrResultCodeType,
@@ -468,7 +604,7 @@ func (p *Packet) prepareSRResultCode() ([]byte, error) {
 	sfrd := ServiceDataSet{
 		ServiceDataRecord{
 			RecordLength:             data.Length(),
-			RecordNumber:             nextRecordNumber(),
+			RecordNumber:             seq.nextRecordNumber(),
 			SourceServiceOnDevice:    "0",
 			RecipientServiceOnDevice: "0",
 			Group:                    "1",
@@ -493,7 +629,7 @@ func (p *Packet) prepareSRResultCode() ([]byte, error) {
 		HeaderLength:      DefaultHeaderLen,
 		HeaderEncoding:    0,
 		FrameDataLength:   sfrd.Length(),
-		PacketIdentifier:  nextPacketIdentifier(),
+		PacketIdentifier:  seq.nextPacketIdentifier(),
 		PacketType:        PtResponsePacket,
 		ServicesFrameData: &sfrd,
 	}
@@ -0,0 +1,148 @@
+package egts
+
+import (
+	"strconv"
+
+	"github.com/gotrackery/protocol/common"
+	"github.com/peterstace/simplefeatures/geom"
+	"gopkg.in/guregu/null.v4"
+)
+
+const (
+	// ProtocolName is the name of the protocol set in common.Position.
+	ProtocolName = "egts"
+	// PZ90Datum is the datum of coordinates marked by the CS flag as PZ-90 (see CSPZ90).
+	PZ90Datum = common.PZ9002
+)
+
+// Positions converts all records of the APPDATA packet into positions.
+func (p *Packet) Positions(opt ...func(*Options)) []common.Position {
+	sds, ok := p.ServicesFrameData.(*ServiceDataSet)
+	if p.PacketType != PtAppdataPacket || !ok {
+		return nil
+	}
+
+	var result []common.Position
+	for i := range *sds {
+		result = append(result, (*sds)[i].Positions(opt...)...)
+	}
+	return result
+}
+
+// Positions converts the record into positions. Every EGTS_SR_POS_DATA or EGTS_SR_EGTSPLUS_DATA subrecord
+// starts a new position, the following subrecords supplement it. Coordinates in PZ-90 are transformed into WGS-84.
+// Liquid level sensors are converted into litres with Options.Fuel calibrations.
+func (s *ServiceDataRecord) Positions(opt ...func(*Options)) []common.Position {
+	options := &Options{}
+	for _, o := range opt {
+		o(options)
+	}
+
+	var (
+		result []common.Position
+		pos    *common.Position
+	)
+	for _, rd := range s.RecordDataSet {
+		n := len(result)
+		switch sr := rd.SubrecordData.(type) {
+		case *SrPosData:
+			result = append(result, sr.position())
+		case *StorageRecord:
+			result = append(result, sr.Position(opt...))
+		}
+		if len(result) > n {
+			pos = &result[len(result)-1]
+			if s.ObjectIDFieldExists == "1" {
+				pos.DeviceID = strconv.FormatUint(uint64(s.ObjectIdentifier), 10)
+			}
+			continue
+		}
+		if pos == nil {
+			continue
+		}
+
+		switch sr := rd.SubrecordData.(type) {
+		case *SrExtPosData:
+			sr.applyTo(pos)
+		case *SrStateData:
+			for k, v := range sr.Attributes() {
+				pos.Attributes[k] = v
+			}
+		case *SrLiquidLevelSensor:
+			if litres, err := sr.Litres(options.Fuel); err == nil {
+				addFuelLevel(pos.Attributes, uint32(sr.LiquidLevelSensorNumber), litres)
+			}
+		}
+	}
+	return result
+}
+
+// position converts the subrecord into position.
+func (e *SrPosData) position() common.Position {
+	lat, lon := e.Latitude, e.Longitude
+	if e.LAHS == LAHSSouth {
+		lat = -lat
+	}
+	if e.LOHS == LOHSWest {
+		lon = -lon
+	}
+
+	pos := common.Position{
+		Location: common.Location{
+			Coordinates: geom.Coordinates{XY: geom.XY{X: lon, Y: lat}, Type: geom.DimXY},
+			Valid:       e.VLD == VLDValid,
+		},
+		Protocol:   ProtocolName,
+		DeviceTime: e.NavigationTime,
+		Speed:      null.FloatFrom(float64(e.Speed)),
+		Course:     null.FloatFrom(float64(e.course())),
+		Attributes: common.Attributes{
+			common.Datum:    string(common.WGS84),
+			common.Odometer: float64(e.Odometer) / 10,
+			common.DigInput: int64(e.DigitalInputs),
+			common.Move:     boolToInt64(e.MV == MVMoving),
+		},
+	}
+	for k, v := range e.EventAttributes() {
+		pos.Attributes[k] = v
+	}
+	if e.ALTE == "1" {
+		pos.Type = geom.DimXYZ
+		pos.Z = float64(e.Altitude)
+		if e.AltitudeSign == ALTSBelowSea {
+			pos.Z = -pos.Z
+		}
+	}
+
+	if e.CS == CSPZ90 {
+		pos.Attributes[common.Datum] = string(PZ90Datum)
+		if loc, err := pos.Location.Transform(PZ90Datum, common.WGS84); err == nil {
+			pos.Location = loc
+		}
+	}
+	return pos
+}
+
+// course returns the full direction of movement in degrees, restoring the DIRH bit.
+func (e *SrPosData) course() uint16 {
+	return uint16(e.Direction&^(e.DirectionHighestBit<<7)) | uint16(e.DirectionHighestBit)<<8
+}
+
+// applyTo supplements position with the dilution of precision and satellites data.
+func (e *SrExtPosData) applyTo(pos *common.Position) {
+	if e.VdopFieldExists == "1" {
+		pos.Attributes[common.VDOP] = float64(e.VerticalDilutionOfPrecision) / 10
+	}
+	if e.HdopFieldExists == "1" {
+		pos.Attributes[common.HDOP] = float64(e.HorizontalDilutionOfPrecision) / 10
+	}
+	if e.PdopFieldExists == "1" {
+		pos.Attributes[common.PDOP] = float64(e.PositionDilutionOfPrecision) / 10
+	}
+	if e.SatellitesFieldExists == "1" {
+		pos.Attributes[common.Satellites] = int64(e.Satellites)
+	}
+	if e.NavigationSystemFieldExists == "1" {
+		pos.Attributes[common.NavSystem] = int64(e.NavigationSystem)
+	}
+}
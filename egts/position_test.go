@@ -0,0 +1,86 @@
+package egts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gotrackery/protocol/common"
+	"github.com/peterstace/simplefeatures/geom"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPacket_Positions(t *testing.T) {
+	pkg := Packet{}
+	if err := pkg.Decode(egtsPkgPosDataBytes); !assert.NoError(t, err) {
+		return
+	}
+
+	positions := pkg.Positions()
+	if assert.Len(t, positions, 1) {
+		pos := positions[0]
+		assert.Equal(t, ProtocolName, pos.Protocol)
+		assert.Equal(t, "133552", pos.DeviceID)
+		assert.Equal(t, time.Date(2018, time.July, 5, 20, 8, 53, 0, time.UTC), pos.DeviceTime)
+		assert.True(t, pos.Valid)
+		assert.Equal(t, geom.DimXY, pos.Type)
+		assert.InDelta(t, 55.55389399769574, pos.Y, 1e-9)
+		assert.InDelta(t, 37.43236696287812, pos.X, 1e-9)
+		assert.Equal(t, 200.0, pos.Speed.Float64)
+		assert.Equal(t, 300.0, pos.Course.Float64)
+		assert.Equal(t, string(common.WGS84), pos.Attributes[common.Datum])
+		assert.Equal(t, 0.1, pos.Attributes[common.Odometer])
+		assert.Equal(t, SrcIgnitionOnTimer.String(), pos.Attributes[common.Event])
+		assert.Equal(t, int64(1), pos.Attributes[common.Ignition])
+	}
+}
+
+func TestServiceDataRecord_PositionsPZ90(t *testing.T) {
+	posData := SrPosData{
+		NavigationTime: time.Date(2018, time.July, 5, 20, 8, 53, 0, time.UTC),
+		Latitude:       55.55389399769574,
+		Longitude:      37.43236696287812,
+		ALTE:           "1",
+		LOHS:           LOHSWest,
+		LAHS:           LAHSNorth,
+		MV:             MVMoving,
+		BB:             BBActual,
+		CS:             CSPZ90,
+		FIX:            FIX3D,
+		VLD:            VLDValid,
+		Altitude:       150,
+		AltitudeSign:   ALTSAboveSea,
+		Speed:          60,
+		Direction:      90,
+	}
+	sdr := ServiceDataRecord{
+		RecordDataSet: RecordDataSet{
+			{SubrecordType: SrPosDataType, SubrecordData: &posData},
+			{SubrecordType: SrExtPosDataType, SubrecordData: &SrExtPosData{
+				SatellitesFieldExists:         "1",
+				HdopFieldExists:               "1",
+				Satellites:                    12,
+				HorizontalDilutionOfPrecision: 9,
+			}},
+		},
+	}
+
+	positions := sdr.Positions()
+	if assert.Len(t, positions, 1) {
+		pos := positions[0]
+		assert.Equal(t, string(common.PZ9002), pos.Attributes[common.Datum])
+		assert.Equal(t, geom.DimXYZ, pos.Type)
+
+		lon, lat, alt, err := common.PZ9002.Transform(-posData.Longitude, posData.Latitude, 150, common.WGS84)
+		if assert.NoError(t, err) {
+			assert.InDelta(t, lon, pos.X, 1e-12)
+			assert.InDelta(t, lat, pos.Y, 1e-12)
+			assert.InDelta(t, alt, pos.Z, 1e-6)
+		}
+		assert.NotEqual(t, -posData.Longitude, pos.X)
+		assert.Equal(t, 90.0, pos.Course.Float64)
+		assert.Equal(t, int64(1), pos.Attributes[common.Move])
+		assert.Equal(t, int64(12), pos.Attributes[common.Satellites])
+		assert.Equal(t, 0.9, pos.Attributes[common.HDOP])
+	}
+	assert.Empty(t, (&ServiceDataRecord{}).Positions())
+}
@@ -15,6 +15,12 @@ type PtResponse struct {
 
 // Decode decodes the bytes into EGTS_PT_RESPONSE type struct.
 func (s *PtResponse) Decode(content []byte) error {
+	return s.decode(content, nil, 0)
+}
+
+// decode parses the response. In lenient mode the problems are reported to the state
+// with the offsets shifted by base.
+func (s *PtResponse) decode(content []byte, st *decodeState, base int) error {
 	var (
 		err error
 	)
@@ -22,18 +28,19 @@ func (s *PtResponse) Decode(content []byte) error {
 
 	tmpIntBuf := make([]byte, 2)
 	if _, err = buf.Read(tmpIntBuf); err != nil {
-		return fmt.Errorf("failed to get the packet identifier from the response: %w", err)
+		return st.report(base, "RPID", fmt.Errorf("failed to get the packet identifier from the response: %w", err))
 	}
 	s.ResponsePacketID = binary.LittleEndian.Uint16(tmpIntBuf)
 
 	if s.ProcessingResult, err = buf.ReadByte(); err != nil {
-		return fmt.Errorf("failed to get processing result code: %w", err)
+		return st.report(base+2, "PR", fmt.Errorf("failed to get processing result code: %w", err))
 	}
 
 	// if there is a service level, because it is optional
 	if buf.Len() > 0 {
-		s.SDR = &ServiceDataSet{}
-		if err = s.SDR.Decode(buf.Bytes()); err != nil {
+		sdr := &ServiceDataSet{}
+		s.SDR = sdr
+		if err = sdr.decode(buf.Bytes(), st, base+len(content)-buf.Len()); err != nil {
 			return fmt.Errorf("failed to decode service data set: %w", err)
 		}
 	}
@@ -81,3 +88,12 @@ func (s *PtResponse) Length() uint16 {
 
 	return result
 }
+
+// Validate checks the service data set of the response.
+func (s *PtResponse) Validate() error {
+	var v violations
+	if s.SDR != nil {
+		v.validate("SDR", s.SDR)
+	}
+	return v.err()
+}
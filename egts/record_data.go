@@ -18,22 +18,51 @@ type RecordDataSet []RecordData
 
 // Decode parses the set of bytes into RecordDataSet structure.
 func (rds *RecordDataSet) Decode(recDS []byte) error {
+	return rds.decode(recDS, nil, 0)
+}
+
+// countSubrecords returns the number of the subrecords by their headers to allocate the set at once.
+func countSubrecords(recDS []byte) int {
+	n := 0
+	for i := 0; i < len(recDS); n++ {
+		if i+3 > len(recDS) {
+			return n + 1
+		}
+		i += 3 + int(binary.LittleEndian.Uint16(recDS[i+1:]))
+	}
+	return n
+}
+
+// decode parses the record data. In lenient mode the problems are reported to the state with the offsets
+// shifted by base, unknown and broken subrecords are kept as SrRaw.
+func (rds *RecordDataSet) decode(recDS []byte, st *decodeState, base int) error {
 	var (
 		err error
 	)
+	if n := countSubrecords(recDS); cap(*rds)-len(*rds) < n {
+		grown := make(RecordDataSet, len(*rds), len(*rds)+n)
+		copy(grown, *rds)
+		*rds = grown
+	}
+
 	buf := bytes.NewBuffer(recDS)
 	for buf.Len() > 0 {
+		offset := base + len(recDS) - buf.Len()
 		rd := RecordData{}
-		if rd.SubrecordType, err = buf.ReadByte(); err != nil {
-			return fmt.Errorf("failed to get subrecord data record type: %w", err)
+		if buf.Len() < 3 {
+			return st.report(offset, "SRL", fmt.Errorf("failed to get subrecord data record header: %w",
+				ErrRecordLength))
 		}
+		rd.SubrecordType, _ = buf.ReadByte()
 
 		tmpIntBuf := make([]byte, 2)
-		if _, err = buf.Read(tmpIntBuf); err != nil {
-			return fmt.Errorf("failed to get subrecord data record length: %w", err)
-		}
+		_, _ = buf.Read(tmpIntBuf)
 		rd.SubrecordLength = binary.LittleEndian.Uint16(tmpIntBuf)
 
+		if int(rd.SubrecordLength) > buf.Len() {
+			st.warn(offset+1, "SRL", fmt.Errorf("subrecord length %d, %d bytes left: %w",
+				rd.SubrecordLength, buf.Len(), ErrRecordLength))
+		}
 		subRecordBytes := buf.Next(int(rd.SubrecordLength))
 
 		switch rd.SubrecordType {
@@ -56,9 +85,7 @@ func (rds *RecordDataSet) Decode(recDS []byte) error {
 			if rd.SubrecordLength == uint16(5) {
 				rd.SubrecordData = &SrStateData{}
 			} else {
-				// TODO: add EGTS_SR_ACCEL_DATA
-				return fmt.Errorf("unimplemented section EGTS_SR_ACCEL_DATA: %d. Length: %d. Contents: %X",
-					rd.SubrecordType, rd.SubrecordLength, subRecordBytes)
+				rd.SubrecordData = &SrAccelData{}
 			}
 		case SrStateDataType:
 			rd.SubrecordData = &SrStateData{}
@@ -76,13 +103,22 @@ func (rds *RecordDataSet) Decode(recDS []byte) error {
 			rd.SubrecordData = &SrAbsAnSensData{}
 		case SrDispatcherIdentityType:
 			rd.SubrecordData = &SrDispatcherIdentity{}
+		case SrCommandDataType:
+			rd.SubrecordData = &SrCommandData{}
 		default:
-			return fmt.Errorf("unknown subrecord type: %d. Length: %d. Contents: %X", rd.SubrecordType,
-				rd.SubrecordLength, subRecordBytes)
+			if err = st.report(offset, "SRT", fmt.Errorf("%w: %d. Length: %d. Contents: %X", ErrUnknownSubrecord,
+				rd.SubrecordType, rd.SubrecordLength, subRecordBytes)); err != nil {
+				return err
+			}
+			rd.SubrecordData = &SrRaw{}
 		}
 
 		if err = rd.SubrecordData.Decode(subRecordBytes); err != nil {
-			return fmt.Errorf("failed to decode subrecord data: %w", err)
+			if err = st.report(offset+3, "SRD", fmt.Errorf("failed to decode subrecord data: %w", err)); err != nil {
+				return err
+			}
+			rd.SubrecordData = &SrRaw{}
+			_ = rd.SubrecordData.Decode(subRecordBytes)
 		}
 		*rds = append(*rds, rd)
 	}
@@ -100,34 +136,8 @@ func (rds *RecordDataSet) Encode() ([]byte, error) {
 
 	for _, rd := range *rds {
 		if rd.SubrecordType == 0 {
-			switch rd.SubrecordData.(type) {
-			case *SrPosData:
-				rd.SubrecordType = SrPosDataType
-			case *SrTermIdentity:
-				rd.SubrecordType = SrTermIdentityType
-			case *SrResponse:
-				rd.SubrecordType = SrRecordResponseType
-			case *SrResultCode:
-				rd.SubrecordType = SrResultCodeType
-			case *SrExtPosData:
-				rd.SubrecordType = SrExtPosDataType
-			case *SrAdSensorsData:
-				rd.SubrecordType = SrAdSensorsDataType
-			case *SrStateData:
-				rd.SubrecordType = SrStateDataType
-			case *SrLiquidLevelSensor:
-				rd.SubrecordType = SrLiquidLevelSensorType
-			case *SrAbsCntrData:
-				rd.SubrecordType = SrAbsCntrDataType
-			case *SrAuthInfo:
-				rd.SubrecordType = SrAuthInfoType
-			case *SrCountersData:
-				rd.SubrecordType = SrCountersDataType
-			case *StorageRecord:
-				rd.SubrecordType = SrEgtsPlusDataType
-			case *SrAbsAnSensData:
-				rd.SubrecordType = SrAbsAnSensDataType
-			default:
+			var ok bool
+			if rd.SubrecordType, ok = subrecordType(rd.SubrecordData); !ok {
 				return result, fmt.Errorf("there is no known code for this type of subrecord: %T", rd.SubrecordData)
 			}
 		}
@@ -170,3 +180,73 @@ func (rds *RecordDataSet) Length() uint16 {
 
 	return result
 }
+
+// subrecordType returns the code of the subrecord type by its structure.
+func subrecordType(data BinaryData) (byte, bool) {
+	switch data.(type) {
+	case *SrPosData:
+		return SrPosDataType, true
+	case *SrTermIdentity:
+		return SrTermIdentityType, true
+	case *SrResponse:
+		return SrRecordResponseType, true
+	case *SrResultCode:
+		return SrResultCodeType, true
+	case *SrExtPosData:
+		return SrExtPosDataType, true
+	case *SrAdSensorsData:
+		return SrAdSensorsDataType, true
+	case *SrStateData:
+		return SrStateDataType, true
+	case *SrAccelData:
+		return SrType20, true
+	case *SrLiquidLevelSensor:
+		return SrLiquidLevelSensorType, true
+	case *SrAbsCntrData:
+		return SrAbsCntrDataType, true
+	case *SrAuthInfo:
+		return SrAuthInfoType, true
+	case *SrCountersData:
+		return SrCountersDataType, true
+	case *StorageRecord:
+		return SrEgtsPlusDataType, true
+	case *SrAbsAnSensData:
+		return SrAbsAnSensDataType, true
+	case *SrCommandData:
+		return SrCommandDataType, true
+	default:
+		return 0, false
+	}
+}
+
+// Validate checks the subrecords: the type must match the structure, the length must match the encoded data.
+func (rds *RecordDataSet) Validate() error {
+	var v violations
+
+	for i, rd := range *rds {
+		name := fmt.Sprintf("RD[%d]", i)
+		if rd.SubrecordData == nil {
+			v.add(name+".SRD", nil, "must be set")
+			continue
+		}
+
+		srt, ok := subrecordType(rd.SubrecordData)
+		switch {
+		case !ok:
+			if _, raw := rd.SubrecordData.(*SrRaw); !raw {
+				v.add(name+".SRD", fmt.Sprintf("%T", rd.SubrecordData), "has no known subrecord type")
+			}
+		case rd.SubrecordType != 0 && rd.SubrecordType != srt &&
+			!(srt == SrStateDataType && rd.SubrecordType == SrType20):
+			v.add(name+".SRT", rd.SubrecordType, fmt.Sprintf("does not match %T", rd.SubrecordData))
+		}
+
+		// encoding errors are caused by the invalid fields reported by the subrecord validation
+		if srd, err := rd.SubrecordData.Encode(); err == nil && rd.SubrecordLength != 0 &&
+			int(rd.SubrecordLength) != len(srd) {
+			v.add(name+".SRL", rd.SubrecordLength, fmt.Sprintf("does not match encoded length %d", len(srd)))
+		}
+		v.validate(name, rd.SubrecordData)
+	}
+	return v.err()
+}
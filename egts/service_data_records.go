@@ -35,29 +35,39 @@ type ServiceDataSet []ServiceDataRecord
 
 // Decode decodes the given byte slice into a ServiceDataRecord.
 func (s *ServiceDataSet) Decode(serviceDS []byte) error {
+	return s.decode(serviceDS, nil, 0)
+}
+
+// decode parses the records. In lenient mode the problems are reported to the state with the offsets
+// shifted by base, the record truncated in its header stops the decoding.
+func (s *ServiceDataSet) decode(serviceDS []byte, st *decodeState, base int) error {
 	var (
 		err   error
 		flags byte
 	)
 	buf := bytes.NewReader(serviceDS)
+	offset := func() int {
+		return base + len(serviceDS) - buf.Len()
+	}
 
 	for buf.Len() > 0 {
+		recOffset := offset()
 		sdr := ServiceDataRecord{}
 		tmpIntBuf := make([]byte, 2)
 		if _, err = buf.Read(tmpIntBuf); err != nil {
-			return fmt.Errorf("failed to get the SDR record length: %w", err)
+			return st.report(offset(), "RL", fmt.Errorf("failed to get the SDR record length: %w", err))
 		}
 		sdr.RecordLength = binary.LittleEndian.Uint16(tmpIntBuf)
 
 		if _, err = buf.Read(tmpIntBuf); err != nil {
-			return fmt.Errorf("failed to get SDR record number: %w", err)
+			return st.report(offset(), "RN", fmt.Errorf("failed to get SDR record number: %w", err))
 		}
 		sdr.RecordNumber = binary.LittleEndian.Uint16(tmpIntBuf)
 
 		if flags, err = buf.ReadByte(); err != nil {
-			return fmt.Errorf("failed to read the SDR flags byte: %w", err)
+			return st.report(offset(), "RFL", fmt.Errorf("failed to read the SDR flags byte: %w", err))
 		}
-		flagBits := fmt.Sprintf("%08b", flags)
+		flagBits := bitString(flags)
 		sdr.SourceServiceOnDevice = flagBits[:1]
 		sdr.RecipientServiceOnDevice = flagBits[1:2]
 		sdr.Group = flagBits[2:3]
@@ -69,7 +79,7 @@ func (s *ServiceDataSet) Decode(serviceDS []byte) error {
 		if sdr.ObjectIDFieldExists == "1" {
 			oid := make([]byte, 4)
 			if _, err := buf.Read(oid); err != nil {
-				return fmt.Errorf("failed to get SDR object identifier: %w", err)
+				return st.report(offset(), "OID", fmt.Errorf("failed to get SDR object identifier: %w", err))
 			}
 			sdr.ObjectIdentifier = binary.LittleEndian.Uint32(oid)
 		}
@@ -77,7 +87,7 @@ func (s *ServiceDataSet) Decode(serviceDS []byte) error {
 		if sdr.EventIDFieldExists == "1" {
 			event := make([]byte, 4)
 			if _, err := buf.Read(event); err != nil {
-				return fmt.Errorf("failed to get SDR event identifier: %w", err)
+				return st.report(offset(), "EVID", fmt.Errorf("failed to get SDR event identifier: %w", err))
 			}
 			sdr.EventIdentifier = binary.LittleEndian.Uint32(event)
 		}
@@ -87,28 +97,38 @@ func (s *ServiceDataSet) Decode(serviceDS []byte) error {
 		if sdr.TimeFieldExists == "1" {
 			tm := make([]byte, 4)
 			if _, err := buf.Read(tm); err != nil {
-				return fmt.Errorf("failed to get record generation time on the sender side of the SDR: %w", err)
+				return st.report(offset(), "TM",
+					fmt.Errorf("failed to get record generation time on the sender side of the SDR: %w", err))
 			}
 			preFieldVal := binary.LittleEndian.Uint32(tm)
 			sdr.Time = timeOffset.Add(time.Duration(preFieldVal) * time.Second)
 		}
 
 		if sdr.SourceServiceType, err = buf.ReadByte(); err != nil {
-			return fmt.Errorf("failed to read the identifier of the SDR sending service type: %w", err)
+			return st.report(offset(), "SST",
+				fmt.Errorf("failed to read the identifier of the SDR sending service type: %w", err))
 		}
 
 		if sdr.RecipientServiceType, err = buf.ReadByte(); err != nil {
-			return fmt.Errorf("failed to read the identifier of the SDR recipient service type: %w", err)
+			return st.report(offset(), "RST",
+				fmt.Errorf("failed to read the identifier of the SDR recipient service type: %w", err))
 		}
 
 		if buf.Len() != 0 {
 			rds := RecordDataSet{}
+			rdOffset := offset()
 			rdsBytes := make([]byte, sdr.RecordLength)
-			if _, err = buf.Read(rdsBytes); err != nil {
-				return fmt.Errorf("failed to read the SDR record data: %w", err)
+			n, err := buf.Read(rdsBytes)
+			if err != nil {
+				return st.report(rdOffset, "RD", fmt.Errorf("failed to read the SDR record data: %w", err))
+			}
+			if n < len(rdsBytes) && st != nil {
+				st.warn(recOffset, "RL", fmt.Errorf("record %d length %d, %d bytes left: %w", sdr.RecordNumber,
+					sdr.RecordLength, n, ErrRecordLength))
+				rdsBytes = rdsBytes[:n]
 			}
 
-			if err = rds.Decode(rdsBytes); err != nil {
+			if err = rds.decode(rdsBytes, st, rdOffset); err != nil {
 				return fmt.Errorf("failed to decode the SDR record data: %w", err)
 			}
 			sdr.RecordDataSet = rds
@@ -203,3 +223,35 @@ func (s *ServiceDataSet) Length() uint16 {
 
 	return result
 }
+
+// Validate checks the records of the set.
+func (s *ServiceDataSet) Validate() error {
+	var v violations
+	for i := range *s {
+		v.nested(fmt.Sprintf("SDR[%d]", i), (*s)[i].Validate())
+	}
+	return v.err()
+}
+
+// Validate checks the record flags and that the record length matches the encoded record data.
+func (sdr *ServiceDataRecord) Validate() error {
+	var v violations
+
+	v.flag("SSOD", sdr.SourceServiceOnDevice, 1)
+	v.flag("RSOD", sdr.RecipientServiceOnDevice, 1)
+	v.flag("GRP", sdr.Group, 1)
+	v.flag("RPP", sdr.RecordProcessingPriority, 2)
+	v.flag("TMFE", sdr.TimeFieldExists, 1)
+	v.flag("EVFE", sdr.EventIDFieldExists, 1)
+	v.flag("OBFE", sdr.ObjectIDFieldExists, 1)
+
+	if sdr.TimeFieldExists == "1" && sdr.Time.Before(timeOffset) {
+		v.add("TM", sdr.Time, "must not be before 2010-01-01")
+	}
+
+	if rd, err := sdr.RecordDataSet.Encode(); err == nil && sdr.RecordLength != 0 && int(sdr.RecordLength) != len(rd) {
+		v.add("RL", sdr.RecordLength, fmt.Sprintf("does not match encoded length %d", len(rd)))
+	}
+	v.nested("RD", sdr.RecordDataSet.Validate())
+	return v.err()
+}
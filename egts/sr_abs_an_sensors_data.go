@@ -36,3 +36,11 @@ func (e *SrAbsAnSensData) Encode() ([]byte, error) {
 func (e *SrAbsAnSensData) Length() uint16 {
 	return 4
 }
+
+// Validate checks the range of the sensor value of EGTS_SR_ABS_AN_SENS_DATA.
+func (e *SrAbsAnSensData) Validate() error {
+	var v violations
+
+	v.max("ASV", uint64(e.Value), 1<<24-1)
+	return v.err()
+}
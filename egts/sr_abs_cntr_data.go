@@ -70,3 +70,11 @@ func (e *SrAbsCntrData) Length() uint16 {
 
 	return result
 }
+
+// Validate checks the range of the counter value of EGTS_SR_ABS_CNTR_DATA.
+func (e *SrAbsCntrData) Validate() error {
+	var v violations
+
+	v.max("CNV", uint64(e.CounterValue), 1<<24-1)
+	return v.err()
+}
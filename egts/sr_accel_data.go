@@ -0,0 +1,140 @@
+package egts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// standardGravity is the standard acceleration due to gravity, m/s².
+const standardGravity = 9.80665
+
+// AccelSample is a single accelerometer measurement with accelerations along the axes in g.
+// It is the common representation of EGTS_SR_ACCEL_DATA and EGTS+ sens_accelerometer_data samples.
+type AccelSample struct {
+	Time time.Time
+	X    float64
+	Y    float64
+	Z    float64
+}
+
+// AccelDataStructure (ADS) is a single measurement of EGTS_SR_ACCEL_DATA subrecord.
+type AccelDataStructure struct {
+	// RelativeTime (RTM) - time offset from ATM, ms.
+	RelativeTime uint16 `json:"RTM"`
+	// XAxisAccelerationValue (XAAV) - linear acceleration along the X axis in increments of 0.1 m/s².
+	XAxisAccelerationValue int16 `json:"XAAV"`
+	// YAxisAccelerationValue (YAAV) - linear acceleration along the Y axis in increments of 0.1 m/s².
+	YAxisAccelerationValue int16 `json:"YAAV"`
+	// ZAxisAccelerationValue (ZAAV) - linear acceleration along the Z axis in increments of 0.1 m/s².
+	ZAxisAccelerationValue int16 `json:"ZAAV"`
+}
+
+// SrAccelData is the structure of EGTS_SR_ACCEL_DATA subrecord, which is used by the subscriber's
+// terminal to transmit the accelerometer data.
+type SrAccelData struct {
+	// StructuresAmount (SA) - number of the accelerometer data structures.
+	StructuresAmount uint8 `json:"SA"`
+	// AbsoluteTime (ATM) - time of the first measurement (number of seconds since 00:00:00 01.01.2010 UTC).
+	AbsoluteTime time.Time `json:"ATM"`
+	// AccelDataStructures (ADS) - accelerometer measurements.
+	AccelDataStructures []AccelDataStructure `json:"ADS"`
+}
+
+// Decode parses the set of bytes into EGTS_SR_ACCEL_DATA structure.
+func (e *SrAccelData) Decode(content []byte) error {
+	var (
+		err error
+		atm uint32
+	)
+	buf := bytes.NewReader(content)
+
+	if e.StructuresAmount, err = buf.ReadByte(); err != nil {
+		return fmt.Errorf("failed to get the amount of accelerometer data structures: %w", err)
+	}
+
+	if err = binary.Read(buf, binary.LittleEndian, &atm); err != nil {
+		return fmt.Errorf("failed to get the absolute time of accelerometer data: %w", err)
+	}
+	e.AbsoluteTime = timeOffset.Add(time.Duration(atm) * time.Second)
+
+	e.AccelDataStructures = make([]AccelDataStructure, e.StructuresAmount)
+	for i := range e.AccelDataStructures {
+		if err = binary.Read(buf, binary.LittleEndian, &e.AccelDataStructures[i]); err != nil {
+			return fmt.Errorf("failed to get accelerometer data structure %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// Encode encodes the EGTS_SR_ACCEL_DATA structure into the set of bytes.
+func (e *SrAccelData) Encode() ([]byte, error) {
+	var (
+		err    error
+		result []byte
+	)
+	buf := new(bytes.Buffer)
+
+	if err = buf.WriteByte(uint8(len(e.AccelDataStructures))); err != nil {
+		return result, fmt.Errorf("failed to write the amount of accelerometer data structures: %w", err)
+	}
+
+	atm := uint32(e.AbsoluteTime.Unix() - timeOffset.Unix())
+	if err = binary.Write(buf, binary.LittleEndian, atm); err != nil {
+		return result, fmt.Errorf("failed to write the absolute time of accelerometer data: %w", err)
+	}
+
+	for i, ads := range e.AccelDataStructures {
+		if err = binary.Write(buf, binary.LittleEndian, ads); err != nil {
+			return result, fmt.Errorf("failed to write accelerometer data structure %d: %w", i, err)
+		}
+	}
+
+	result = buf.Bytes()
+	return result, nil
+}
+
+// Length returns the length of the EGTS_SR_ACCEL_DATA structure.
+func (e *SrAccelData) Length() uint16 {
+	var result uint16
+
+	if recBytes, err := e.Encode(); err != nil {
+		result = uint16(0)
+	} else {
+		result = uint16(len(recBytes))
+	}
+
+	return result
+}
+
+// Samples converts the accelerometer data structures into samples in g.
+func (e *SrAccelData) Samples() []AccelSample {
+	const unit = 0.1 / standardGravity
+
+	result := make([]AccelSample, 0, len(e.AccelDataStructures))
+	for _, ads := range e.AccelDataStructures {
+		result = append(result, AccelSample{
+			Time: e.AbsoluteTime.Add(time.Duration(ads.RelativeTime) * time.Millisecond),
+			X:    float64(ads.XAxisAccelerationValue) * unit,
+			Y:    float64(ads.YAxisAccelerationValue) * unit,
+			Z:    float64(ads.ZAxisAccelerationValue) * unit,
+		})
+	}
+	return result
+}
+
+// Validate checks that the amount of the structures of EGTS_SR_ACCEL_DATA matches the measurements.
+func (e *SrAccelData) Validate() error {
+	var v violations
+
+	v.maxLen("ADS", len(e.AccelDataStructures), 255)
+	if e.StructuresAmount != 0 && int(e.StructuresAmount) != len(e.AccelDataStructures) {
+		v.add("SA", e.StructuresAmount, fmt.Sprintf("does not match %d structures", len(e.AccelDataStructures)))
+	}
+	if e.AbsoluteTime.Before(timeOffset) {
+		v.add("ATM", e.AbsoluteTime, "must not be before 2010-01-01")
+	}
+	return v.err()
+}
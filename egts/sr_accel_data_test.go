@@ -0,0 +1,70 @@
+package egts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testEgtsSrAccelData = SrAccelData{
+		StructuresAmount: 2,
+		AbsoluteTime:     time.Date(2018, time.July, 6, 20, 8, 53, 0, time.UTC),
+		AccelDataStructures: []AccelDataStructure{
+			{RelativeTime: 0, XAxisAccelerationValue: 98, YAxisAccelerationValue: -49, ZAxisAccelerationValue: 0},
+			{RelativeTime: 250, XAxisAccelerationValue: 0, YAxisAccelerationValue: 10, ZAxisAccelerationValue: -98},
+		},
+	}
+	testSrAccelDataBytes = []byte{0x02, 0x55, 0x91, 0x02, 0x10,
+		0x00, 0x00, 0x62, 0x00, 0xCF, 0xFF, 0x00, 0x00,
+		0xFA, 0x00, 0x00, 0x00, 0x0A, 0x00, 0x9E, 0xFF}
+)
+
+func TestEgtsSrAccelData_Encode(t *testing.T) {
+	accelBytes, err := testEgtsSrAccelData.Encode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, accelBytes, testSrAccelDataBytes)
+	}
+}
+
+func TestEgtsSrAccelData_Decode(t *testing.T) {
+	accelData := SrAccelData{}
+
+	if assert.NoError(t, accelData.Decode(testSrAccelDataBytes)) {
+		assert.Equal(t, accelData, testEgtsSrAccelData)
+	}
+}
+
+// проверяем что рекордсет работает правильно с данным типом подзаписи
+func TestEgtsSrAccelDataRs(t *testing.T) {
+	accelDataRDBytes := append([]byte{0x14, 0x15, 0x00}, testSrAccelDataBytes...)
+	accelDataRD := RecordDataSet{
+		RecordData{
+			SubrecordType:   SrType20,
+			SubrecordLength: testEgtsSrAccelData.Length(),
+			SubrecordData:   &testEgtsSrAccelData,
+		},
+	}
+	testStruct := RecordDataSet{}
+
+	testBytes, err := accelDataRD.Encode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, testBytes, accelDataRDBytes)
+
+		if assert.NoError(t, testStruct.Decode(accelDataRDBytes)) {
+			assert.Equal(t, accelDataRD, testStruct)
+		}
+	}
+}
+
+func TestEgtsSrAccelData_Samples(t *testing.T) {
+	samples := testEgtsSrAccelData.Samples()
+	if assert.Len(t, samples, 2) {
+		assert.Equal(t, testEgtsSrAccelData.AbsoluteTime, samples[0].Time)
+		assert.Equal(t, testEgtsSrAccelData.AbsoluteTime.Add(250*time.Millisecond), samples[1].Time)
+		assert.InDelta(t, 0.9993, samples[0].X, 1e-4)
+		assert.InDelta(t, -0.4997, samples[0].Y, 1e-4)
+		assert.InDelta(t, -0.9993, samples[1].Z, 1e-4)
+	}
+}
@@ -59,7 +59,7 @@ func (e *SrAdSensorsData) Decode(content []byte) error {
 	if flags, err = buf.ReadByte(); err != nil {
 		return fmt.Errorf("failed to get ad_sesor_data digital output bytea: %w", err)
 	}
-	flagBits := fmt.Sprintf("%08b", flags)
+	flagBits := bitString(flags)
 
 	e.DigitalInputsOctetExists8 = flagBits[:1]
 	e.DigitalInputsOctetExists7 = flagBits[1:2]
@@ -77,7 +77,7 @@ func (e *SrAdSensorsData) Decode(content []byte) error {
 	if flags, err = buf.ReadByte(); err != nil {
 		return fmt.Errorf("failed to get byte of analog outputs ad_sesor_data: %w", err)
 	}
-	flagBits = fmt.Sprintf("%08b", flags)
+	flagBits = bitString(flags)
 
 	e.AnalogSensorFieldExists8 = flagBits[:1]
 	e.AnalogSensorFieldExists7 = flagBits[1:2]
@@ -371,3 +371,30 @@ func (e *SrAdSensorsData) Length() uint16 {
 
 	return result
 }
+
+// Validate checks the flags of EGTS_SR_AD_SENSORS_DATA and the range of the analog sensors.
+func (e *SrAdSensorsData) Validate() error {
+	var v violations
+
+	for i, f := range []string{
+		e.DigitalInputsOctetExists1, e.DigitalInputsOctetExists2, e.DigitalInputsOctetExists3,
+		e.DigitalInputsOctetExists4, e.DigitalInputsOctetExists5, e.DigitalInputsOctetExists6,
+		e.DigitalInputsOctetExists7, e.DigitalInputsOctetExists8,
+	} {
+		v.flag(fmt.Sprintf("DIOE%d", i+1), f, 1)
+	}
+	for i, f := range []string{
+		e.AnalogSensorFieldExists1, e.AnalogSensorFieldExists2, e.AnalogSensorFieldExists3,
+		e.AnalogSensorFieldExists4, e.AnalogSensorFieldExists5, e.AnalogSensorFieldExists6,
+		e.AnalogSensorFieldExists7, e.AnalogSensorFieldExists8,
+	} {
+		v.flag(fmt.Sprintf("ASFE%d", i+1), f, 1)
+	}
+	for i, ans := range []uint32{
+		e.AnalogSensor1, e.AnalogSensor2, e.AnalogSensor3, e.AnalogSensor4,
+		e.AnalogSensor5, e.AnalogSensor6, e.AnalogSensor7, e.AnalogSensor8,
+	} {
+		v.max(fmt.Sprintf("ANS%d", i+1), uint64(ans), 1<<24-1)
+	}
+	return v.err()
+}
@@ -84,3 +84,13 @@ func (e *SrAuthInfo) Length() uint16 {
 
 	return result
 }
+
+// Validate checks the length of the strings of EGTS_SR_AUTH_INFO.
+func (e *SrAuthInfo) Validate() error {
+	var v violations
+
+	v.maxLen("UNM", len(e.UserName), 32)
+	v.maxLen("UPSW", len(e.UserPassword), 32)
+	v.maxLen("SS", len(e.ServerSequence), 255)
+	return v.err()
+}
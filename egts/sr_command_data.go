@@ -0,0 +1,305 @@
+package egts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Command types (CT field of EGTS_SR_COMMAND_DATA).
+const (
+	CtComConf uint8 = 0b0001 // CtComConf is the confirmation of the command execution.
+	CtMsgConf uint8 = 0b0010 // CtMsgConf is the confirmation of the message delivery.
+	CtMsgFrom uint8 = 0b0011 // CtMsgFrom is the information message from the terminal.
+	CtMsgTo   uint8 = 0b0100 // CtMsgTo is the information message to the terminal.
+	CtCom     uint8 = 0b0101 // CtCom is the command to execute on the terminal.
+	CtDelCom  uint8 = 0b0110 // CtDelCom is the removal of the command from the queue.
+	CtSubReq  uint8 = 0b0111 // CtSubReq is the additional subrequest for the command.
+	CtDeliv   uint8 = 0b1000 // CtDeliv is the confirmation of the command or message delivery.
+)
+
+// Command confirmation types (CCT field of EGTS_SR_COMMAND_DATA).
+const (
+	CcOk     uint8 = 0b0000 // CcOk means successful execution.
+	CcError  uint8 = 0b0001 // CcError means execution failure.
+	CcIll    uint8 = 0b0010 // CcIll means the command is not allowed.
+	CcDel    uint8 = 0b0011 // CcDel means the command is removed from the queue.
+	CcNFound uint8 = 0b0100 // CcNFound means the command to remove is not found.
+	CcNConf  uint8 = 0b0101 // CcNConf means the command is not confirmed.
+	CcInProg uint8 = 0b0110 // CcInProg means the command is being processed.
+)
+
+// Actions of the command (ACT field of the command data).
+const (
+	ActParams uint8 = 0 // ActParams is the command with parameters in DT.
+	ActGet    uint8 = 1 // ActGet requests the value of the parameter CCD.
+	ActSet    uint8 = 2 // ActSet sets the value of the parameter CCD to DT.
+	ActAdd    uint8 = 3 // ActAdd adds the new parameter CCD of SZ bytes.
+	ActDelete uint8 = 4 // ActDelete removes the parameter CCD.
+)
+
+// Charsets of the command data (CHS field of EGTS_SR_COMMAND_DATA).
+const (
+	CharsetCP1251 uint8 = 0 // CharsetCP1251 is CP-1251.
+	CharsetIA5    uint8 = 1 // CharsetIA5 is IA5 (CCITT T.50)/ASCII (ANSI X3.4).
+	CharsetBinary uint8 = 2 // CharsetBinary is binary data.
+	CharsetLatin1 uint8 = 3 // CharsetLatin1 is Latin 1 (ISO-8859-1).
+	CharsetUCS2   uint8 = 8 // CharsetUCS2 is UCS2 (ISO/IEC-10646).
+)
+
+// CommandDetails is the command data (CD) of CT_COM and CT_COMCONF commands.
+// The confirmation carries no SZ and ACT fields.
+type CommandDetails struct {
+	// Address (ADR) - address of the terminal module the command is intended for.
+	Address uint16 `json:"ADR"`
+	// Size (SZ) - size of the new parameter value for ActAdd action.
+	Size uint8 `json:"SZ"`
+	// Action (ACT) - action of the command.
+	Action uint8 `json:"ACT"`
+	// Code (CCD) - command or parameter code.
+	Code uint16 `json:"CCD"`
+	// Data (DT) - command parameters or parameter value.
+	Data []byte `json:"DT"`
+}
+
+// SrCommandData is the structure of EGTS_SR_COMMAND_DATA subrecord of COMMANDS_SERVICE, which is used
+// to transmit commands, information messages and their confirmations.
+type SrCommandData struct {
+	// CommandType (CT) - type of the command.
+	CommandType uint8 `json:"CT"`
+	// CommandConfirmationType (CCT) - type of the confirmation.
+	CommandConfirmationType uint8 `json:"CCT"`
+	// CommandIdentifier (CID) - command identifier assigned by the sender.
+	CommandIdentifier uint32 `json:"CID"`
+	// SourceIdentifier (SID) - identifier of the sender.
+	SourceIdentifier uint32 `json:"SID"`
+	// ACFE - authorization code field exists, bit 1 of the flags.
+	ACFE string `json:"ACFE"`
+	// CHSFE - charset field exists, bit 0 of the flags.
+	CHSFE string `json:"CHSFE"`
+	// Charset (CHS) - charset of the command data.
+	Charset uint8 `json:"CHS"`
+	// AuthorizationCodeLength (ACL) - length of the authorization code.
+	AuthorizationCodeLength uint8 `json:"ACL"`
+	// AuthorizationCode (AC) - authorization code of the terminal.
+	AuthorizationCode []byte `json:"AC"`
+	// Command is the command data of CT_COM and CT_COMCONF types.
+	Command *CommandDetails `json:"CD,omitempty"`
+	// Message is the command data of the other types.
+	Message []byte `json:"MSG,omitempty"`
+}
+
+// hasCommandDetails reports whether the command data is CommandDetails structure.
+func (e *SrCommandData) hasCommandDetails() bool {
+	return e.CommandType == CtCom || e.CommandType == CtComConf
+}
+
+// Decode parses the set of bytes into EGTS_SR_COMMAND_DATA structure.
+func (e *SrCommandData) Decode(content []byte) error {
+	var (
+		err   error
+		flags byte
+	)
+	buf := bytes.NewReader(content)
+
+	if flags, err = buf.ReadByte(); err != nil {
+		return fmt.Errorf("failed to get command type: %w", err)
+	}
+	e.CommandType = flags >> 4
+	e.CommandConfirmationType = flags & 0x0F
+
+	if err = binary.Read(buf, binary.LittleEndian, &e.CommandIdentifier); err != nil {
+		return fmt.Errorf("failed to get command identifier: %w", err)
+	}
+	if err = binary.Read(buf, binary.LittleEndian, &e.SourceIdentifier); err != nil {
+		return fmt.Errorf("failed to get command source identifier: %w", err)
+	}
+
+	if flags, err = buf.ReadByte(); err != nil {
+		return fmt.Errorf("failed to get command flags: %w", err)
+	}
+	flagBits := bitString(flags)
+	e.ACFE = flagBits[6:7] // flags << 1
+	e.CHSFE = flagBits[7:] // flags << 0
+
+	if e.CHSFE == "1" {
+		if e.Charset, err = buf.ReadByte(); err != nil {
+			return fmt.Errorf("failed to get command charset: %w", err)
+		}
+	}
+
+	if e.ACFE == "1" {
+		if e.AuthorizationCodeLength, err = buf.ReadByte(); err != nil {
+			return fmt.Errorf("failed to get authorization code length: %w", err)
+		}
+		e.AuthorizationCode = make([]byte, e.AuthorizationCodeLength)
+		if _, err = io.ReadFull(buf, e.AuthorizationCode); err != nil {
+			return fmt.Errorf("failed to get authorization code: %w", err)
+		}
+	}
+
+	data := make([]byte, buf.Len())
+	if _, err = io.ReadFull(buf, data); err != nil {
+		return fmt.Errorf("failed to get command data: %w", err)
+	}
+	if !e.hasCommandDetails() {
+		e.Message = data
+		return nil
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	e.Command = &CommandDetails{}
+	if err = e.Command.decode(data, e.CommandType == CtCom); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Encode returns the set of bytes of the EGTS_SR_COMMAND_DATA structure.
+func (e *SrCommandData) Encode() ([]byte, error) {
+	var (
+		result []byte
+		flags  uint64
+		err    error
+	)
+	buf := new(bytes.Buffer)
+
+	if err = buf.WriteByte(e.CommandType<<4 | e.CommandConfirmationType&0x0F); err != nil {
+		return result, fmt.Errorf("failed to write command type: %w", err)
+	}
+	if err = binary.Write(buf, binary.LittleEndian, e.CommandIdentifier); err != nil {
+		return result, fmt.Errorf("failed to write command identifier: %w", err)
+	}
+	if err = binary.Write(buf, binary.LittleEndian, e.SourceIdentifier); err != nil {
+		return result, fmt.Errorf("failed to write command source identifier: %w", err)
+	}
+
+	flags, err = strconv.ParseUint("000000"+e.ACFE+e.CHSFE, 2, 8)
+	if err != nil {
+		return result, fmt.Errorf("failed to convert command flags to a number: %w", err)
+	}
+	if err = buf.WriteByte(uint8(flags)); err != nil {
+		return result, fmt.Errorf("failed to write command flags: %w", err)
+	}
+
+	if e.CHSFE == "1" {
+		if err = buf.WriteByte(e.Charset); err != nil {
+			return result, fmt.Errorf("failed to write command charset: %w", err)
+		}
+	}
+
+	if e.ACFE == "1" {
+		if err = buf.WriteByte(uint8(len(e.AuthorizationCode))); err != nil {
+			return result, fmt.Errorf("failed to write authorization code length: %w", err)
+		}
+		if _, err = buf.Write(e.AuthorizationCode); err != nil {
+			return result, fmt.Errorf("failed to write authorization code: %w", err)
+		}
+	}
+
+	if e.hasCommandDetails() {
+		if e.Command != nil {
+			if err = e.Command.encode(buf, e.CommandType == CtCom); err != nil {
+				return result, err
+			}
+		}
+	} else if _, err = buf.Write(e.Message); err != nil {
+		return result, fmt.Errorf("failed to write command message: %w", err)
+	}
+
+	result = buf.Bytes()
+	return result, nil
+}
+
+// Length returns the length of the EGTS_SR_COMMAND_DATA structure.
+func (e *SrCommandData) Length() uint16 {
+	var result uint16
+
+	if recBytes, err := e.Encode(); err != nil {
+		result = uint16(0)
+	} else {
+		result = uint16(len(recBytes))
+	}
+
+	return result
+}
+
+// decode parses the command data, withAction is set for CT_COM commands having SZ and ACT fields.
+func (c *CommandDetails) decode(content []byte, withAction bool) error {
+	var (
+		err   error
+		flags byte
+	)
+	buf := bytes.NewReader(content)
+
+	if err = binary.Read(buf, binary.LittleEndian, &c.Address); err != nil {
+		return fmt.Errorf("failed to get command module address: %w", err)
+	}
+
+	if withAction {
+		if flags, err = buf.ReadByte(); err != nil {
+			return fmt.Errorf("failed to get command action: %w", err)
+		}
+		c.Size = flags >> 4
+		c.Action = flags & 0x0F
+	}
+
+	if err = binary.Read(buf, binary.LittleEndian, &c.Code); err != nil {
+		return fmt.Errorf("failed to get command code: %w", err)
+	}
+
+	c.Data = make([]byte, buf.Len())
+	if _, err = io.ReadFull(buf, c.Data); err != nil {
+		return fmt.Errorf("failed to get command data: %w", err)
+	}
+	return nil
+}
+
+// encode writes the command data, withAction is set for CT_COM commands having SZ and ACT fields.
+func (c *CommandDetails) encode(buf *bytes.Buffer, withAction bool) error {
+	var err error
+
+	if err = binary.Write(buf, binary.LittleEndian, c.Address); err != nil {
+		return fmt.Errorf("failed to write command module address: %w", err)
+	}
+	if withAction {
+		if err = buf.WriteByte(c.Size<<4 | c.Action&0x0F); err != nil {
+			return fmt.Errorf("failed to write command action: %w", err)
+		}
+	}
+	if err = binary.Write(buf, binary.LittleEndian, c.Code); err != nil {
+		return fmt.Errorf("failed to write command code: %w", err)
+	}
+	if _, err = buf.Write(c.Data); err != nil {
+		return fmt.Errorf("failed to write command data: %w", err)
+	}
+	return nil
+}
+
+// Validate checks the types, the flags and the authorization code of EGTS_SR_COMMAND_DATA.
+func (e *SrCommandData) Validate() error {
+	var v violations
+
+	if e.CommandType < CtComConf || e.CommandType > CtDeliv {
+		v.add("CT", e.CommandType, "is unknown")
+	}
+	v.max("CCT", uint64(e.CommandConfirmationType), uint64(CcInProg))
+	v.flag("ACFE", e.ACFE, 1)
+	v.flag("CHSFE", e.CHSFE, 1)
+	if e.ACFE == "1" {
+		v.maxLen("AC", len(e.AuthorizationCode), 255)
+		if e.AuthorizationCodeLength != 0 && int(e.AuthorizationCodeLength) != len(e.AuthorizationCode) {
+			v.add("ACL", e.AuthorizationCodeLength, fmt.Sprintf("does not match AC length %d",
+				len(e.AuthorizationCode)))
+		}
+	}
+	if e.Command != nil {
+		v.max("SZ", uint64(e.Command.Size), 0x0F)
+		v.max("ACT", uint64(e.Command.Action), uint64(ActDelete))
+	}
+	return v.err()
+}
@@ -0,0 +1,101 @@
+package egts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testEgtsSrCommandData = SrCommandData{
+		CommandType:             CtCom,
+		CommandConfirmationType: CcOk,
+		CommandIdentifier:       1,
+		SourceIdentifier:        0,
+		ACFE:                    "1",
+		CHSFE:                   "0",
+		AuthorizationCodeLength: 2,
+		AuthorizationCode:       []byte{0x31, 0x32},
+		Command: &CommandDetails{
+			Address: 0,
+			Action:  ActGet,
+			Code:    ParamGprsAPN,
+			Data:    []byte{},
+		},
+	}
+	testSrCommandDataBytes = []byte{0x50, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x02, 0x31, 0x32,
+		0x00, 0x00, 0x01, 0x03, 0x02}
+
+	testEgtsSrCommandConf = SrCommandData{
+		CommandType:             CtComConf,
+		CommandConfirmationType: CcOk,
+		CommandIdentifier:       1,
+		SourceIdentifier:        0,
+		ACFE:                    "0",
+		CHSFE:                   "1",
+		Charset:                 CharsetIA5,
+		Command: &CommandDetails{
+			Address: 0,
+			Code:    ParamGprsAPN,
+			Data:    []byte("internet"),
+		},
+	}
+	testSrCommandConfBytes = append([]byte{0x10, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x01,
+		0x00, 0x00, 0x03, 0x02}, []byte("internet")...)
+)
+
+func TestEgtsSrCommandData_Encode(t *testing.T) {
+	cmdBytes, err := testEgtsSrCommandData.Encode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, testSrCommandDataBytes, cmdBytes)
+	}
+
+	confBytes, err := testEgtsSrCommandConf.Encode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, testSrCommandConfBytes, confBytes)
+	}
+}
+
+func TestEgtsSrCommandData_Decode(t *testing.T) {
+	cmd := SrCommandData{}
+	if assert.NoError(t, cmd.Decode(testSrCommandDataBytes)) {
+		assert.Equal(t, testEgtsSrCommandData, cmd)
+	}
+
+	conf := SrCommandData{}
+	if assert.NoError(t, conf.Decode(testSrCommandConfBytes)) {
+		assert.Equal(t, testEgtsSrCommandConf, conf)
+	}
+}
+
+func TestEgtsSrCommandData_DecodeMessage(t *testing.T) {
+	msg := SrCommandData{}
+	content := append([]byte{0x30, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, []byte("hello")...)
+	if assert.NoError(t, msg.Decode(content)) {
+		assert.Equal(t, CtMsgFrom, msg.CommandType)
+		assert.Nil(t, msg.Command)
+		assert.Equal(t, []byte("hello"), msg.Message)
+	}
+}
+
+// проверяем что рекордсет работает правильно с данным типом подзаписи
+func TestEgtsSrCommandDataRs(t *testing.T) {
+	commandDataRDBytes := append([]byte{0x33, 0x12, 0x00}, testSrCommandDataBytes...)
+	commandDataRD := RecordDataSet{
+		RecordData{
+			SubrecordType:   SrCommandDataType,
+			SubrecordLength: 18,
+			SubrecordData:   &testEgtsSrCommandData,
+		},
+	}
+	testStruct := RecordDataSet{}
+
+	testBytes, err := commandDataRD.Encode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, commandDataRDBytes, testBytes)
+
+		if assert.NoError(t, testStruct.Decode(commandDataRDBytes)) {
+			assert.Equal(t, commandDataRD, testStruct)
+		}
+	}
+}
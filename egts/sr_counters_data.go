@@ -40,7 +40,7 @@ func (c *SrCountersData) Decode(content []byte) error {
 	if flags, err = buf.ReadByte(); err != nil {
 		return fmt.Errorf("failed to get a byte of digital outputs sr_counters_data: %w", err)
 	}
-	flagBits := fmt.Sprintf("%08b", flags)
+	flagBits := bitString(flags)
 
 	c.CounterFieldExists8 = flagBits[:1]
 	c.CounterFieldExists7 = flagBits[1:2]
@@ -216,3 +216,21 @@ func (c *SrCountersData) Length() uint16 {
 
 	return result
 }
+
+// Validate checks the flags of EGTS_SR_COUNTERS_DATA and the range of the counters.
+func (c *SrCountersData) Validate() error {
+	var v violations
+
+	for i, f := range []string{
+		c.CounterFieldExists1, c.CounterFieldExists2, c.CounterFieldExists3, c.CounterFieldExists4,
+		c.CounterFieldExists5, c.CounterFieldExists6, c.CounterFieldExists7, c.CounterFieldExists8,
+	} {
+		v.flag(fmt.Sprintf("CFE%d", i+1), f, 1)
+	}
+	for i, cn := range []uint32{
+		c.Counter1, c.Counter2, c.Counter3, c.Counter4, c.Counter5, c.Counter6, c.Counter7, c.Counter8,
+	} {
+		v.max(fmt.Sprintf("CN%d", i+1), uint64(cn), 1<<24-1)
+	}
+	return v.err()
+}
@@ -72,3 +72,11 @@ func (d *SrDispatcherIdentity) Length() uint16 {
 
 	return result
 }
+
+// Validate checks the length of the description of EGTS_SR_DISPATCHER_IDENTITY.
+func (d *SrDispatcherIdentity) Validate() error {
+	var v violations
+
+	v.maxLen("DSCR", len(d.Description), 255)
+	return v.err()
+}
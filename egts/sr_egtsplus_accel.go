@@ -0,0 +1,116 @@
+package egts
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Sample formats of EGTS+ accelerometer data (format field of sens_accelerometer_data).
+const (
+	AccelFormatInt8    uint32 = 0
+	AccelFormatInt16   uint32 = 1
+	AccelFormatInt32   uint32 = 2
+	AccelFormatASN1PER uint32 = 3
+)
+
+// maxInflatedSize limits the size of the unpacked EGTS+ buffers.
+const maxInflatedSize = 1 << 20
+
+var (
+	// ErrAccelFormat is returned when the accelerometer samples format is not supported.
+	ErrAccelFormat = errors.New("unsupported accelerometer data format")
+	// ErrInflatedTooLarge is returned when the unpacked buffer exceeds the size limit.
+	ErrInflatedTooLarge = errors.New("unpacked data is too large")
+)
+
+// Samples unpacks the accelerometer buffer into samples in g. Every sample is a triple of signed
+// little-endian X, Y, Z values, scaled by the measurement range and timed by the sampling frequency.
+func (m *SensAccelerometerData) Samples() ([]AccelSample, error) {
+	buf := m.GetBuf()
+	if m.GetZlib() != 0 {
+		var err error
+		if buf, err = inflate(buf); err != nil {
+			return nil, fmt.Errorf("failed to unpack accelerometer data: %w", err)
+		}
+	}
+
+	var size int
+	switch m.GetFormat() {
+	case AccelFormatInt8:
+		size = 1
+	case AccelFormatInt16:
+		size = 2
+	case AccelFormatInt32:
+		size = 4
+	default:
+		return nil, fmt.Errorf("format %d: %w", m.GetFormat(), ErrAccelFormat)
+	}
+
+	// full scale of the format corresponds to the measurement range (stored multiplied by 10)
+	scale := float64(m.GetRange()) / 10 / float64(uint64(1)<<(8*size-1))
+	var period time.Duration
+	if m.GetFrequency() != 0 {
+		period = time.Second / time.Duration(m.GetFrequency()*10)
+	}
+	start := time.Unix(int64(m.GetAtm()), 0).UTC()
+
+	n := len(buf) / (3 * size)
+	result := make([]AccelSample, 0, n)
+	for i := 0; i < n; i++ {
+		sample := buf[i*3*size:]
+		result = append(result, AccelSample{
+			Time: start.Add(time.Duration(i) * period),
+			X:    float64(signedLE(sample[:size])) * scale,
+			Y:    float64(signedLE(sample[size:2*size])) * scale,
+			Z:    float64(signedLE(sample[2*size:3*size])) * scale,
+		})
+	}
+	return result, nil
+}
+
+// Unpack returns the buffered data, inflating it if it is packed.
+func (m *SensBufferData) Unpack() ([]byte, error) {
+	if !m.GetIsPacked() {
+		return m.GetData(), nil
+	}
+	data, err := inflate(m.GetData())
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack buffer data: %w", err)
+	}
+	return data, nil
+}
+
+// signedLE reads the signed little-endian integer of 1, 2 or 4 bytes.
+func signedLE(b []byte) int32 {
+	switch len(b) {
+	case 1:
+		return int32(int8(b[0]))
+	case 2:
+		return int32(int16(binary.LittleEndian.Uint16(b)))
+	default:
+		return int32(binary.LittleEndian.Uint32(b))
+	}
+}
+
+// inflate unpacks zlib data.
+func inflate(data []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zlib stream: %w", err)
+	}
+	defer r.Close()
+
+	result, err := io.ReadAll(io.LimitReader(r, maxInflatedSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to inflate zlib stream: %w", err)
+	}
+	if len(result) > maxInflatedSize {
+		return nil, ErrInflatedTooLarge
+	}
+	return result, nil
+}
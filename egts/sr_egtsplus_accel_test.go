@@ -0,0 +1,107 @@
+package egts
+
+import (
+	"bytes"
+	"compress/zlib"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func deflate(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestSensAccelerometerData_Samples(t *testing.T) {
+	start := time.Date(2019, time.February, 9, 21, 29, 31, 0, time.UTC)
+	int16Buf := []byte{0x00, 0x40, 0x00, 0xC0, 0x00, 0x00, 0xFF, 0x7F, 0x00, 0x00, 0x00, 0x80}
+
+	tests := []struct {
+		name    string
+		data    SensAccelerometerData
+		want    []AccelSample
+		wantErr error
+	}{
+		{
+			name: "int8 ±2g 100Hz",
+			data: SensAccelerometerData{
+				Buf: []byte{0x40, 0xC0, 0x00, 0x00, 0x20, 0x80}, Atm: proto.Uint32(uint32(start.Unix())),
+				Frequency: proto.Uint32(10), Range: proto.Uint32(20), Format: proto.Uint32(AccelFormatInt8),
+			},
+			want: []AccelSample{
+				{Time: start, X: 1, Y: -1, Z: 0},
+				{Time: start.Add(10 * time.Millisecond), X: 0, Y: 0.5, Z: -2},
+			},
+		},
+		{
+			name: "int16 ±4g zlib",
+			data: SensAccelerometerData{
+				Buf: deflate(t, int16Buf), Atm: proto.Uint32(uint32(start.Unix())), Zlib: proto.Uint32(1),
+				Frequency: proto.Uint32(5), Range: proto.Uint32(40), Format: proto.Uint32(AccelFormatInt16),
+			},
+			want: []AccelSample{
+				{Time: start, X: 2, Y: -2, Z: 0},
+				{Time: start.Add(20 * time.Millisecond), X: 4 * 32767.0 / 32768, Y: 0, Z: -4},
+			},
+		},
+		{
+			name: "int32",
+			data: SensAccelerometerData{
+				Buf:   []byte{0, 0, 0, 0x40, 0, 0, 0, 0, 0, 0, 0, 0xC0},
+				Range: proto.Uint32(80), Format: proto.Uint32(AccelFormatInt32),
+			},
+			want: []AccelSample{{Time: time.Unix(0, 0).UTC(), X: 4, Y: 0, Z: -4}},
+		},
+		{
+			name:    "asn1 per",
+			data:    SensAccelerometerData{Format: proto.Uint32(AccelFormatASN1PER)},
+			wantErr: ErrAccelFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.data.Samples()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSensBufferData_Unpack(t *testing.T) {
+	payload := []byte("$GPRMC,212931.00,A,5544.5414,N,03739.2914,E,0.0,0.0,090219,,,A*6C")
+
+	plain := SensBufferData{Data: payload}
+	got, err := plain.Unpack()
+	if assert.NoError(t, err) {
+		assert.Equal(t, payload, got)
+	}
+
+	packed := SensBufferData{Data: deflate(t, payload), IsPacked: proto.Bool(true)}
+	got, err = packed.Unpack()
+	if assert.NoError(t, err) {
+		assert.Equal(t, payload, got)
+	}
+
+	broken := SensBufferData{Data: payload, IsPacked: proto.Bool(true)}
+	_, err = broken.Unpack()
+	assert.Error(t, err)
+
+	bomb := SensBufferData{Data: deflate(t, make([]byte, maxInflatedSize+1)), IsPacked: proto.Bool(true)}
+	_, err = bomb.Unpack()
+	assert.ErrorIs(t, err, ErrInflatedTooLarge)
+}
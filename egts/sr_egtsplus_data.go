@@ -1520,3 +1520,13 @@ var fileDescriptor0 = []byte{
 	0x56, 0x4d, 0x22, 0xa9, 0xba, 0x1a, 0xf9, 0x5f, 0x00, 0x00, 0x00, 0xff, 0xff, 0xad, 0x0e, 0xcd,
 	0xc4, 0x0c, 0x15, 0x00, 0x00,
 }
+
+// Validate checks the required fields of the storage record.
+func (m *StorageRecord) Validate() error {
+	var v violations
+
+	v.required("record_number", m.RecordNumber != nil)
+	v.required("time_stamp", m.TimeStamp != nil)
+	v.required("status_flags", m.StatusFlags != nil)
+	return v.err()
+}
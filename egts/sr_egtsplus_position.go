@@ -0,0 +1,195 @@
+package egts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotrackery/protocol/common"
+	"github.com/peterstace/simplefeatures/geom"
+	"gopkg.in/guregu/null.v4"
+)
+
+// Attributes of EGTS+ CANLog data.
+const (
+	AttrCanSpeed         = "can_speed"
+	AttrCanOdometer      = "can_odometer"
+	AttrCanFuelLevel     = "can_fuel_level"
+	AttrCanFuelLevelPct  = "can_fuel_level_pct"
+	AttrCanSecurityFlags = "can_security_flags"
+	AttrCanAlarmFlags    = "can_alarm_flags"
+	// AttrDigInputExt is the state of external digital inputs.
+	AttrDigInputExt = "dinput_ext"
+)
+
+// msdAngleUnit is the unit of coordinates in ERA-GLONASS minimum set of data (MSD) format, milliarcseconds.
+const msdAngleUnit = 3600000.0
+
+// canFuelLevelPercent is the bit of sens_can_log_data fuel_level marking the value in percent.
+const canFuelLevelPercent = 1 << 15
+
+// Position converts the EGTS+ storage record into position. Navigation data becomes location fields,
+// sensors become attributes and record reasons become named events.
+// Fuel level sensors are converted into litres with Options.Fuel calibrations.
+func (m *StorageRecord) Position(opt ...func(*Options)) common.Position {
+	options := &Options{}
+	for _, o := range opt {
+		o(options)
+	}
+
+	pos := common.Position{
+		Protocol:   ProtocolName,
+		DeviceTime: time.Unix(int64(m.GetTimeStamp()), 0).UTC(),
+		Attributes: common.Attributes{},
+	}
+
+	m.applyReasons(pos.Attributes)
+	if nav := m.GetSensNdNavData(); len(nav) > 0 {
+		nav[0].applyTo(&pos)
+	}
+	for _, can := range m.GetSensCanLogData() {
+		can.applyTo(pos.Attributes)
+	}
+	for _, termo := range m.GetSensTermoData() {
+		if termo.GetStatus() == 0 {
+			pos.Attributes[fmt.Sprintf("%s_%d", common.Temperature, termo.GetSensNum())] = int64(termo.GetTemperature())
+		}
+	}
+	for _, fuel := range m.GetSensFuelLevel() {
+		if litres, err := fuel.Litres(options.Fuel); err == nil {
+			addFuelLevel(pos.Attributes, fuel.GetSensNum(), litres)
+		}
+	}
+	for _, dins := range m.GetSensDinsFlags() {
+		if dins.Device != nil {
+			pos.Attributes[common.DigInput] = int64(dins.GetDevice())
+		}
+		if dins.External != nil {
+			pos.Attributes[AttrDigInputExt] = int64(dins.GetExternal())
+		}
+	}
+	for _, cnt := range m.GetSensCounterCount() {
+		pos.Attributes[fmt.Sprintf("%s_%d", common.Counter, cnt.GetSensNum())] = int64(cnt.GetValue())
+	}
+	if cells := m.GetSensGsmCellMonotoringCellMonitoring(); len(cells) > 0 {
+		pos.Cellular = cells[0].cellular()
+	}
+	return pos
+}
+
+// applyReasons maps the record reasons to the event attributes.
+func (m *StorageRecord) applyReasons(attrs common.Attributes) {
+	reasons := m.GetRecordReason()
+	if len(reasons) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(reasons))
+	for _, r := range reasons {
+		names = append(names, strings.ToLower(r.String()))
+		switch r { //nolint:exhaustive
+		case StorageRecord_IGNITION_ON:
+			attrs[common.Ignition] = int64(1)
+		case StorageRecord_IGNITION_OFF:
+			attrs[common.Ignition] = int64(0)
+		case StorageRecord_SOS_BUTTON, StorageRecord_ACCIDENT, StorageRecord_OVERTHROW:
+			attrs[common.Alarm] = int64(1)
+		}
+	}
+	attrs[common.Event] = strings.Join(names, ",")
+}
+
+// applyTo fills the location fields of the position.
+func (m *SensNdNavData) applyTo(pos *common.Position) {
+	if m.Longitude != nil && m.Latitude != nil {
+		pos.Location = common.Location{
+			Coordinates: geom.Coordinates{
+				XY: geom.XY{
+					X: float64(m.GetLongitude()) / msdAngleUnit,
+					Y: float64(m.GetLatitude()) / msdAngleUnit,
+				},
+				Type: geom.DimXY,
+			},
+			Valid: true,
+		}
+		if m.Altitude != nil {
+			pos.Type = geom.DimXYZ
+			pos.Z = float64(m.GetAltitude())
+		}
+	}
+	if m.Speed != nil {
+		pos.Speed = null.FloatFrom(float64(m.GetSpeed()))
+	}
+	if m.Course != nil {
+		pos.Course = null.FloatFrom(float64(m.GetCourse()))
+	}
+	if m.SatCount != nil {
+		pos.Attributes[common.Satellites] = int64(m.GetSatCount())
+	}
+	if m.Pdop != nil {
+		pos.Attributes[common.PDOP] = float64(m.GetPdop())
+	}
+	if m.Odometer != nil {
+		pos.Attributes[common.Odometer] = float64(m.GetOdometer()) / 1000
+	}
+}
+
+// applyTo adds CANLog data to the attributes.
+func (m *SensCanLogData) applyTo(attrs common.Attributes) {
+	if m.EngineTimeAll != nil {
+		attrs[common.EngineHours] = float64(m.GetEngineTimeAll()) / 100
+	}
+	if m.EngineTurnSpeed != nil {
+		attrs[common.RPM] = int64(m.GetEngineTurnSpeed())
+	}
+	if m.EngineTemperature != nil {
+		attrs[common.EngineTemperature] = int64(m.GetEngineTemperature())
+	}
+	if m.FuelConsumptionAll != nil {
+		attrs[common.FuelUsed] = float64(m.GetFuelConsumptionAll())
+	}
+	if m.FuelLevel != nil {
+		level := m.GetFuelLevel()
+		if level&canFuelLevelPercent != 0 {
+			attrs[AttrCanFuelLevelPct] = float64(level &^ canFuelLevelPercent)
+		} else {
+			attrs[AttrCanFuelLevel] = float64(level)
+		}
+	}
+	if m.TrackAll != nil {
+		attrs[AttrCanOdometer] = float64(m.GetTrackAll()) / 100
+	}
+	if m.Speed != nil {
+		attrs[AttrCanSpeed] = float64(m.GetSpeed())
+	}
+	if m.FlagSecurityState != nil {
+		attrs[AttrCanSecurityFlags] = int64(m.GetFlagSecurityState())
+	}
+	if m.FlagAlarm != nil {
+		attrs[AttrCanAlarmFlags] = int64(m.GetFlagAlarm())
+	}
+	for i, p := range []*uint32{m.PressureAxis_1, m.PressureAxis_2, m.PressureAxis_3, m.PressureAxis_4,
+		m.PressureAxis_5} {
+		if p != nil {
+			attrs[fmt.Sprintf("%s_%d", common.AxleLoad, i+1)] = float64(*p) / 10
+		}
+	}
+}
+
+// cellular converts the GSM base station information. LAC and CID are big-endian numbers.
+func (m *SensGsmCellMonotoringCellMonitoring) cellular() *common.Cellular {
+	return &common.Cellular{
+		CellID: bytesToInt64(m.GetCid()),
+		LAC:    bytesToInt64(m.GetLac()),
+		MCC:    int64(m.GetMcc()),
+		MNC:    int64(m.GetMnc()),
+	}
+}
+
+func bytesToInt64(b []byte) int64 {
+	var v int64
+	for _, c := range b {
+		v = v<<8 | int64(c)
+	}
+	return v
+}
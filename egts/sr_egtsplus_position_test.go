@@ -0,0 +1,183 @@
+package egts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/gotrackery/protocol/common"
+	"github.com/peterstace/simplefeatures/geom"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorageRecord_PositionCapture(t *testing.T) {
+	sr := StorageRecord{}
+	if !assert.NoError(t, sr.Decode(srEgtsPlusBytes)) {
+		return
+	}
+
+	pos := sr.Position()
+	assert.Equal(t, ProtocolName, pos.Protocol)
+	assert.Equal(t, time.Date(2019, time.February, 9, 21, 29, 31, 0, time.UTC), pos.DeviceTime)
+	assert.False(t, pos.Valid)
+	assert.Equal(t, common.Attributes{common.EngineTemperature: int64(-40)}, pos.Attributes)
+}
+
+// srEgtsPlusFixBytes is the storage record with a valid fix and the sensor blocks, assembled by hand from
+// the protobuf schema as a device sends it: unpacked reasons, zigzag temperatures, one message per sensor.
+// It is not a capture from a device.
+var srEgtsPlusFixBytes = []byte{
+	0x08, 0x02, // record_number 2
+	0x15, 0x3B, 0x46, 0x5F, 0x5C, // time_stamp 1549747771
+	0x18, 0x0B, // record_reason TIMER_MOVE
+	0x25, 0x00, 0x00, 0x00, 0x00, // status_flags 0
+	0x3A, 0x18, // sens_nd_nav_data
+	0x15, 0x6D, 0x71, 0x14, 0x08, // longitude 135557485 mas
+	0x1D, 0xE5, 0x04, 0xF6, 0x0B, // latitude 200672485 mas
+	0x20, 0xB4, 0x01, // altitude 180
+	0x28, 0x2A, // speed 42
+	0x30, 0x8F, 0x02, // course 271
+	0x38, 0x0B, // sat_count 11
+	0x50, 0xC0, 0xC4, 0x07, // odometer 123456
+	0x42, 0x09, 0x08, 0x00, 0x15, 0x00, 0x00, 0xF4, 0x41, 0x20, 0x01, // sens_fuel_level 0: 30.5 l
+	0x42, 0x05, 0x08, 0x01, 0x18, 0xD0, 0x0F, // sens_fuel_level 1: 2000 parrots
+	0x52, 0x04, 0x10, 0x05, 0x18, 0x02, // sens_dins_flags: device 5, external 2
+	0x5A, 0x04, 0x08, 0x03, 0x10, 0x4D, // sens_counter_count 3: 77
+	0x6A, 0x0F, 0x10, 0xFA, 0x01, 0x18, 0x01, // sens_gsm_cell_monitoring: MCC 250, MNC 1
+	0x22, 0x02, 0x1E, 0x61, 0x2A, 0x04, 0x00, 0x01, 0x86, 0xA0, // LAC 7777, CID 100000
+	0x7A, 0x06, 0x08, 0x01, 0x10, 0x00, 0x18, 0x0D, // sens_termo_data 1: -7
+	0x7A, 0x06, 0x08, 0x02, 0x10, 0x01, 0x18, 0x00, // sens_termo_data 2: fault
+	0x82, 0x01, 0x13, // sens_can_log_data
+	0x20, 0x7D, // engine_time_all 125
+	0x28, 0x88, 0x0E, // engine_turn_speed 1800
+	0x30, 0x17, // engine_temperature -12
+	0x40, 0xCB, 0x80, 0x02, // fuel_level 75 %
+	0x48, 0x87, 0xAD, 0x4B, // track_all 1234567
+	0x60, 0xD8, 0xAD, 0x03, // pressure_axis_2 55000
+}
+
+func TestStorageRecord_PositionFix(t *testing.T) {
+	sr := StorageRecord{}
+	if !assert.NoError(t, sr.Decode(srEgtsPlusFixBytes)) {
+		return
+	}
+
+	calibration := FuelCalibration{1: {Table: common.CalibrationTable{{Raw: 0, Value: 0}, {Raw: 4000, Value: 100}}}}
+	pos := sr.Position(func(o *Options) { o.Fuel = calibration })
+	assert.Equal(t, ProtocolName, pos.Protocol)
+	assert.Equal(t, time.Date(2019, time.February, 9, 21, 29, 31, 0, time.UTC), pos.DeviceTime)
+	assert.True(t, pos.Valid)
+	assert.Equal(t, geom.DimXYZ, pos.Type)
+	assert.Equal(t, 135557485/msdAngleUnit, pos.X)
+	assert.Equal(t, 200672485/msdAngleUnit, pos.Y)
+	assert.InDelta(t, 37.654857, pos.X, 1e-6)
+	assert.InDelta(t, 55.742357, pos.Y, 1e-6)
+	assert.Equal(t, 180.0, pos.Z)
+	assert.Equal(t, 42.0, pos.Speed.Float64)
+	assert.Equal(t, 271.0, pos.Course.Float64)
+	assert.Equal(t, &common.Cellular{CellID: 100000, LAC: 7777, MCC: 250, MNC: 1}, pos.Cellular)
+	assert.Equal(t, common.Attributes{
+		common.Event:             "timer_move",
+		common.Satellites:        int64(11),
+		common.Odometer:          123.456,
+		common.EngineHours:       1.25,
+		common.RPM:               int64(1800),
+		common.EngineTemperature: int64(-12),
+		AttrCanFuelLevelPct:      75.0,
+		AttrCanOdometer:          12345.67,
+		"axle_load_2":            5500.0,
+		"temp_1":                 int64(-7),
+		"fuel_level_0":           30.5,
+		"fuel_level_1":           50.0,
+		common.FuelLevel:         80.5,
+		common.DigInput:          int64(5),
+		AttrDigInputExt:          int64(2),
+		"counter_3":              int64(77),
+	}, pos.Attributes)
+}
+
+func TestStorageRecord_Position(t *testing.T) {
+	record := StorageRecord{
+		RecordNumber: proto.Uint32(1),
+		TimeStamp:    proto.Uint32(1549747771),
+		StatusFlags:  proto.Uint32(0),
+		RecordReason: []StorageRecordReason{StorageRecord_IGNITION_ON, StorageRecord_SOS_BUTTON},
+		SensNdNavData: []*SensNdNavData{{
+			Longitude: proto.Int32(135557484), // 37.654857°
+			Latitude:  proto.Int32(-200672484),
+			Altitude:  proto.Uint32(180),
+			Speed:     proto.Uint32(42),
+			Course:    proto.Uint32(271),
+			SatCount:  proto.Uint32(11),
+			Odometer:  proto.Uint32(123456),
+		}},
+		SensCanLogData: []*SensCanLogData{{
+			EngineTimeAll:   proto.Uint32(125),
+			EngineTurnSpeed: proto.Uint32(1800),
+			FuelLevel:       proto.Uint32(1<<15 | 75),
+			TrackAll:        proto.Uint32(1234567),
+			PressureAxis_2:  proto.Uint32(55000),
+		}},
+		SensTermoData: []*SensTermoData{
+			{SensNum: proto.Uint32(1), Status: proto.Uint32(0), Temperature: proto.Int32(-7)},
+			{SensNum: proto.Uint32(2), Status: proto.Uint32(1), Temperature: proto.Int32(0)},
+		},
+		SensFuelLevel: []*SensFuelLevel{
+			{SensNum: proto.Uint32(0), Value: proto.Float32(30.5), Unit: proto.Uint32(FuelUnitLitres)},
+			{SensNum: proto.Uint32(1), Parrots: proto.Uint32(2000)},
+		},
+		SensDinsFlags:    []*SensDinsFlags{{Device: proto.Uint32(5), External: proto.Uint32(2)}},
+		SensCounterCount: []*SensCounterCount{{SensNum: proto.Uint32(3), Value: proto.Uint32(77)}},
+		SensGsmCellMonotoringCellMonitoring: []*SensGsmCellMonotoringCellMonitoring{{
+			Lac: []byte{0x1E, 0x61}, Cid: []byte{0x00, 0x01, 0x86, 0xA0}, Mcc: proto.Uint32(250), Mnc: proto.Uint32(1),
+		}},
+	}
+	data, err := record.Encode()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	sdr := ServiceDataRecord{ObjectIDFieldExists: "1", ObjectIdentifier: 42}
+	rdBytes := append([]byte{SrEgtsPlusDataType, byte(len(data)), byte(len(data) >> 8)}, data...)
+	if !assert.NoError(t, sdr.RecordDataSet.Decode(rdBytes)) {
+		return
+	}
+
+	calibration := FuelCalibration{1: {Table: common.CalibrationTable{{Raw: 0, Value: 0}, {Raw: 4000, Value: 100}}}}
+	positions := sdr.Positions(func(o *Options) { o.Fuel = calibration })
+	if !assert.Len(t, positions, 1) {
+		return
+	}
+	pos := positions[0]
+
+	assert.Equal(t, "42", pos.DeviceID)
+	assert.True(t, pos.Valid)
+	assert.Equal(t, geom.DimXYZ, pos.Type)
+	assert.InDelta(t, 37.654857, pos.X, 1e-6)
+	assert.InDelta(t, -55.742357, pos.Y, 1e-6)
+	assert.Equal(t, 180.0, pos.Z)
+	assert.Equal(t, 42.0, pos.Speed.Float64)
+	assert.Equal(t, 271.0, pos.Course.Float64)
+	assert.Equal(t, &common.Cellular{CellID: 100000, LAC: 7777, MCC: 250, MNC: 1}, pos.Cellular)
+
+	want := common.Attributes{
+		common.Event:        "ignition_on,sos_button",
+		common.Ignition:     int64(1),
+		common.Alarm:        int64(1),
+		common.Satellites:   int64(11),
+		common.Odometer:     123.456,
+		common.EngineHours:  1.25,
+		common.RPM:          int64(1800),
+		AttrCanFuelLevelPct: 75.0,
+		AttrCanOdometer:     12345.67,
+		"axle_load_2":       5500.0,
+		"temp_1":            int64(-7),
+		"fuel_level_0":      30.5,
+		"fuel_level_1":      50.0,
+		common.FuelLevel:    80.5,
+		common.DigInput:     int64(5),
+		AttrDigInputExt:     int64(2),
+		"counter_3":         int64(77),
+	}
+	assert.Equal(t, want, pos.Attributes)
+}
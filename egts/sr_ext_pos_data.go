@@ -35,7 +35,7 @@ func (e *SrExtPosData) Decode(content []byte) error {
 	if flags, err = buf.ReadByte(); err != nil {
 		return fmt.Errorf("failed to get the ext_pos_data flags byte: %w", err)
 	}
-	flagBits := fmt.Sprintf("%08b", flags)
+	flagBits := bitString(flags)
 
 	e.NavigationSystemFieldExists = flagBits[3:4]
 	e.SatellitesFieldExists = flagBits[4:5]
@@ -147,3 +147,15 @@ func (e *SrExtPosData) Length() uint16 {
 
 	return result
 }
+
+// Validate checks the flags of EGTS_SR_EXT_POS_DATA.
+func (e *SrExtPosData) Validate() error {
+	var v violations
+
+	v.flag("NSFE", e.NavigationSystemFieldExists, 1)
+	v.flag("SFE", e.SatellitesFieldExists, 1)
+	v.flag("PFE", e.PdopFieldExists, 1)
+	v.flag("HFE", e.HdopFieldExists, 1)
+	v.flag("VFE", e.VdopFieldExists, 1)
+	return v.err()
+}
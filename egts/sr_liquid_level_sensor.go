@@ -30,7 +30,7 @@ func (e *SrLiquidLevelSensor) Decode(content []byte) error {
 	if flags, err = buf.ReadByte(); err != nil {
 		return fmt.Errorf("failed to get liquid_level flags byte: %w", err)
 	}
-	flagBits := fmt.Sprintf("%08b", flags)
+	flagBits := bitString(flags)
 
 	e.LiquidLevelSensorErrorFlag = flagBits[1:2]
 	e.LiquidLevelSensorValueUnit = flagBits[2:4]
@@ -100,3 +100,14 @@ func (e *SrLiquidLevelSensor) Length() uint16 {
 
 	return result
 }
+
+// Validate checks the flags and the sensor number of EGTS_SR_LIQUID_LEVEL_SENSOR.
+func (e *SrLiquidLevelSensor) Validate() error {
+	var v violations
+
+	v.flag("LLSEF", e.LiquidLevelSensorErrorFlag, 1)
+	v.flag("LLSVU", e.LiquidLevelSensorValueUnit, 2)
+	v.flag("RDF", e.RawDataFlag, 1)
+	v.max("LLSN", uint64(e.LiquidLevelSensorNumber), 7)
+	return v.err()
+}
@@ -146,3 +146,12 @@ func (e *SrModuleData) Length() uint16 {
 
 	return result
 }
+
+// Validate checks the length of the strings of EGTS_SR_MODULE_DATA.
+func (e *SrModuleData) Validate() error {
+	var v violations
+
+	v.maxLen("SRN", len(e.SerialNumber), 32)
+	v.maxLen("DSCR", len(e.Description), 32)
+	return v.err()
+}
@@ -107,21 +107,20 @@ type SrPosData struct {
 	// transport monitoring systems of the basic level.
 	DigitalInputs byte `json:"DIN"`
 	// Source (SRC) - defines the source (event) that initiated the sending of this navigation information.
-	Source byte `json:"SRC"`
+	Source EventSource `json:"SRC"`
 	// Altitude (ALT) - altitude above sea level, m (optional parameter,
 	// the presence of which is determined by the ALTE bit flag).
 	Altitude uint32 `json:"ALT"`
 	// SourceData (SRCD) - data characterizing the source (event) from the SRC field.
 	// The presence and interpretation of the value of this field is determined by the SRC field.
 	SourceData int16 `json:"SRCD"`
+	// HasSourceData marks that the optional SRCD field is present in the subrecord.
+	HasSourceData bool `json:"-"`
 }
 
 // Decode parses bytes into a subrecord structure.
 func (e *SrPosData) Decode(content []byte) (err error) {
-	var (
-		flags byte
-		speed uint64
-	)
+	var flags byte
 	buf := bytes.NewReader(content)
 
 	startDate := time.Date(2010, time.January, 1, 0, 0, 0, 0, time.UTC)
@@ -149,7 +148,7 @@ func (e *SrPosData) Decode(content []byte) (err error) {
 	if flags, err = buf.ReadByte(); err != nil {
 		return fmt.Errorf("failed to get the pos_data flags byte: %w", err)
 	}
-	flagBits := fmt.Sprintf("%08b", flags)
+	flagBits := bitString(flags)
 	e.ALTE = flagBits[:1]
 	e.LOHS = flagBits[1:2]
 	e.LAHS = flagBits[2:3]
@@ -168,13 +167,8 @@ func (e *SrPosData) Decode(content []byte) (err error) {
 	e.DirectionHighestBit = uint8(spd >> 15 & 0x1)
 	e.AltitudeSign = uint8(spd >> 14 & 0x1)
 
-	speedBits := fmt.Sprintf("%016b", spd)
-	if speed, err = strconv.ParseUint(speedBits[2:], 2, 16); err != nil {
-		return fmt.Errorf("failed to decrypt bit rate: %w", err)
-	}
-
-	// т.к. скорость с дискретностью 0,1 км
-	e.Speed = uint16(speed) / 10
+	// т.к. скорость с дискретностью 0,1 км, 14 младших бит
+	e.Speed = (spd & 0x3FFF) / 10
 
 	if e.Direction, err = buf.ReadByte(); err != nil {
 		return fmt.Errorf("failed to get the direction of travel: %w", err)
@@ -192,23 +186,27 @@ func (e *SrPosData) Decode(content []byte) (err error) {
 		return fmt.Errorf("failed to receive bit flags, determine the state of the main discrete inputs: %w", err)
 	}
 
-	if e.Source, err = buf.ReadByte(); err != nil {
+	var src byte
+	if src, err = buf.ReadByte(); err != nil {
 		return fmt.Errorf("failed to get the source (event) that initiated the parcel: %w", err)
 	}
+	e.Source = EventSource(src)
 
 	if e.ALTE == "1" {
 		bytesTmpBuf = []byte{0, 0, 0, 0}
-		if _, err = buf.Read(bytesTmpBuf); err != nil {
+		if _, err = buf.Read(bytesTmpBuf[:3]); err != nil {
 			return fmt.Errorf("failed to get the altitude above sea level: %w", err)
 		}
 		e.Altitude = binary.LittleEndian.Uint32(bytesTmpBuf)
 	}
 
-	// something wrong with it from real data
-	// err = binary.Read(buf, binary.LittleEndian, &e.Source)
-	// if err != nil {
-	// 	return fmt.Errorf("failed to get data characterizing the source: %w", err)
-	// }
+	// SRCD is optional and has no presence flag, so it is determined by the rest of the subrecord.
+	e.HasSourceData = buf.Len() >= 2
+	if e.HasSourceData {
+		if err = binary.Read(buf, binary.LittleEndian, &e.SourceData); err != nil {
+			return fmt.Errorf("failed to get data characterizing the source: %w", err)
+		}
+	}
 
 	return nil
 }
@@ -278,11 +276,11 @@ func (e *SrPosData) Encode() (result []byte, err error) {
 		}
 	}
 
-	// something wrong with it from real data
-	// err = binary.Write(buf, binary.LittleEndian, e.SourceData)
-	// if err != nil {
-	// 	return nil, fmt.Errorf("failed to record source data: %w", err)
-	// }
+	if e.HasSourceData {
+		if err = binary.Write(buf, binary.LittleEndian, e.SourceData); err != nil {
+			return nil, fmt.Errorf("failed to record source data: %w", err)
+		}
+	}
 
 	return buf.Bytes(), nil
 }
@@ -299,3 +297,30 @@ func (e *SrPosData) Length() uint16 {
 
 	return result
 }
+
+// Validate checks the fields of EGTS_SR_POS_DATA against the constraints of the specification.
+func (e *SrPosData) Validate() error {
+	var v violations
+
+	if e.NavigationTime.Before(timeOffset) {
+		v.add("NTM", e.NavigationTime, "must not be before 2010-01-01")
+	}
+	if e.Latitude < 0 || e.Latitude > 90 {
+		v.add("LAT", e.Latitude, "must be modulo in range [0, 90]")
+	}
+	if e.Longitude < 0 || e.Longitude > 180 {
+		v.add("LONG", e.Longitude, "must be modulo in range [0, 180]")
+	}
+	for _, f := range []struct{ name, value string }{
+		{"ALTE", e.ALTE}, {"LOHS", e.LOHS}, {"LAHS", e.LAHS}, {"MV", e.MV},
+		{"BB", e.BB}, {"CS", e.CS}, {"FIX", e.FIX}, {"VLD", e.VLD},
+	} {
+		v.flag(f.name, f.value, 1)
+	}
+	v.max("DIRH", uint64(e.DirectionHighestBit), 1)
+	v.max("ALTS", uint64(e.AltitudeSign), 1)
+	v.max("SPD", uint64(e.Speed), (1<<14-1)/10) // encoded in 0.1 km/h
+	v.max("ODM", uint64(e.Odometer), 1<<24-1)
+	v.max("ALT", uint64(e.Altitude), 1<<24-1)
+	return v.err()
+}
@@ -47,3 +47,24 @@ func TestEgtsSrPosData_Decode(t *testing.T) {
 		assert.Equal(t, posData, testEgtsSrPosData)
 	}
 }
+
+func TestEgtsSrPosData_AltitudeAndSourceData(t *testing.T) {
+	posDataBytes := []byte{0x55, 0x91, 0x02, 0x10, 0x6F, 0x1C, 0x05, 0x9E, 0x7A, 0xB5, 0x3C, 0x35,
+		0x81, 0xD0, 0x87, 0x2C, 0x01, 0x00, 0x00, 0x00, 0x23, 0x96, 0x00, 0x00, 0x02, 0x00}
+	posData := testEgtsSrPosData
+	posData.ALTE = "1"
+	posData.Source = SrcModeChange
+	posData.Altitude = 150
+	posData.SourceData = int16(StateActive)
+	posData.HasSourceData = true
+
+	decoded := SrPosData{}
+	if assert.NoError(t, decoded.Decode(posDataBytes)) {
+		assert.Equal(t, posData, decoded)
+	}
+
+	encoded, err := posData.Encode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, posDataBytes, encoded)
+	}
+}
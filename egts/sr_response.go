@@ -69,3 +69,11 @@ func (s *SrResponse) Length() uint16 {
 
 	return result
 }
+
+// Validate checks the result code of EGTS_SR_RESPONSE.
+func (s *SrResponse) Validate() error {
+	var v violations
+
+	v.resultCode("RST", s.RecordStatus)
+	return v.err()
+}
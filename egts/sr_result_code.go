@@ -53,3 +53,11 @@ func (s *SrResultCode) Length() uint16 {
 
 	return result
 }
+
+// Validate checks the result code of EGTS_SR_RESULT_CODE.
+func (s *SrResultCode) Validate() error {
+	var v violations
+
+	v.resultCode("RCD", s.ResultCode)
+	return v.err()
+}
@@ -4,19 +4,90 @@ import (
 	"bytes"
 	"fmt"
 	"strconv"
+
+	"github.com/gotrackery/protocol/common"
+)
+
+// TerminalState is the current operating mode of the subscriber terminal (ST field of EGTS_SR_STATE_DATA).
+type TerminalState uint8
+
+// Operating modes of the subscriber terminal.
+const (
+	StatePassive           TerminalState = 0 // "Passive" mode
+	StateEra               TerminalState = 1 // "ERA" (ERA-GLONASS) mode
+	StateActive            TerminalState = 2 // "Active" mode
+	StateEmergencyCall     TerminalState = 3 // "Emergency call" mode
+	StateEmergencyTracking TerminalState = 4 // "Emergency tracking" mode
+	StateTesting           TerminalState = 5 // "Testing" mode
+	StateService           TerminalState = 6 // "Car service" mode
+	StateFirmwareUpdate    TerminalState = 7 // "Firmware update" mode
 )
 
+var terminalStateNames = map[TerminalState]string{
+	StatePassive:           "passive",
+	StateEra:               "era",
+	StateActive:            "active",
+	StateEmergencyCall:     "emergency_call",
+	StateEmergencyTracking: "emergency_tracking",
+	StateTesting:           "testing",
+	StateService:           "service",
+	StateFirmwareUpdate:    "firmware_update",
+}
+
+// String returns the name of the operating mode.
+func (s TerminalState) String() string {
+	if name, ok := terminalStateNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("state_%d", uint8(s))
+}
+
+// voltageUnit is the resolution of the voltage fields, V.
+const voltageUnit = 0.1
+
 // SrStateData is the structure of subrecord of EGTS_SR_STATE_DATA type, used to transmit to the
 // information about the subscriber terminal state (current operation mode,
 // voltage of the main and backup power supplies, etc.).
 type SrStateData struct {
-	State                  uint8  `json:"ST"`
-	MainPowerSourceVoltage uint8  `json:"MPSV"`
-	BackUpBatteryVoltage   uint8  `json:"BBV"`
-	InternalBatteryVoltage uint8  `json:"IBV"`
-	NMS                    string `json:"NMS"`
-	IBU                    string `json:"IBU"`
-	BBU                    string `json:"BBU"`
+	// State (ST) - current operating mode.
+	State TerminalState `json:"ST"`
+	// MainPowerSourceVoltage (MPSV) - main power supply voltage in increments of 0.1 V.
+	MainPowerSourceVoltage uint8 `json:"MPSV"`
+	// BackUpBatteryVoltage (BBV) - backup battery voltage in increments of 0.1 V.
+	BackUpBatteryVoltage uint8 `json:"BBV"`
+	// InternalBatteryVoltage (IBV) - internal battery voltage in increments of 0.1 V.
+	InternalBatteryVoltage uint8 `json:"IBV"`
+	// NMS - bit flag, the navigation module is on.
+	NMS string `json:"NMS"`
+	// IBU - bit flag, the internal battery is used.
+	IBU string `json:"IBU"`
+	// BBU - bit flag, the backup battery is used.
+	BBU string `json:"BBU"`
+}
+
+// MainPowerSourceVolts returns the main power supply voltage, V.
+func (e *SrStateData) MainPowerSourceVolts() float64 {
+	return float64(e.MainPowerSourceVoltage) * voltageUnit
+}
+
+// BackUpBatteryVolts returns the backup battery voltage, V.
+func (e *SrStateData) BackUpBatteryVolts() float64 {
+	return float64(e.BackUpBatteryVoltage) * voltageUnit
+}
+
+// InternalBatteryVolts returns the internal battery voltage, V.
+func (e *SrStateData) InternalBatteryVolts() float64 {
+	return float64(e.InternalBatteryVoltage) * voltageUnit
+}
+
+// Attributes maps the terminal state to common attributes: operating mode and voltages in volts.
+func (e *SrStateData) Attributes() common.Attributes {
+	return common.Attributes{
+		common.State:           e.State.String(),
+		common.Power:           e.MainPowerSourceVolts(),
+		common.Battery:         e.BackUpBatteryVolts(),
+		common.InternalBattery: e.InternalBatteryVolts(),
+	}
 }
 
 // Decode parses the set of bytes into EGTS_SR_STATE_DATA structure.
@@ -27,9 +98,11 @@ func (e *SrStateData) Decode(content []byte) error {
 	)
 
 	buf := bytes.NewReader(content)
-	if e.State, err = buf.ReadByte(); err != nil {
+	st, err := buf.ReadByte()
+	if err != nil {
 		return fmt.Errorf("failed to read the current mode of operation: %w", err)
 	}
+	e.State = TerminalState(st)
 
 	if e.MainPowerSourceVoltage, err = buf.ReadByte(); err != nil {
 		return fmt.Errorf("the value of the main power supply voltage could not be read: %w", err)
@@ -46,7 +119,7 @@ func (e *SrStateData) Decode(content []byte) error {
 	if flags, err = buf.ReadByte(); err != nil {
 		return fmt.Errorf("failed to get state_data flags byte: %w", err)
 	}
-	flagBits := fmt.Sprintf("%08b", flags)
+	flagBits := bitString(flags)
 	e.NMS = flagBits[5:6]
 	e.IBU = flagBits[6:7]
 	e.BBU = flagBits[7:]
@@ -63,7 +136,7 @@ func (e *SrStateData) Encode() ([]byte, error) {
 	)
 	buf := new(bytes.Buffer)
 
-	if err = buf.WriteByte(e.State); err != nil {
+	if err = buf.WriteByte(uint8(e.State)); err != nil {
 		return result, fmt.Errorf("the current operating mode could not be written: %w", err)
 	}
 
@@ -103,3 +176,14 @@ func (e *SrStateData) Length() uint16 {
 
 	return result
 }
+
+// Validate checks the state and the flags of EGTS_SR_STATE_DATA.
+func (e *SrStateData) Validate() error {
+	var v violations
+
+	v.max("ST", uint64(e.State), uint64(StateFirmwareUpdate))
+	v.flag("NMS", e.NMS, 1)
+	v.flag("IBU", e.IBU, 1)
+	v.flag("BBU", e.BBU, 1)
+	return v.err()
+}
@@ -1,8 +1,10 @@
 package egts
 
 import (
-	"github.com/stretchr/testify/assert"
 	"testing"
+
+	"github.com/gotrackery/protocol/common"
+	"github.com/stretchr/testify/assert"
 )
 
 var (
@@ -55,3 +57,15 @@ func TestEgtsSrStateDataRs(t *testing.T) {
 		}
 	}
 }
+
+func TestEgtsSrStateData_Attributes(t *testing.T) {
+	assert.Equal(t, "active", testEgtsSrStateData.State.String())
+	assert.Equal(t, "state_9", TerminalState(9).String())
+	assert.InDelta(t, 12.7, testEgtsSrStateData.MainPowerSourceVolts(), 1e-9)
+	assert.InDelta(t, 4.1, testEgtsSrStateData.InternalBatteryVolts(), 1e-9)
+
+	attrs := testEgtsSrStateData.Attributes()
+	assert.Equal(t, "active", attrs[common.State])
+	assert.InDelta(t, 12.7, attrs[common.Power], 1e-9)
+	assert.InDelta(t, 0.0, attrs[common.Battery], 1e-9)
+}
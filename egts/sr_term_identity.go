@@ -45,7 +45,7 @@ func (e *SrTermIdentity) Decode(content []byte) error {
 	if flags, err = buf.ReadByte(); err != nil {
 		return fmt.Errorf("failed to read the flags byte term identify: %w", err)
 	}
-	flagBits := fmt.Sprintf("%08b", flags)
+	flagBits := bitString(flags)
 	e.MNE = flagBits[:1]
 	e.BSE = flagBits[1:2]
 	e.NIDE = flagBits[2:3]
@@ -193,3 +193,30 @@ func (e *SrTermIdentity) Length() uint16 {
 
 	return result
 }
+
+// Validate checks the flags of EGTS_SR_TERM_IDENTITY and the length of the optional fields.
+func (e *SrTermIdentity) Validate() error {
+	var v violations
+
+	for _, f := range []struct{ name, value string }{
+		{"MNE", e.MNE}, {"BSE", e.BSE}, {"NIDE", e.NIDE}, {"SSRA", e.SSRA},
+		{"LNGCE", e.LNGCE}, {"IMSIE", e.IMSIE}, {"IMEIE", e.IMEIE}, {"HDIDE", e.HDIDE},
+	} {
+		v.flag(f.name, f.value, 1)
+	}
+	for _, f := range []struct {
+		name, exists string
+		length, want int
+	}{
+		{"IMEI", e.IMEIE, len(e.IMEI), 15},
+		{"IMSI", e.IMSIE, len(e.IMSI), 16},
+		{"LNGC", e.LNGCE, len(e.LanguageCode), 3},
+		{"NID", e.NIDE, len(e.NetworkIdentifier), 3},
+		{"MSISDN", e.MNE, len(e.MobileNumber), 15},
+	} {
+		if f.exists == "1" && f.length != f.want {
+			v.add(f.name+" length", f.length, fmt.Sprintf("must be %d", f.want))
+		}
+	}
+	return v.err()
+}
@@ -0,0 +1,234 @@
+package egts
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/gotrackery/protocol/common"
+)
+
+const (
+	// MaxPacketLength is the maximum length of the whole EGTS packet.
+	MaxPacketLength = 65535
+	// minHeaderPrefix is the number of the header bytes needed to know the packet length (up to FDL).
+	minHeaderPrefix = 7
+	// readerBufferSize is the initial size of the pooled reader buffer, it grows for longer packets.
+	readerBufferSize = 4096
+)
+
+// ErrFrameTooLarge is returned by Reader when the packet is longer than the maximum frame size.
+var ErrFrameTooLarge = errors.New("packet length exceeds the maximum frame size")
+
+var readerBuffers = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, readerBufferSize)
+		return &b
+	},
+}
+
+// Frame is the packet read from the stream along with its raw bytes.
+type Frame struct {
+	Packet *Packet
+	// Raw is the packet as it was received. It refers to the reader buffer
+	// and is valid until the next call of ReadPacket, copy it to keep.
+	Raw []byte
+}
+
+// Reader reads EGTS packets from the bytes stream.
+// It replaces bufio.Scanner with Splitter and reuses the read buffers of the released readers,
+// the packets are decoded into new Packet values as Packet.Decode does.
+type Reader struct {
+	// MaxFrameSize limits the length of the packet, longer packets stop the reading with ErrFrameTooLarge.
+	MaxFrameSize int
+
+	r     io.Reader
+	opt   []func(*Options)
+	buf   *[]byte
+	start int
+	end   int
+	err   error
+}
+
+// NewReader returns reader of the stream decoding the packets with the given options.
+func NewReader(r io.Reader, opt ...func(*Options)) *Reader {
+	return &Reader{
+		MaxFrameSize: MaxPacketLength,
+		r:            r,
+		opt:          opt,
+		buf:          readerBuffers.Get().(*[]byte), //nolint:forcetypeassert
+	}
+}
+
+// ReadPacket reads and decodes the next packet. It returns io.EOF when the stream ends between the packets.
+// When the packet is framed properly but can't be decoded, the frame is returned along with the error:
+// the reading may go on and the packet ErrorCode is ready for the response.
+// Framing errors (common.ErrBadData, ErrFrameTooLarge, io.ErrUnexpectedEOF) are returned by all the next calls.
+func (r *Reader) ReadPacket() (Frame, error) {
+	if r.err != nil {
+		return Frame{}, r.err
+	}
+
+	size, err := r.frameSize()
+	if err != nil {
+		r.err = err
+		return Frame{}, err
+	}
+
+	buf := *r.buf
+	frame := Frame{
+		Packet: &Packet{},
+		Raw:    buf[r.start : r.start+size : r.start+size],
+	}
+	r.start += size
+
+	if err = frame.Packet.Decode(frame.Raw, r.opt...); err != nil {
+		return frame, fmt.Errorf("failed to decode packet: %w", err)
+	}
+	return frame, nil
+}
+
+// Release returns the buffer of the reader to the pool. The reader can't be used after that.
+func (r *Reader) Release() {
+	if r.buf == nil {
+		return
+	}
+	readerBuffers.Put(r.buf)
+	r.buf = nil
+	r.err = io.ErrClosedPipe
+}
+
+// frameSize reads the packet header and the whole packet into the buffer and returns the packet length.
+func (r *Reader) frameSize() (int, error) {
+	if err := r.fill(minHeaderPrefix); err != nil {
+		return 0, err
+	}
+
+	buf := *r.buf
+	if buf[r.start] != allowedFirstByte1 {
+		return 0, fmt.Errorf("unsupported protocol version %d: %w", buf[r.start], common.ErrBadData)
+	}
+	headerLen := int(buf[r.start+3])
+	if headerLen < DefaultHeaderLen {
+		return 0, fmt.Errorf("incorrect header length %d: %w", headerLen, common.ErrBadData)
+	}
+	size := headerLen
+	if dataLen := int(binary.LittleEndian.Uint16(buf[r.start+5 : r.start+7])); dataLen > 0 {
+		size += dataLen + 2
+	}
+	if size > r.MaxFrameSize {
+		return 0, fmt.Errorf("packet of %d bytes, limit %d: %w", size, r.MaxFrameSize, ErrFrameTooLarge)
+	}
+
+	if err := r.fill(size); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// fill reads the stream until the buffer holds n unread bytes.
+func (r *Reader) fill(n int) error {
+	for r.end-r.start < n {
+		buf := *r.buf
+		if r.start+n > len(buf) {
+			if n > len(buf) {
+				grown := make([]byte, n)
+				r.buf = &grown
+			}
+			r.end = copy(*r.buf, buf[r.start:r.end])
+			r.start = 0
+			buf = *r.buf
+		}
+
+		read, err := r.r.Read(buf[r.end:])
+		r.end += read
+		if err == nil {
+			continue
+		}
+		if r.end-r.start >= n {
+			return nil
+		}
+		if errors.Is(err, io.EOF) && r.end > r.start {
+			return fmt.Errorf("packet is truncated: %w", io.ErrUnexpectedEOF)
+		}
+		return err //nolint:wrapcheck
+	}
+	return nil
+}
+
+// Writer writes EGTS packets to the bytes stream.
+// The packet identifiers and record numbers of the stream are sequenced independently of the other streams.
+// It is safe for concurrent use.
+type Writer struct {
+	// Header is the template of the packets written by WriteRecords, it must not be encrypted or compressed.
+	Header Packet
+	// BufferSize limits the size of the packets written by WriteRecords, see BatchEncoder.
+	BufferSize uint16
+
+	w   io.Writer
+	opt []func(*Options)
+	mu  sync.Mutex
+	seq streamSequence
+}
+
+// NewWriter returns writer of unrouted and unencrypted packets encoding them with the given options.
+func NewWriter(w io.Writer, opt ...func(*Options)) *Writer {
+	return &Writer{
+		Header: defaultHeader(),
+		w:      w,
+		opt:    opt,
+	}
+}
+
+// WritePacket assigns the next packet identifier of the stream to the packet, encodes and writes it.
+func (w *Writer) WritePacket(p *Packet) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	p.PacketIdentifier = w.seq.nextPacketIdentifier()
+	data, err := p.Encode(w.opt...)
+	if err != nil {
+		return fmt.Errorf("failed to encode packet %d: %w", p.PacketIdentifier, err)
+	}
+	return w.write(data)
+}
+
+// WriteRecords numbers the records, packs them into APPDATA packets and writes them.
+// The returned batches tell which records are carried by which packet.
+func (w *Writer) WriteRecords(records []ServiceDataRecord) ([]Batch, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	enc := BatchEncoder{Header: w.Header, BufferSize: w.BufferSize, seq: &w.seq}
+	batches, err := enc.Encode(records, w.opt...)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range batches {
+		if err = w.write(b.Data); err != nil {
+			return nil, err
+		}
+	}
+	return batches, nil
+}
+
+// WriteResponse writes the response to the received packet (see Packet.Response).
+func (w *Writer) WriteResponse(p *Packet) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	data, err := p.response(&w.seq)
+	if err != nil {
+		return fmt.Errorf("failed to prepare response to packet %d: %w", p.PacketIdentifier, err)
+	}
+	return w.write(data)
+}
+
+func (w *Writer) write(data []byte) error {
+	if _, err := w.w.Write(data); err != nil {
+		return fmt.Errorf("failed to write packet: %w", err)
+	}
+	return nil
+}
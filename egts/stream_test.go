@@ -0,0 +1,198 @@
+package egts
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"testing/iotest"
+
+	"github.com/gotrackery/protocol/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReader_ReadPacket(t *testing.T) {
+	tests := []struct {
+		path      string
+		wantCount int
+		wantLen   int
+		wantErr   error
+	}{
+		{path: "./testdata/0001.data", wantCount: 41, wantLen: 5872, wantErr: io.EOF},
+		{path: "./testdata/0002.data", wantCount: 59, wantLen: 6346, wantErr: io.EOF},
+		{path: "./testdata/0003.data", wantCount: 77, wantLen: 8673, wantErr: io.EOF},
+		{path: "./testdata/0004.data", wantCount: 395, wantLen: 31967, wantErr: io.ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			data, err := os.ReadFile(tt.path)
+			require.NoError(t, err)
+
+			// one byte reads make the reader grow and compact the buffer
+			r := NewReader(iotest.OneByteReader(bytes.NewReader(data)))
+			defer r.Release()
+
+			var cnt, ln int
+			for {
+				frame, err := r.ReadPacket()
+				if frame.Packet == nil {
+					assert.True(t, errors.Is(err, tt.wantErr), "got error %v", err)
+					break
+				}
+				assert.Equal(t, data[ln:ln+len(frame.Raw)], frame.Raw)
+				cnt++
+				ln += len(frame.Raw)
+			}
+			assert.Equal(t, tt.wantCount, cnt)
+			assert.Equal(t, tt.wantLen, ln)
+		})
+	}
+}
+
+func TestReader_ReadPacketErrors(t *testing.T) {
+	t.Run("max frame size", func(t *testing.T) {
+		r := NewReader(bytes.NewReader(egtsPkgPosDataBytes))
+		r.MaxFrameSize = len(egtsPkgPosDataBytes) - 1
+		_, err := r.ReadPacket()
+		assert.True(t, errors.Is(err, ErrFrameTooLarge))
+		_, err = r.ReadPacket()
+		assert.True(t, errors.Is(err, ErrFrameTooLarge), "framing error is sticky")
+	})
+
+	t.Run("bad data", func(t *testing.T) {
+		r := NewReader(bytes.NewReader(egtsPkgPosDataBytes[1:]))
+		_, err := r.ReadPacket()
+		assert.True(t, errors.Is(err, common.ErrBadData))
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		r := NewReader(bytes.NewReader(egtsPkgPosDataBytes[:20]))
+		_, err := r.ReadPacket()
+		assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	})
+
+	t.Run("decode error", func(t *testing.T) {
+		data := append([]byte(nil), egtsPkgPosDataBytes...)
+		data[len(data)-1]++ // SFRCS mismatch
+		r := NewReader(bytes.NewReader(append(data, egtsPkgPosDataBytes...)))
+
+		frame, err := r.ReadPacket()
+		assert.True(t, errors.Is(err, ErrFrameCheckSum))
+		if assert.NotNil(t, frame.Packet) {
+			assert.NotEqual(t, EgtsPcOk, frame.Packet.ErrorCode)
+		}
+
+		frame, err = r.ReadPacket()
+		if assert.NoError(t, err) {
+			assert.Equal(t, egtsPkgPosDataBytes, frame.Raw)
+		}
+	})
+
+	t.Run("released", func(t *testing.T) {
+		r := NewReader(bytes.NewReader(egtsPkgPosDataBytes))
+		r.Release()
+		_, err := r.ReadPacket()
+		assert.Error(t, err)
+	})
+}
+
+func TestWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewWriter(buf)
+	w.BufferSize = 128
+
+	batches, err := w.WriteRecords(testBatchRecords(20))
+	require.NoError(t, err)
+	require.Greater(t, len(batches), 1)
+
+	var rn uint16
+	for i, b := range batches {
+		assert.Equal(t, uint16(i), b.PacketIdentifier)
+		for _, n := range b.RecordNumbers {
+			assert.Equal(t, rn, n)
+			rn++
+		}
+	}
+
+	r := NewReader(buf)
+	defer r.Release()
+	for _, b := range batches {
+		frame, err := r.ReadPacket()
+		require.NoError(t, err)
+		assert.Equal(t, b.Data, frame.Raw)
+
+		// the response to the packet continues the sequence of the stream
+		resp := new(bytes.Buffer)
+		rw := NewWriter(resp)
+		require.NoError(t, rw.WriteResponse(frame.Packet))
+		p := Packet{}
+		require.NoError(t, p.Decode(resp.Bytes()))
+		assert.Equal(t, uint16(0), p.PacketIdentifier)
+		if ptr, ok := p.ServicesFrameData.(*PtResponse); assert.True(t, ok) {
+			assert.Equal(t, b.PacketIdentifier, ptr.ResponsePacketID)
+		}
+	}
+
+	p := defaultHeader()
+	p.PacketType = PtAppdataPacket
+	p.ServicesFrameData = &ServiceDataSet{testBatchRecords(1)[0]}
+	require.NoError(t, w.WritePacket(&p))
+	assert.Equal(t, uint16(len(batches)), p.PacketIdentifier)
+
+	frame, err := r.ReadPacket()
+	require.NoError(t, err)
+	assert.Equal(t, p.PacketIdentifier, frame.Packet.PacketIdentifier)
+}
+
+func readTestData(b *testing.B) []byte {
+	b.Helper()
+	data, err := os.ReadFile("./testdata/0003.data")
+	if err != nil {
+		b.Fatal(err)
+	}
+	return data
+}
+
+func BenchmarkReader(b *testing.B) {
+	data := readTestData(b)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		r := NewReader(bytes.NewReader(data))
+		for {
+			if _, err := r.ReadPacket(); err != nil {
+				if !errors.Is(err, io.EOF) {
+					b.Fatal(err)
+				}
+				break
+			}
+		}
+		r.Release()
+	}
+}
+
+func BenchmarkScanner(b *testing.B) {
+	data := readTestData(b)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Split(NewSplitter().Splitter())
+		for scanner.Scan() {
+			p := Packet{}
+			if err := p.Decode(scanner.Bytes()); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package egts
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidField is wrapped by every violation reported by Validate methods.
+var ErrInvalidField = errors.New("invalid field")
+
+// validator is implemented by the structures able to check their fields against GOST constraints.
+type validator interface {
+	Validate() error
+}
+
+// violations collects the violations of the field constraints.
+type violations []error
+
+// add reports the violation of the field.
+func (v *violations) add(field string, value interface{}, reason string) {
+	*v = append(*v, fmt.Errorf("%s=%v %s: %w", field, value, reason, ErrInvalidField))
+}
+
+// flag checks that the value is a bit string of the given width.
+func (v *violations) flag(field, value string, width int) {
+	if len(value) != width {
+		v.add(field, fmt.Sprintf("%q", value), fmt.Sprintf("must be %d bit(s)", width))
+		return
+	}
+	for _, c := range value {
+		if c != '0' && c != '1' {
+			v.add(field, fmt.Sprintf("%q", value), "must contain only 0 and 1")
+			return
+		}
+	}
+}
+
+// max checks that the value does not exceed the limit.
+func (v *violations) max(field string, value, limit uint64) {
+	if value > limit {
+		v.add(field, value, fmt.Sprintf("exceeds %d", limit))
+	}
+}
+
+// maxLen checks that the length of the value does not exceed the limit.
+func (v *violations) maxLen(field string, length, limit int) {
+	if length > limit {
+		v.add(field+" length", length, fmt.Sprintf("exceeds %d", limit))
+	}
+}
+
+// required checks that the required field is set.
+func (v *violations) required(field string, set bool) {
+	if !set {
+		v.add(field, nil, "is required")
+	}
+}
+
+// resultCode checks that the code is one of the processing result codes: EGTS_PC_OK, EGTS_PC_IN_PROGRESS
+// or the error codes from EGTS_PC_UNS_PROTOCOL to EGTS_PC_TEST_FAILED.
+func (v *violations) resultCode(field string, code uint8) {
+	if code > EgtsPcInProgress && (code < EgtsPcUnsProtocol || code > EgtsPcTestFailed) {
+		v.add(field, code, "is not a result code")
+	}
+}
+
+// nested adds the violations of the nested structure, each one prefixed with the structure name.
+func (v *violations) nested(name string, err error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok { //nolint:errorlint
+		for _, e := range joined.Unwrap() {
+			v.nested(name, e)
+		}
+		return
+	}
+	if err != nil {
+		*v = append(*v, fmt.Errorf("%s: %w", name, err))
+	}
+}
+
+// validate runs the validation of the data if it supports one.
+func (v *violations) validate(name string, data interface{}) {
+	if d, ok := data.(validator); ok {
+		v.nested(name, d.Validate())
+	}
+}
+
+// err joins the violations, it is nil if there are none.
+func (v violations) err() error {
+	return errors.Join(v...)
+}
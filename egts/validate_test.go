@@ -0,0 +1,107 @@
+package egts
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPacket_Validate(t *testing.T) {
+	p := Packet{}
+	if !assert.NoError(t, p.Decode(egtsPkgPosDataBytes)) {
+		return
+	}
+	assert.NoError(t, p.Validate())
+
+	p.Prefix = "01"
+	p.Route = "1"
+	sdr := &(*p.ServicesFrameData.(*ServiceDataSet))[0]
+	sdr.RecordLength++
+	sdr.RecordDataSet[0].SubrecordLength++
+	sdr.RecordDataSet[0].SubrecordData.(*SrPosData).Speed = (1<<14-1)/10 + 1
+
+	err := p.Validate()
+	assert.True(t, errors.Is(err, ErrInvalidField))
+	for _, field := range []string{"PRF=", "HL=11", "SFRD: SDR[0]: RL=25", "SFRD: SDR[0]: RD: RD[0].SRL=22", "SFRD: SDR[0]: RD: RD[0]: SPD=1639"} {
+		assert.True(t, strings.Contains(err.Error(), field), "no violation of %s in %q", field, err)
+	}
+
+	_, err = p.Encode(func(o *Options) { o.Validate = true })
+	assert.True(t, errors.Is(err, ErrInvalidField))
+}
+
+func TestPacket_ValidateFrameData(t *testing.T) {
+	p := NewBatchEncoder(0).Header
+	p.PacketType = PtResponsePacket
+	p.ServicesFrameData = &ServiceDataSet{}
+	assert.True(t, errors.Is(p.Validate(), ErrInvalidField))
+
+	p.ServicesFrameData = &PtResponse{ResponsePacketID: 1}
+	assert.NoError(t, p.Validate())
+}
+
+func TestRecordDataSet_ValidateType(t *testing.T) {
+	rds := RecordDataSet{
+		RecordData{SubrecordType: SrType20, SubrecordData: &testEgtsSrStateData},
+		RecordData{SubrecordType: SrPosDataType, SubrecordData: &testEgtsSrStateData},
+		RecordData{SubrecordType: 99, SubrecordData: &SrRaw{Data: []byte{1}}},
+	}
+	err := rds.Validate()
+	if assert.Error(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "RD[1].SRT=16"))
+		assert.False(t, strings.Contains(err.Error(), "RD[0]"))
+		assert.False(t, strings.Contains(err.Error(), "RD[2]"))
+	}
+}
+
+func TestSubrecords_ValidateResults(t *testing.T) {
+	for _, data := range []validator{
+		&SrResponse{RecordStatus: EgtsPcOk},
+		&SrResponse{RecordStatus: EgtsPcInProgress},
+		&SrResultCode{ResultCode: EgtsPcUnsProtocol},
+		&SrResultCode{ResultCode: EgtsPcTestFailed},
+		&StorageRecord{RecordNumber: proto.Uint32(1), TimeStamp: proto.Uint32(1), StatusFlags: proto.Uint32(0)},
+	} {
+		assert.NoError(t, data.Validate(), "%T", data)
+	}
+
+	// the record set skips no subrecord
+	rds := RecordDataSet{RecordData{SubrecordType: SrResultCodeType, SubrecordData: &SrResultCode{ResultCode: 2}}}
+	assert.True(t, errors.Is(rds.Validate(), ErrInvalidField))
+}
+
+func TestSubrecords_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  validator
+		field string
+	}{
+		{"term identity", &SrTermIdentity{MNE: "0", BSE: "0", NIDE: "0", SSRA: "0", LNGCE: "0", IMSIE: "0",
+			IMEIE: "1", HDIDE: "0", IMEI: "123"}, "IMEI length"},
+		{"counters", &SrAbsCntrData{CounterValue: 1 << 24}, "CNV"},
+		{"liquid level", &SrLiquidLevelSensor{LiquidLevelSensorErrorFlag: "0", LiquidLevelSensorValueUnit: "1",
+			RawDataFlag: "0"}, "LLSVU"},
+		{"accel", &SrAccelData{StructuresAmount: 2, AbsoluteTime: timeOffset,
+			AccelDataStructures: []AccelDataStructure{{}}}, "SA"},
+		{"command", &SrCommandData{CommandType: 0, ACFE: "0", CHSFE: "0"}, "CT"},
+		{"module", &SrModuleData{SerialNumber: strings.Repeat("1", 33)}, "SRN length"},
+		{"response", &SrResponse{ConfirmedRecordNumber: 1, RecordStatus: 2}, "RST=2"},
+		{"response error code", &SrResponse{RecordStatus: EgtsPcTestFailed + 1}, "RST=165"},
+		{"result code", &SrResultCode{ResultCode: 127}, "RCD=127"},
+		{"storage record", &StorageRecord{TimeStamp: proto.Uint32(1), StatusFlags: proto.Uint32(0)},
+			"record_number"},
+		{"storage record time", &StorageRecord{RecordNumber: proto.Uint32(1), StatusFlags: proto.Uint32(0)},
+			"time_stamp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.data.Validate()
+			if assert.True(t, errors.Is(err, ErrInvalidField)) {
+				assert.True(t, strings.HasPrefix(err.Error(), tt.field), err.Error())
+			}
+		})
+	}
+}
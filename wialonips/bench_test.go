@@ -0,0 +1,63 @@
+package wialonips
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"testing"
+)
+
+var benchFiles = []string{"0001", "0002", "0003", "0004", "0005"}
+
+// readFrames reads the packets of the test data file.
+func readFrames(tb testing.TB, name string) ([][]byte, int) {
+	tb.Helper()
+	data, err := os.ReadFile("./testdata/" + name + ".data")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	var frames [][]byte
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Split(NewSplitter().Splitter())
+	for scanner.Scan() {
+		frames = append(frames, append([]byte(nil), scanner.Bytes()...))
+	}
+	return frames, len(data)
+}
+
+func BenchmarkPacket_Decode(b *testing.B) {
+	for _, name := range benchFiles {
+		frames, size := readFrames(b, name)
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var (
+					ver  Version
+					imei string
+				)
+				for _, frame := range frames {
+					p := NewPacket(ver, imei)
+					_ = p.Decode(frame)
+					ver, imei = p.Version, p.IMEI
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDecoder_Decode(b *testing.B) {
+	for _, name := range benchFiles {
+		frames, size := readFrames(b, name)
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				d := NewDecoder(UnknownVersion, "")
+				for _, frame := range frames {
+					_, _ = d.Decode(frame)
+				}
+			}
+		})
+	}
+}
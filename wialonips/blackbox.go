@@ -2,6 +2,7 @@ package wialonips
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"strconv"
 )
@@ -9,56 +10,208 @@ import (
 var _ Message = (*BlackBoxMessage)(nil)
 
 // BlackBoxMessage is a WialonIPS black box message.
+// Every record of the packet is decoded on its own, the valid records are kept
+// and the errors of the others are reported in Errors.
 type BlackBoxMessage struct {
 	ShortenedMessages []ShortenedDataMessage
 	DataMessages      []DataMessage
+	// Order is the order of the accepted records of the packet having both shortened and data records.
+	// Without it the shortened messages are followed by the data messages.
+	Order []BlackBoxRecord
+	// Errors are the errors of the records which are not accepted.
+	Errors []RecordError
 	message
 }
 
+// BlackBoxRecord refers to the accepted record of the black box packet.
+type BlackBoxRecord struct {
+	// Shortened is true for the record of ShortenedMessages, false for DataMessages.
+	Shortened bool
+	// Index is the index of the message in ShortenedMessages or DataMessages.
+	Index int
+}
+
+// RecordError is the error of the black box record.
+type RecordError struct {
+	// Index is the index of the record in the packet, starting from 0.
+	Index int
+	Err   error
+}
+
+// Error returns the error message.
+func (e RecordError) Error() string {
+	return fmt.Sprintf("record %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the error of the record.
+func (e RecordError) Unwrap() error {
+	return e.Err
+}
+
 // Decode decodes a WialonIPS message.
 // The black box packet is used to transmit messages for the past period.
 // The maximum number of messages that can be transmitted in one packet is 5000. The packet looks as follows:
 // #B#Date;Time;Lat1;Lat2;Lon1;Lon2;Speed;Course;Alt;Sats|Date;Time;Lat1;Lat 2;Lon1;Lon2;Speed;Course;Alt;Sats|Date
 // ;Time;Lat1;Lat2;Lon1;Lon2;Speed; Course;Alt;Sats|CRC16\r\n.
+// The records may be shortened or not in the same packet, their order is kept in Order then.
+// It fails only when no record is accepted.
 func (bb *BlackBoxMessage) Decode(data []byte) error {
-	const shortDataLen = 10
-	bytesSet := bytes.Split(data, blackBoxDelimiter)
+	const (
+		shortDataLen = 10
+		dataLen      = 16
+	)
 	if bb.ver == V2_0 {
 		bb.err = validateCRC(data, blackBoxDelimiter)
 		if bb.err != nil {
 			return bb.err
 		}
-		bytesSet = bytesSet[0 : len(bytesSet)-1]
+		i := bytes.LastIndex(data, blackBoxDelimiter)
+		if i < 0 {
+			i = 0 // no record before CRC16
+		}
+		data = data[:i]
 	}
 
-	probe := bytes.Count(bytesSet[0], fieldsDelimiter)
-	isShortened := probe == shortDataLen
-	if isShortened {
-		bb.ShortenedMessages = make([]ShortenedDataMessage, 0, len(bytesSet))
-		for _, d := range bytesSet {
-			msg := ShortenedDataMessage{}
-			bb.err = msg.Decode(d)
-			if bb.err != nil {
-				return bb.err
+	// the records are decoded into the messages of the previous decoding to reuse them.
+	bb.err = nil
+	bb.ShortenedMessages, bb.DataMessages, bb.Errors = bb.ShortenedMessages[:0], bb.DataMessages[:0], bb.Errors[:0]
+	bb.Order = bb.Order[:0]
+	records := newFieldScanner(data, blackBoxDelimiter)
+	for i, d := 0, records.next(); d != nil; i, d = i+1, records.next() {
+		var err error
+		switch countFields(d, fieldsDelimiter) {
+		case shortDataLen:
+			bb.ShortenedMessages = grow(bb.ShortenedMessages)
+			msg := &bb.ShortenedMessages[len(bb.ShortenedMessages)-1]
+			if err = msg.Decode(d); err != nil {
+				bb.ShortenedMessages = bb.ShortenedMessages[:len(bb.ShortenedMessages)-1]
+			} else {
+				bb.addRecord(true)
+			}
+		case dataLen:
+			bb.DataMessages = grow(bb.DataMessages)
+			msg := &bb.DataMessages[len(bb.DataMessages)-1]
+			if err = msg.Decode(d); err != nil {
+				bb.DataMessages = bb.DataMessages[:len(bb.DataMessages)-1]
+			} else {
+				bb.addRecord(false)
+			}
+		default:
+			err = ErrWialonIPSInvalidBBMessage
+		}
+		if err != nil {
+			bb.Errors = append(bb.Errors, RecordError{Index: i, Err: err})
+		}
+	}
+	if len(bb.Order) == 0 {
+		bb.Order = nil
+	}
+
+	if len(bb.ShortenedMessages)+len(bb.DataMessages) == 0 {
+		errs := make([]error, 0, len(bb.Errors)+1)
+		errs = append(errs, ErrWialonIPSInvalidBBMessage)
+		for _, e := range bb.Errors {
+			errs = append(errs, e)
+		}
+		bb.err = fmt.Errorf("no record accepted: %w", errors.Join(errs...))
+		return bb.err
+	}
+	return nil
+}
+
+// grow extends the slice by one element keeping the element beyond the length for reuse.
+func grow[T any](s []T) []T {
+	if len(s) < cap(s) {
+		return s[:len(s)+1]
+	}
+	var zero T
+	return append(s, zero)
+}
+
+// addRecord adds the last accepted record to Order when the packet turns out to be mixed.
+func (bb *BlackBoxMessage) addRecord(shortened bool) {
+	if len(bb.Order) == 0 {
+		if shortened && len(bb.DataMessages) == 0 || !shortened && len(bb.ShortenedMessages) == 0 {
+			return // the records are of one kind so far
+		}
+		// the previous records are of the other kind.
+		n := len(bb.DataMessages)
+		if !shortened {
+			n = len(bb.ShortenedMessages)
+		}
+		for i := 0; i < n; i++ {
+			bb.Order = append(bb.Order, BlackBoxRecord{Shortened: !shortened, Index: i})
+		}
+	}
+	index := len(bb.DataMessages) - 1
+	if shortened {
+		index = len(bb.ShortenedMessages) - 1
+	}
+	bb.Order = append(bb.Order, BlackBoxRecord{Shortened: shortened, Index: index})
+}
+
+// records calls f for every record in the order of the packet, either sd or d is nil.
+func (bb *BlackBoxMessage) records(f func(sd *ShortenedDataMessage, d *DataMessage) error) error {
+	if len(bb.Order) == 0 {
+		for i := range bb.ShortenedMessages {
+			if err := f(&bb.ShortenedMessages[i], nil); err != nil {
+				return err
+			}
+		}
+		for i := range bb.DataMessages {
+			if err := f(nil, &bb.DataMessages[i]); err != nil {
+				return err
 			}
-			bb.ShortenedMessages = append(bb.ShortenedMessages, msg)
 		}
 		return nil
 	}
 
-	bb.DataMessages = make([]DataMessage, 0, len(bytesSet))
-	for _, d := range bytesSet {
-		msg := DataMessage{}
-		bb.err = msg.Decode(d)
-		if bb.err != nil {
-			return bb.err
+	for _, r := range bb.Order {
+		var err error
+		switch {
+		case r.Shortened && r.Index >= 0 && r.Index < len(bb.ShortenedMessages):
+			err = f(&bb.ShortenedMessages[r.Index], nil)
+		case !r.Shortened && r.Index >= 0 && r.Index < len(bb.DataMessages):
+			err = f(nil, &bb.DataMessages[r.Index])
+		default:
+			err = fmt.Errorf("record %+v out of messages: %w", r, ErrWialonIPSInvalidBBMessage)
+		}
+		if err != nil {
+			return err
 		}
-		bb.DataMessages = append(bb.DataMessages, msg)
 	}
 	return nil
 }
 
-// Response returns a WialonIPS response message.
+// Encode encodes a WialonIPS message. The records are placed in Order if it is set,
+// otherwise the shortened messages are placed before the data messages.
+func (bb *BlackBoxMessage) Encode() ([]byte, error) {
+	var (
+		buf []byte
+		n   int
+	)
+	err := bb.records(func(sd *ShortenedDataMessage, d *DataMessage) error {
+		if n > 0 {
+			buf = append(buf, blackBoxDelimiter...)
+		}
+		n++
+		if sd != nil {
+			buf = sd.appendFields(buf)
+			return nil
+		}
+		var err error
+		if buf, err = d.appendFields(buf); err != nil {
+			return fmt.Errorf("record %d: %w", n-1, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bb.seal(buf, blackBoxDelimiter), nil
+}
+
+// Response returns a WialonIPS response message: the number of the accepted records.
 func (bb *BlackBoxMessage) Response() []byte {
 	return []byte(fmt.Sprintf(responseTemplate, BlackBoxPacket,
 		strconv.Itoa(len(bb.DataMessages)+len(bb.ShortenedMessages))))
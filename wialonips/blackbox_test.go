@@ -0,0 +1,83 @@
+package wialonips
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlackBoxMessage_DecodePartial(t *testing.T) {
+	records := []string{
+		"240123;141801;5547.7850;N;03734.7740;E;12.5;184;188;12",
+		"240123;141802;5547.7850;X;03734.7740;E;12.5;184;188;12",
+		"240123;141803;5547.7850;N;03734.7740;E;12.5;184;188;12;0.9;5;NA;12.3,NA,4;NA;odo:2:0",
+		"240123;141804",
+		"240123;141805;5547.7850;N;03734.7740;E;12.5;184;188;12;0.9;5;NA;12.3,NA,4;NA;odo:4:0",
+	}
+	data := strings.Join(records, "|")
+
+	for _, v := range []Version{V1_1, V2_0} {
+		t.Run(v.String(), func(t *testing.T) {
+			msg := []byte(data)
+			if v == V2_0 {
+				msg = appendCRC(msg, blackBoxDelimiter)
+			}
+			bb := &BlackBoxMessage{message: message{ver: v}}
+			require.NoError(t, bb.Decode(msg))
+
+			require.Len(t, bb.ShortenedMessages, 1)
+			assert.Equal(t, 1, bb.ShortenedMessages[0].RegisteredAt.Second())
+			require.Len(t, bb.DataMessages, 1)
+			assert.Equal(t, 3, bb.DataMessages[0].RegisteredAt.Second())
+			assert.Equal(t, []BlackBoxRecord{{Shortened: true, Index: 0}, {Index: 0}}, bb.Order)
+
+			require.Len(t, bb.Errors, 3)
+			assert.Equal(t, 1, bb.Errors[0].Index)
+			assert.True(t, errors.Is(bb.Errors[0], ErrWialonIPSParsePoint), "got error %v", bb.Errors[0])
+			assert.Equal(t, 3, bb.Errors[1].Index)
+			assert.True(t, errors.Is(bb.Errors[1], ErrWialonIPSInvalidBBMessage), "got error %v", bb.Errors[1])
+			assert.Equal(t, 4, bb.Errors[2].Index)
+			assert.True(t, errors.Is(bb.Errors[2], ErrWialonIPSParseAttribute), "got error %v", bb.Errors[2])
+
+			assert.Equal(t, "#AB#2\r\n", string(bb.Response()))
+		})
+	}
+}
+
+func TestBlackBoxMessage_DecodeNoneAccepted(t *testing.T) {
+	p := NewPacket(V1_1, testIMEI)
+	err := p.Decode([]byte("#B#240123;141804|NA\r\n"))
+	assert.True(t, errors.Is(err, ErrWialonIPSInvalidBBMessage), "got error %v", err)
+	assert.Equal(t, "#AB#0\r\n", string(response(p, err)))
+
+	p = NewPacket(V2_0, testIMEI)
+	err = p.Decode([]byte("#B#240123;141801;5547.7850;N;03734.7740;E;12.5;184;188;12|0000\r\n"))
+	assert.True(t, errors.Is(err, ErrWialonIPSCRC16Validation), "got error %v", err)
+	assert.Equal(t, "#AB#0\r\n", string(response(p, err)))
+}
+
+func TestBlackBoxMessage_EncodeOrder(t *testing.T) {
+	bb := &BlackBoxMessage{
+		ShortenedMessages: []ShortenedDataMessage{testShortenedData(2)},
+		DataMessages:      []DataMessage{testData(1), testData(3)},
+		Order:             []BlackBoxRecord{{Index: 0}, {Shortened: true, Index: 0}, {Index: 1}},
+	}
+	bb.setHeader(V1_1, testIMEI)
+	data, err := bb.Encode()
+	require.NoError(t, err)
+
+	got := &BlackBoxMessage{message: message{ver: V1_1}}
+	require.NoError(t, got.Decode(bytes.TrimPrefix(bytes.TrimSuffix(data, lineBreak), []byte("#B#"))))
+	assert.Equal(t, bb.Order, got.Order)
+	for i, pos := range got.Positions() {
+		assert.Equal(t, i+1, pos.DeviceTime.Second())
+	}
+
+	bb.Order = []BlackBoxRecord{{Index: 2}}
+	_, err = bb.Encode()
+	assert.True(t, errors.Is(err, ErrWialonIPSInvalidBBMessage), "got error %v", err)
+}
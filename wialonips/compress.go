@@ -0,0 +1,72 @@
+package wialonips
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	// compressedHeaderLen is the length of the compressed packet header: 0xFF mark and the length of the data.
+	compressedHeaderLen = 3
+	// maxInflatedLength limits the decompressed packet, the black box of 5000 messages fits into it.
+	maxInflatedLength = 4 << 20
+)
+
+// compressedFrameLen returns the length of the compressed packet starting the data
+// or false if there is not enough data to know it.
+// The compressed packet looks as follows:
+// 0xFF, the length of the compressed data (uint16, little endian), the data compressed by zlib.
+func compressedFrameLen(data []byte) (int, bool) {
+	if len(data) < compressedHeaderLen {
+		return 0, false
+	}
+	return compressedHeaderLen + int(binary.LittleEndian.Uint16(data[1:compressedHeaderLen])), true
+}
+
+// deflate compresses the text packet into the compressed packet.
+func deflate(data []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, compressedHeaderLen, compressedHeaderLen+len(data)))
+	zw := zlib.NewWriter(buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrWialonIPSDecompression, err.Error())
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrWialonIPSDecompression, err.Error())
+	}
+
+	result := buf.Bytes()
+	n := len(result) - compressedHeaderLen
+	if n > math.MaxUint16 {
+		return nil, fmt.Errorf("compressed packet exceeds %d bytes: %w", math.MaxUint16, ErrWialonIPSDecompression)
+	}
+	result[0] = compressionMark
+	binary.LittleEndian.PutUint16(result[1:compressedHeaderLen], uint16(n))
+	return result, nil
+}
+
+// inflate decompresses the compressed packet into the text packet it contains.
+func inflate(data []byte) ([]byte, error) {
+	n, ok := compressedFrameLen(data)
+	if !ok || len(data) < n {
+		return nil, fmt.Errorf("compressed packet is truncated: %w", ErrWialonIPSDecompression)
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(data[compressedHeaderLen:n]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrWialonIPSDecompression, err.Error())
+	}
+	defer zr.Close()
+
+	result, err := io.ReadAll(io.LimitReader(zr, maxInflatedLength+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrWialonIPSDecompression, err.Error())
+	}
+	if len(result) > maxInflatedLength {
+		return nil, fmt.Errorf("decompressed packet exceeds %d bytes: %w", maxInflatedLength, ErrWialonIPSDecompression)
+	}
+	return result, nil
+}
@@ -0,0 +1,88 @@
+package wialonips
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode_Compressed(t *testing.T) {
+	f, err := os.Open("./testdata/0005.data")
+	require.NoError(t, err)
+	defer f.Close()
+
+	wantTypes := []PacketType{LoginPacket, DataPacket, ShortenedDataPacket, BlackBoxPacket}
+	wantCompressed := []bool{false, true, false, true}
+
+	var (
+		packets []Packet
+		ver     Version
+		imei    string
+	)
+	scanner := bufio.NewScanner(f)
+	scanner.Split(NewSplitter().Splitter())
+	for scanner.Scan() {
+		p := NewPacket(ver, imei)
+		require.NoError(t, p.Decode(scanner.Bytes()))
+		ver, imei = p.Version, p.IMEI
+		packets = append(packets, p)
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, packets, len(wantTypes))
+
+	for i, p := range packets {
+		assert.Equal(t, wantTypes[i], p.Type)
+		assert.Equal(t, wantCompressed[i], p.Compressed)
+		assert.Equal(t, V2_0, p.Version)
+		assert.Equal(t, "866795037163746", p.IMEI)
+	}
+
+	d, ok := packets[1].Message.(*DataMessage)
+	if assert.True(t, ok) {
+		assert.Equal(t, 129390.078125, d.Attributes["mileage"])
+		assert.Equal(t, "#AD#1\r\n", string(d.Response()))
+	}
+
+	bb, ok := packets[3].Message.(*BlackBoxMessage)
+	if assert.True(t, ok) && assert.Len(t, bb.DataMessages, 3) {
+		for i, m := range bb.DataMessages {
+			assert.Equal(t, int64(10+i), m.Attributes["gsm"])
+		}
+		assert.Equal(t, "#AB#3\r\n", string(bb.Response()))
+	}
+}
+
+func TestDecode_CompressedErrors(t *testing.T) {
+	// #P#0000\r\n compressed
+	ping, err := hex.DecodeString("ff0f00789c530e503600025e2e00083d016e")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "header only", data: ping[:2]},
+		{name: "truncated", data: ping[:10]},
+		{name: "not zlib", data: []byte{0xFF, 0x03, 0x00, '#', 'P', '#'}},
+		{name: "broken stream", data: append(append([]byte(nil), ping[:8]...), 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPacket(V2_0, "866795037163746")
+			err := p.Decode(tt.data)
+			assert.True(t, errors.Is(err, ErrWialonIPSDecompression), "got error %v", err)
+		})
+	}
+
+	p := NewPacket(V2_0, "866795037163746")
+	if assert.NoError(t, p.Decode(ping)) {
+		assert.Equal(t, PingPacket, p.Type)
+		assert.True(t, p.Compressed)
+	}
+}
@@ -1,7 +1,6 @@
 package wialonips
 
 import (
-	"bytes"
 	"fmt"
 
 	"github.com/gotrackery/protocol/common"
@@ -28,8 +27,7 @@ type DataMessage struct {
 
 // Decode decodes a WialonIPS message.
 func (d *DataMessage) Decode(data []byte) error {
-	bytesSet := bytes.Split(data, fieldsDelimiter)
-	length := len(bytesSet)
+	length := countFields(data, fieldsDelimiter)
 	if (length != 16 && d.ver == V1_1) || (length != 17 && d.ver == V2_0) {
 		d.err = ErrWialonIPSInvalidDataMessage // -1
 		return d.err
@@ -42,20 +40,39 @@ func (d *DataMessage) Decode(data []byte) error {
 		}
 	}
 
-	d.RegisteredAt, d.Point, d.Speed, d.Course, d.Altitude, d.Sat, d.err = parseBaseFields(bytesSet[0:10])
+	fields := newFieldScanner(data, fieldsDelimiter)
+	d.RegisteredAt, d.Point, d.Speed, d.Course, d.Altitude, d.Sat, d.err = parseBaseFields(&fields)
 	if d.err != nil {
 		return d.err
 	}
 
-	d.HDOP, d.Inputs, d.Outputs, d.ADC, d.IButton, d.err = parseAdditionalFields(bytesSet[10:15])
+	d.HDOP, d.Inputs, d.Outputs, d.ADC, d.IButton, d.err = parseAdditionalFields(&fields, d.ADC)
 	if d.err != nil {
 		return d.err
 	}
 
-	d.Attributes, d.err = parseAttrs(bytesSet[15])
+	d.Attributes, d.err = parseAttrs(fields.next(), d.Attributes)
 	return d.err
 }
 
+// Encode encodes a WialonIPS message.
+func (d *DataMessage) Encode() ([]byte, error) {
+	buf, err := d.appendFields(nil)
+	if err != nil {
+		return nil, err
+	}
+	return d.seal(buf, fieldsDelimiter), nil
+}
+
+// appendFields appends the fields of the message without CRC16.
+func (d *DataMessage) appendFields(buf []byte) ([]byte, error) {
+	buf = d.ShortenedDataMessage.appendFields(buf)
+	buf = append(buf, fieldsDelimiter...)
+	buf = appendAdditionalFields(buf, d.HDOP, d.Inputs, d.Outputs, d.ADC, d.IButton)
+	buf = append(buf, fieldsDelimiter...)
+	return appendAttrs(buf, d.Attributes)
+}
+
 // Response returns a WialonIPS response message.
 func (d *DataMessage) Response() []byte {
 	return []byte(fmt.Sprintf(responseTemplate, DataPacket, MapErrToRespCode(DataPacket, d.err)))
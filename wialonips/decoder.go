@@ -0,0 +1,35 @@
+package wialonips
+
+// Decoder decodes the packets of one connection as Packet.Decode does, but reuses the packet and its messages
+// between the calls to save allocations. The version and IMEI of the login are kept for the following packets.
+// The packet returned and its message are valid until the next call of Decode. It is not safe for concurrent use.
+type Decoder struct {
+	packet   Packet
+	messages map[PacketType]Message
+}
+
+// NewDecoder creates a new Decoder of the packets of the device, the version and IMEI are set by the login packet
+// when they are not known.
+func NewDecoder(v Version, imei string) *Decoder {
+	return &Decoder{
+		packet:   NewPacket(v, imei),
+		messages: make(map[PacketType]Message),
+	}
+}
+
+// Decode decodes the packet. The packet is returned on error too to answer it.
+func (d *Decoder) Decode(data []byte) (*Packet, error) {
+	d.packet = Packet{Version: d.packet.Version, IMEI: d.packet.IMEI}
+	err := d.packet.decode(data, d.message)
+	return &d.packet, err
+}
+
+// message returns the message of the previous packet of the type.
+func (d *Decoder) message(t PacketType) Message {
+	m, ok := d.messages[t]
+	if !ok {
+		m = newMessage(t)
+		d.messages[t] = m
+	}
+	return m
+}
@@ -0,0 +1,35 @@
+package wialonips
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder_Decode(t *testing.T) {
+	for _, name := range benchFiles {
+		t.Run(name, func(t *testing.T) {
+			frames, _ := readFrames(t, name)
+			require.NotEmpty(t, frames)
+
+			d := NewDecoder(UnknownVersion, "")
+			want := NewPacket(UnknownVersion, "")
+			for _, frame := range frames {
+				want = NewPacket(want.Version, want.IMEI)
+				wantErr := want.Decode(frame)
+
+				got, err := d.Decode(frame)
+				assert.Equal(t, wantErr, err)
+				assert.Equal(t, want.Type, got.Type)
+				assert.Equal(t, want.Version, got.Version)
+				assert.Equal(t, want.IMEI, got.IMEI)
+				assert.Equal(t, want.Compressed, got.Compressed)
+				if want.Message != nil {
+					assert.Equal(t, want.Message.Response(), got.Message.Response())
+					assert.Equal(t, want.Positions(), got.Positions())
+				}
+			}
+		})
+	}
+}
@@ -7,6 +7,10 @@ var (
 	ErrWialonIPSInvalidLoginMessage   = errors.New("invalid login #L# message")
 	ErrWialonIPSInvalidSDMessage      = errors.New("invalid shortened data #SD# message")
 	ErrWialonIPSInvalidDataMessage    = errors.New("invalid data #D# message")
+	ErrWialonIPSInvalidBBMessage      = errors.New("invalid black box #B# message")
+	ErrWialonIPSInvalidPingMessage    = errors.New("invalid ping #P# message")
+	ErrWialonIPSInvalidImageMessage   = errors.New("invalid image #I# message")
+	ErrWialonIPSImageData             = errors.New("invalid image data")
 	ErrWialonIPSParseDateTime         = errors.New("invalid date or time data")
 	ErrWialonIPSParsePoint            = errors.New("invalid coordinates data")
 	ErrWialonIPSParseSCA              = errors.New("invalid speed, course or altitude data")
@@ -16,6 +20,10 @@ var (
 	ErrWialonIPSParseADC              = errors.New("invalid adc data")
 	ErrWialonIPSParseAttribute        = errors.New("invalid parameter data")
 	ErrWialonIPSCRC16Validation       = errors.New("CRC16 validation not passed")
+	ErrWialonIPSDecompression         = errors.New("invalid compressed packet")
+	ErrWialonIPSInvalidDatagram       = errors.New("invalid UDP datagram")
+	ErrWialonIPSInvalidPassword       = errors.New("invalid password")
+	ErrWialonIPSNotLoggedIn           = errors.New("device is not logged in")
 )
 
 // MapErrToRespCode maps errors to WialonIPS respond codes.
@@ -27,8 +35,8 @@ func MapErrToRespCode(p PacketType, err error) string {
 		return "1"
 	case p == LoginPacket && errors.Is(err, ErrWialonIPSInvalidLoginMessage):
 		return "0"
-	// case p == LoginPacket && errors.Is(err, ToDo password verification):
-	// 	return "01"
+	case p == LoginPacket && errors.Is(err, ErrWialonIPSInvalidPassword):
+		return "01"
 	case p == LoginPacket && errors.Is(err, ErrWialonIPSCRC16Validation):
 		return "10"
 
@@ -67,6 +75,26 @@ func MapErrToRespCode(p PacketType, err error) string {
 		return "15"
 	case p == DataPacket && errors.Is(err, ErrWialonIPSCRC16Validation):
 		return "16"
+
+	case p == BlackBoxPacket:
+		return "0" // no message is accepted, the decoded packet answers the number of its messages.
+
+	case p == PingPacket:
+		return "" // the ping answer has no result code.
+
+	case p == ImagePacket && err == nil:
+		return "1"
+	case p == ImagePacket && errors.Is(err, ErrWialonIPSCRC16Validation):
+		return "01"
+	case p == ImagePacket:
+		return "0"
+
+	case p == TextPacket && err == nil:
+		return "1"
+	case p == TextPacket && errors.Is(err, ErrWialonIPSCRC16Validation):
+		return "01"
+	case p == TextPacket:
+		return "0"
 	}
 
 	return "-1"
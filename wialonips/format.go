@@ -0,0 +1,165 @@
+package wialonips
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gotrackery/protocol/common"
+	"github.com/sigurn/crc16"
+	"gopkg.in/guregu/null.v4"
+)
+
+const (
+	crcFormat      = "%04X"
+	latFormat      = "%09.4f"  // ddmm.mmmm
+	lonFormat      = "%010.4f" // dddmm.mmmm
+	dateTimeFormat = "020106;150405"
+)
+
+// appendBaseFields appends Date;Time;Lat1;Lat2;Lon1;Lon2;Speed;Course;Alt;Sats fields.
+func appendBaseFields(buf []byte, t time.Time, p common.PointWGS84, speed null.Float, course null.Int,
+	alt null.Float, sat null.Int) []byte {
+	buf = appendTime(buf, t)
+	buf = append(buf, fieldsDelimiter...)
+	buf = appendPoint(buf, p)
+	buf = append(buf, fieldsDelimiter...)
+	buf = appendFloat(buf, speed)
+	buf = append(buf, fieldsDelimiter...)
+	buf = appendInt(buf, course)
+	buf = append(buf, fieldsDelimiter...)
+	buf = appendFloat(buf, alt)
+	buf = append(buf, fieldsDelimiter...)
+	return appendInt(buf, sat)
+}
+
+// appendAdditionalFields appends HDOP;Inputs;Outputs;ADC;Ibutton fields.
+func appendAdditionalFields(buf []byte, hdop null.Float, inputs, outputs null.Int, adc []null.Float,
+	ibutton null.String) []byte {
+	buf = appendFloat(buf, hdop)
+	buf = append(buf, fieldsDelimiter...)
+	buf = appendInt(buf, inputs)
+	buf = append(buf, fieldsDelimiter...)
+	buf = appendInt(buf, outputs)
+	buf = append(buf, fieldsDelimiter...)
+	for i, v := range adc {
+		if i > 0 {
+			buf = append(buf, analogDelimiter...)
+		}
+		buf = appendFloat(buf, v)
+	}
+	buf = append(buf, fieldsDelimiter...)
+	return appendString(buf, ibutton)
+}
+
+// appendAttrs appends the parameters as Name:Type:Value separated by commas, sorted by name.
+func appendAttrs(buf []byte, a common.Attributes) ([]byte, error) {
+	if len(a) == 0 {
+		return append(buf, na...), nil
+	}
+
+	keys := make([]string, 0, len(a))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for i, k := range keys {
+		if i > 0 {
+			buf = append(buf, valuesDelimiter...)
+		}
+		t, v, err := formatValue(a[k])
+		if err == nil {
+			err = checkText(k)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("format attribute %s: %w", k, errors.Join(err, ErrWialonIPSParseAttribute))
+		}
+		buf = append(buf, k...)
+		buf = append(buf, paramsDelimiter...)
+		buf = append(buf, t)
+		buf = append(buf, paramsDelimiter...)
+		buf = append(buf, v...)
+	}
+	return buf, nil
+}
+
+// formatValue returns the type and the text of the parameter value.
+func formatValue(val interface{}) (byte, string, error) {
+	switch v := val.(type) {
+	case int:
+		return intType, strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return intType, strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return intType, strconv.FormatInt(v, 10), nil
+	case uint32:
+		return intType, strconv.FormatUint(uint64(v), 10), nil
+	case float32:
+		return floatType, strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return floatType, strconv.FormatFloat(v, 'f', -1, 64), nil
+	case string:
+		return stringType, v, checkText(v)
+	}
+	return 0, "", fmt.Errorf("invalid parameter value type %T", val) //nolint:goerr113
+}
+
+// checkText returns error if the text contains the delimiters of the packet.
+func checkText(s string) error {
+	if i := bytes.IndexAny([]byte(s), ";,:|#\r\n"); i >= 0 {
+		return fmt.Errorf("invalid character %q in %q", s[i], s) //nolint:goerr113
+	}
+	return nil
+}
+
+func appendTime(buf []byte, t time.Time) []byte {
+	if t.IsZero() {
+		return append(append(append(buf, na...), fieldsDelimiter...), na...)
+	}
+	return t.UTC().AppendFormat(buf, dateTimeFormat)
+}
+
+func appendPoint(buf []byte, p common.PointWGS84) []byte {
+	if !p.Valid {
+		return append(buf, "NA;NA;NA;NA"...)
+	}
+	latC, lonC := p.Lat.Cardinal, p.Lon.Cardinal
+	if latC == "" {
+		latC = common.North
+	}
+	if lonC == "" {
+		lonC = common.East
+	}
+	return fmt.Appendf(buf, latFormat+";%s;"+lonFormat+";%s", p.Lat.Coordinate, latC, p.Lon.Coordinate, lonC)
+}
+
+func appendFloat(buf []byte, f null.Float) []byte {
+	if !f.Valid {
+		return append(buf, na...)
+	}
+	return strconv.AppendFloat(buf, f.Float64, 'f', -1, 64)
+}
+
+func appendInt(buf []byte, i null.Int) []byte {
+	if !i.Valid {
+		return append(buf, na...)
+	}
+	return strconv.AppendInt(buf, i.Int64, 10)
+}
+
+func appendString(buf []byte, s null.String) []byte {
+	if !s.Valid {
+		return append(buf, na...)
+	}
+	return append(buf, s.String...)
+}
+
+// appendCRC appends the delimiter and CRC16 of the message including the delimiter.
+func appendCRC(buf []byte, delim []byte) []byte {
+	buf = append(buf, delim...)
+	return fmt.Appendf(buf, crcFormat, crc16.Checksum(buf, crc16Table))
+}
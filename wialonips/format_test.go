@@ -0,0 +1,186 @@
+package wialonips
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gotrackery/protocol/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+const testIMEI = "866795037163746"
+
+func testShortenedData(sec int) ShortenedDataMessage {
+	return ShortenedDataMessage{
+		RegisteredAt: time.Date(2023, time.January, 24, 14, 18, sec, 0, time.UTC),
+		Point: common.PointWGS84{
+			Lon:   common.AxisWGS84{Coordinate: 3734.7740, Cardinal: common.East},
+			Lat:   common.AxisWGS84{Coordinate: 5547.7850, Cardinal: common.North},
+			Valid: true,
+		},
+		Speed:    null.NewFloat(12.5, true),
+		Course:   null.NewInt(184, true),
+		Altitude: null.NewFloat(188, true),
+		Sat:      null.NewInt(12, true),
+	}
+}
+
+func testData(sec int) DataMessage {
+	return DataMessage{
+		ShortenedDataMessage: testShortenedData(sec),
+		HDOP:                 null.NewFloat(0.9, true),
+		Inputs:               null.NewInt(5, true),
+		Outputs:              null.NewInt(0, false),
+		ADC:                  []null.Float{null.NewFloat(12.3, true), null.NewFloat(0, false), null.NewFloat(4, true)},
+		IButton:              null.NewString("", false),
+		Attributes: common.Attributes{
+			"odo":          0.0,
+			"cell_id":      int64(50176),
+			"frm_version":  "QCOQ",
+			"pwr_ext":      28.293,
+			"engine_hours": int64(sec),
+		},
+	}
+}
+
+func TestPacket_Encode(t *testing.T) {
+	sd := testShortenedData(38)
+
+	tests := []struct {
+		name string
+		p    Packet
+		want string
+	}{
+		{
+			name: "Login Packet v1.1",
+			p:    Packet{Version: V1_1, IMEI: testIMEI, Message: &LoginMessage{}},
+			want: "#L#866795037163746;NA\r\n",
+		},
+		{
+			name: "Login Packet v2.0",
+			p:    Packet{Version: V2_0, IMEI: testIMEI, Message: &LoginMessage{Password: "NA"}},
+			want: "#L#2.0;866795037163746;NA;9262\r\n",
+		},
+		{
+			name: "ShortData Packet v1.1",
+			p:    Packet{Version: V1_1, Message: &sd},
+			want: "#SD#240123;141838;5547.7850;N;03734.7740;E;12.5;184;188;12\r\n",
+		},
+		{
+			name: "ShortData Packet v2.0",
+			p:    Packet{Version: V2_0, Message: &sd},
+			want: "#SD#240123;141838;5547.7850;N;03734.7740;E;12.5;184;188;12;C69D\r\n",
+		},
+		{
+			name: "ShortData Packet v1.1 all na",
+			p:    Packet{Version: V1_1, Message: &ShortenedDataMessage{}},
+			want: "#SD#NA;NA;NA;NA;NA;NA;NA;NA;NA;NA\r\n",
+		},
+		{
+			name: "Data Packet v1.1",
+			p: Packet{Version: V1_1, Message: &DataMessage{
+				ShortenedDataMessage: sd,
+				HDOP:                 null.NewFloat(1, true),
+				Inputs:               null.NewInt(0, true),
+				Outputs:              null.NewInt(0, true),
+				Attributes:           common.Attributes{"fuel": 45.8, "count1": int64(564), "hw": "V4.5"},
+			}},
+			want: "#D#240123;141838;5547.7850;N;03734.7740;E;12.5;184;188;12;1;0;0;;NA;" +
+				"count1:1:564,fuel:2:45.8,hw:3:V4.5\r\n",
+		},
+		{
+			name: "Ping Packet v2.0",
+			p:    Packet{Version: V2_0, Message: &PingMessage{}},
+			want: "#P#0000\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.p.Encode()
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, string(got))
+			}
+		})
+	}
+}
+
+func TestPacket_EncodeRoundTrip(t *testing.T) {
+	d := testData(40)
+	tests := []struct {
+		name string
+		msg  Message
+	}{
+		{name: "Login", msg: &LoginMessage{Password: "secret"}},
+		{name: "ShortData", msg: func() Message { m := testShortenedData(38); return &m }()},
+		{name: "Data", msg: &d},
+		{name: "Data na", msg: &DataMessage{ShortenedDataMessage: testShortenedData(1)}},
+		{name: "BlackBox short", msg: &BlackBoxMessage{
+			ShortenedMessages: []ShortenedDataMessage{testShortenedData(1), testShortenedData(2), testShortenedData(3)},
+		}},
+		{name: "BlackBox data", msg: &BlackBoxMessage{
+			DataMessages: []DataMessage{testData(1), testData(2)},
+		}},
+		{name: "BlackBox mixed", msg: &BlackBoxMessage{
+			ShortenedMessages: []ShortenedDataMessage{testShortenedData(1), testShortenedData(3)},
+			DataMessages:      []DataMessage{testData(2)},
+			Order:             []BlackBoxRecord{{Shortened: true, Index: 0}, {Index: 0}, {Shortened: true, Index: 1}},
+		}},
+		{name: "Ping", msg: &PingMessage{}},
+	}
+	for _, tt := range tests {
+		for _, v := range []Version{V1_1, V2_0} {
+			for _, compressed := range []bool{false, true} {
+				name := tt.name + " " + v.String()
+				if compressed {
+					name += " compressed"
+				}
+				t.Run(name, func(t *testing.T) {
+					p := Packet{Version: v, IMEI: testIMEI, Message: tt.msg, Compressed: compressed}
+					data, err := p.Encode()
+					require.NoError(t, err)
+
+					got := NewPacket(v, testIMEI)
+					require.NoError(t, got.Decode(data), string(data))
+					assert.Equal(t, p, got)
+				})
+			}
+		}
+	}
+}
+
+func TestPacket_EncodeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       Packet
+		wantErr error
+	}{
+		{
+			name:    "no message",
+			p:       Packet{Version: V1_1},
+			wantErr: ErrWialonIPSUnsupportedPacketType,
+		},
+		{
+			name: "attribute delimiter",
+			p: Packet{Version: V1_1, Message: &DataMessage{
+				Attributes: common.Attributes{"text": "a,b"},
+			}},
+			wantErr: ErrWialonIPSParseAttribute,
+		},
+		{
+			name: "attribute type",
+			p: Packet{Version: V1_1, Message: &DataMessage{
+				Attributes: common.Attributes{"flag": true},
+			}},
+			wantErr: ErrWialonIPSParseAttribute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.p.Encode()
+			assert.True(t, errors.Is(err, tt.wantErr), "got error %v", err)
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package wialonips
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sigurn/crc16"
+	"gopkg.in/guregu/null.v4"
+)
+
+var _ Message = (*ImageMessage)(nil)
+
+var (
+	lineBreak   = []byte("\r\n")
+	imagePrefix = []byte("#I#")
+)
+
+// ImageMessage is a WialonIPS image message.
+// The device sends the image split into blocks, each block is sent in its own packet which looks as follows:
+// #I#Sz;Ind;Count;Date;Time;Name;CRC16\r\nBIN, where BIN is the binary block of Sz bytes.
+// CRC16 is calculated for the header fields including the last delimiter followed by the binary block,
+// the checksum of the header fields only is accepted too. Version 1.1 packet has no CRC16.
+// Use ImageAssembler to collect the blocks into the image.
+type ImageMessage struct {
+	// Size (Sz) is the size of the binary block.
+	Size int
+	// Index (Ind) is the index of the block, starting from 0.
+	Index int
+	// Count is the index of the last block of the image.
+	Count        int
+	RegisteredAt time.Time
+	Name         null.String
+	Data         []byte
+	message
+}
+
+// Decode decodes a WialonIPS message.
+func (im *ImageMessage) Decode(data []byte) error {
+	const headerLen = 6
+	i := bytes.Index(data, lineBreak)
+	if i < 0 {
+		im.err = ErrWialonIPSInvalidImageMessage // NA;0
+		return im.err
+	}
+	header, body := data[:i], data[i+len(lineBreak):]
+
+	length := countFields(header, fieldsDelimiter)
+	if (length != headerLen && im.ver != V2_0) || (length != headerLen+1 && im.ver == V2_0) {
+		im.err = ErrWialonIPSInvalidImageMessage
+		return im.err
+	}
+
+	var err error
+	fields := newFieldScanner(header, fieldsDelimiter)
+	if im.Size, err = strconv.Atoi(string(fields.next())); err != nil {
+		im.err = fmt.Errorf("parse size: %w", errors.Join(err, ErrWialonIPSInvalidImageMessage))
+		return im.err
+	}
+	if im.Index, err = strconv.Atoi(string(fields.next())); err != nil {
+		im.err = fmt.Errorf("parse index: %w", errors.Join(err, ErrWialonIPSInvalidImageMessage))
+		return im.err
+	}
+	if im.Count, err = strconv.Atoi(string(fields.next())); err != nil {
+		im.err = fmt.Errorf("parse count: %w", errors.Join(err, ErrWialonIPSInvalidImageMessage))
+		return im.err
+	}
+	if im.RegisteredAt, err = parseTime(fields.next(), fields.next()); err != nil {
+		im.err = fmt.Errorf("parse time: %w", errors.Join(err, ErrWialonIPSInvalidImageMessage))
+		return im.err
+	}
+	im.Name = parseString(fields.next())
+
+	if im.ver == V2_0 {
+		if im.err = validateImageCRC(header, body); im.err != nil {
+			return im.err
+		}
+	}
+
+	if im.Index < 0 || im.Index > im.Count || len(body) != im.Size {
+		im.err = fmt.Errorf("block %d of %d bytes, %d received: %w", im.Index, im.Size, len(body),
+			ErrWialonIPSImageData)
+		return im.err
+	}
+	im.Data = append([]byte(nil), body...)
+	return nil
+}
+
+// validateImageCRC checks CRC16 of the header and the binary block or of the header only.
+func validateImageCRC(header, body []byte) error {
+	i := bytes.LastIndex(header, fieldsDelimiter)
+	crc, err := parseCRC(header[i+1:])
+	if err != nil {
+		return fmt.Errorf("failed parsing crc %s: %w", string(header[i+1:]), ErrWialonIPSCRC16Validation)
+	}
+	checked := crc16.Update(crc16.Init(crc16Table), header[:i+1], crc16Table)
+	if crc == crc16.Complete(crc16.Update(checked, body, crc16Table), crc16Table) ||
+		crc == crc16.Complete(checked, crc16Table) {
+		return nil
+	}
+	return fmt.Errorf("failed crc validation: %w", ErrWialonIPSCRC16Validation)
+}
+
+// Encode encodes a WialonIPS message. Size is taken from the data.
+// Unlike the other messages it ends with the binary block, the packet has no line break after it.
+func (im *ImageMessage) Encode() ([]byte, error) {
+	im.Size = len(im.Data)
+	var buf []byte
+	buf = strconv.AppendInt(buf, int64(im.Size), 10)
+	buf = append(buf, fieldsDelimiter...)
+	buf = strconv.AppendInt(buf, int64(im.Index), 10)
+	buf = append(buf, fieldsDelimiter...)
+	buf = strconv.AppendInt(buf, int64(im.Count), 10)
+	buf = append(buf, fieldsDelimiter...)
+	buf = appendTime(buf, im.RegisteredAt)
+	buf = append(buf, fieldsDelimiter...)
+	buf = appendString(buf, im.Name)
+
+	if im.ver == V2_0 {
+		buf = append(buf, fieldsDelimiter...)
+		crc := crc16.Update(crc16.Init(crc16Table), buf, crc16Table)
+		crc = crc16.Complete(crc16.Update(crc, im.Data, crc16Table), crc16Table)
+		buf = fmt.Appendf(buf, crcFormat, crc)
+	}
+	buf = append(buf, lineBreak...)
+	return append(buf, im.Data...), nil
+}
+
+// Response returns a WialonIPS response message: the acknowledgement of the block.
+// Use ImageAssembler to get the acknowledgement of the whole image.
+func (im *ImageMessage) Response() []byte {
+	index := na
+	if !errors.Is(im.err, ErrWialonIPSInvalidImageMessage) {
+		index = strconv.Itoa(im.Index)
+	}
+	return []byte(fmt.Sprintf(responseTemplate, ImagePacket,
+		index+string(fieldsDelimiter)+MapErrToRespCode(ImagePacket, im.err)))
+}
+
+// SplitImage splits the image into the messages of blocks of blockSize bytes at most.
+func SplitImage(name string, registeredAt time.Time, data []byte, blockSize int) []*ImageMessage {
+	if blockSize <= 0 {
+		blockSize = len(data)
+	}
+	count := 0
+	if len(data) > 0 {
+		count = (len(data) - 1) / blockSize
+	}
+
+	result := make([]*ImageMessage, 0, count+1)
+	for i := 0; i <= count; i++ {
+		end := (i + 1) * blockSize
+		if end > len(data) {
+			end = len(data)
+		}
+		block := data[i*blockSize : end]
+		result = append(result, &ImageMessage{
+			Size:         len(block),
+			Index:        i,
+			Count:        count,
+			RegisteredAt: registeredAt,
+			Name:         null.NewString(name, name != ""),
+			Data:         block,
+		})
+	}
+	return result
+}
+
+// maxImageBlockSize is the maximum size of the binary block of the image packet accepted by Splitter.
+const maxImageBlockSize = 1 << 20
+
+// imageFrameLen returns the length of the image packet which header ends at lineEnd (index of '\n')
+// or false if the header has no valid size.
+func imageFrameLen(data []byte, lineEnd int) (int, bool) {
+	header := data[len(imagePrefix):lineEnd]
+	i := bytes.Index(header, fieldsDelimiter)
+	if i < 0 {
+		return 0, false
+	}
+	size, err := strconv.Atoi(string(header[:i]))
+	if err != nil || size < 0 || size > maxImageBlockSize {
+		return 0, false
+	}
+	return lineEnd + 1 + size, true
+}
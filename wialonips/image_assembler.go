@@ -0,0 +1,199 @@
+package wialonips
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// Image is the image collected from the blocks of ImageMessage.
+type Image struct {
+	IMEI         string
+	Name         null.String
+	RegisteredAt time.Time
+	Data         []byte
+}
+
+// DefaultMaxPending is the number of incomplete images of the device kept by ImageAssembler
+// when MaxPending is not set.
+const DefaultMaxPending = 8
+
+// ImageAssembler collects the image blocks sent by the devices into images.
+// The blocks of the image may come in any order, the image is identified by the device IMEI,
+// the image name and time. The zero value is ready to use. It is safe for concurrent use.
+type ImageAssembler struct {
+	// Timeout drops the incomplete image when no block of it comes for this time, 0 means no timeout.
+	Timeout time.Duration
+	// MaxSize limits the size of the image, 0 means no limit.
+	MaxSize int
+	// MaxPending limits the number of incomplete images of the device, the least recently updated one
+	// is dropped to start a new one. 0 means DefaultMaxPending, negative value means no limit.
+	MaxPending int
+
+	mu      sync.Mutex
+	images  map[imageKey]*partialImage
+	pending map[string]int // number of incomplete images by IMEI
+	now     func() time.Time
+}
+
+type imageKey struct {
+	imei         string
+	name         string
+	registeredAt int64
+}
+
+type partialImage struct {
+	count     int
+	blocks    map[int][]byte
+	size      int
+	updatedAt time.Time
+}
+
+// NewImageAssembler creates a new ImageAssembler with the given timeout and size limit.
+func NewImageAssembler(timeout time.Duration, maxSize int) *ImageAssembler {
+	return &ImageAssembler{
+		Timeout: timeout,
+		MaxSize: maxSize,
+	}
+}
+
+// Add adds the block to the image. It returns the acknowledgement to send to the device:
+// #AI#Ind;1 for the block, #AI#1 for the last block completing the image, which is returned too.
+// The image exceeding MaxSize is dropped with ErrWialonIPSImageData error and #AI#Ind;0 acknowledgement.
+// The new image of the device having MaxPending incomplete images drops the least recently updated one.
+func (a *ImageAssembler) Add(m *ImageMessage) ([]byte, *Image, error) {
+	if m.Index < 0 || m.Index > m.Count {
+		return blockNotAccepted(m.Index), nil, fmt.Errorf("block %d of %d: %w", m.Index, m.Count,
+			ErrWialonIPSImageData)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.init()
+	now := a.now()
+	a.expire(now)
+
+	key := imageKey{imei: m.IMEI(), name: m.Name.String, registeredAt: m.RegisteredAt.UnixNano()}
+	part, ok := a.images[key]
+	if !ok {
+		a.limitPending(key.imei)
+		a.pending[key.imei]++
+	}
+	if !ok || part.count != m.Count {
+		// the image is sent anew when the number of blocks changes.
+		part = &partialImage{count: m.Count, blocks: make(map[int][]byte)}
+		a.images[key] = part
+	}
+	part.updatedAt = now
+	part.size += len(m.Data) - len(part.blocks[m.Index])
+	part.blocks[m.Index] = m.Data
+
+	if a.MaxSize > 0 && part.size > a.MaxSize {
+		a.remove(key)
+		return blockNotAccepted(m.Index), nil, fmt.Errorf("image of %d bytes exceeds %d bytes: %w",
+			part.size, a.MaxSize, ErrWialonIPSImageData)
+	}
+
+	if len(part.blocks) <= part.count {
+		return m.Response(), nil, nil
+	}
+
+	a.remove(key)
+	img := &Image{
+		IMEI:         key.imei,
+		Name:         m.Name,
+		RegisteredAt: m.RegisteredAt,
+		Data:         make([]byte, 0, part.size),
+	}
+	for i := 0; i <= part.count; i++ {
+		img.Data = append(img.Data, part.blocks[i]...)
+	}
+	return []byte(fmt.Sprintf(responseTemplate, ImagePacket, "1")), img, nil
+}
+
+// blockNotAccepted returns the acknowledgement of the block that is not accepted.
+func blockNotAccepted(index int) []byte {
+	return []byte(fmt.Sprintf(responseTemplate, ImagePacket, fmt.Sprintf("%d;0", index)))
+}
+
+// Drop drops the incomplete images of the device, e.g. when the connection is closed.
+func (a *ImageAssembler) Drop(imei string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for k := range a.images {
+		if k.imei == imei {
+			a.remove(k)
+		}
+	}
+}
+
+// Pending returns the number of incomplete images.
+func (a *ImageAssembler) Pending() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return len(a.images)
+}
+
+// expire drops the images which blocks have not come within the timeout.
+func (a *ImageAssembler) expire(now time.Time) {
+	if a.Timeout <= 0 {
+		return
+	}
+	for k, part := range a.images {
+		if now.Sub(part.updatedAt) > a.Timeout {
+			a.remove(k)
+		}
+	}
+}
+
+// init initialises the zero value of ImageAssembler.
+func (a *ImageAssembler) init() {
+	if a.images == nil {
+		a.images = make(map[imageKey]*partialImage)
+		a.pending = make(map[string]int)
+	}
+	if a.now == nil {
+		a.now = time.Now
+	}
+}
+
+// limitPending drops the least recently updated image of the device when it has MaxPending images.
+func (a *ImageAssembler) limitPending(imei string) {
+	limit := a.MaxPending
+	if limit == 0 {
+		limit = DefaultMaxPending
+	}
+	if limit < 0 || a.pending[imei] < limit {
+		return
+	}
+
+	var (
+		oldest   imageKey
+		found    bool
+		earliest time.Time
+	)
+	for k, part := range a.images {
+		if k.imei == imei && (!found || part.updatedAt.Before(earliest)) {
+			oldest, earliest, found = k, part.updatedAt, true
+		}
+	}
+	if found {
+		a.remove(oldest)
+	}
+}
+
+// remove removes the image.
+func (a *ImageAssembler) remove(key imageKey) {
+	if _, ok := a.images[key]; !ok {
+		return
+	}
+	delete(a.images, key)
+	if a.pending[key.imei]--; a.pending[key.imei] <= 0 {
+		delete(a.pending, key.imei)
+	}
+}
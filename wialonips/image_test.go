@@ -0,0 +1,234 @@
+package wialonips
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+// testImage contains line breaks and packet delimiters to check the binary framing.
+var testImage = []byte("\xff\xd8\r\n#I#5;0;0;NA;NA;NA\r\n\x00\x01\x02\r\n\xff\xd9\r")
+
+func encodeImage(t *testing.T, v Version, blockSize int) []byte {
+	t.Helper()
+	var buf []byte
+	at := time.Date(2023, time.January, 24, 14, 18, 38, 0, time.UTC)
+	for _, m := range SplitImage("photo.jpg", at, testImage, blockSize) {
+		p := Packet{Version: v, IMEI: testIMEI, Message: m}
+		data, err := p.Encode()
+		require.NoError(t, err)
+		buf = append(buf, data...)
+	}
+	return buf
+}
+
+func TestImageMessage_Decode(t *testing.T) {
+	for _, v := range []Version{V1_1, V2_0} {
+		t.Run(v.String(), func(t *testing.T) {
+			stream := encodeImage(t, v, 8)
+
+			scanner := bufio.NewScanner(bytes.NewReader(stream))
+			scanner.Split(NewSplitter().Splitter())
+			var blocks []*ImageMessage
+			for scanner.Scan() {
+				p := NewPacket(v, testIMEI)
+				require.NoError(t, p.Decode(scanner.Bytes()))
+				require.Equal(t, ImagePacket, p.Type)
+				m, ok := p.Message.(*ImageMessage)
+				require.True(t, ok)
+				blocks = append(blocks, m)
+			}
+			require.NoError(t, scanner.Err())
+			require.Len(t, blocks, 4)
+
+			var data []byte
+			for i, m := range blocks {
+				assert.Equal(t, i, m.Index)
+				assert.Equal(t, 3, m.Count)
+				assert.Equal(t, len(m.Data), m.Size)
+				assert.Equal(t, null.NewString("photo.jpg", true), m.Name)
+				assert.Equal(t, time.Date(2023, time.January, 24, 14, 18, 38, 0, time.UTC), m.RegisteredAt)
+				data = append(data, m.Data...)
+			}
+			assert.Equal(t, testImage, data)
+			assert.Equal(t, "#AI#2;1\r\n", string(blocks[2].Response()))
+		})
+	}
+}
+
+func TestImageMessage_DecodeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		wantErr  error
+		wantResp string
+	}{
+		{
+			name:     "no header end",
+			data:     "3;0;0;240123;141838;NA;1234",
+			wantErr:  ErrWialonIPSInvalidImageMessage,
+			wantResp: "#AI#NA;0\r\n",
+		},
+		{
+			name:     "bad size",
+			data:     "x;0;0;240123;141838;NA;1234\r\nabc",
+			wantErr:  ErrWialonIPSInvalidImageMessage,
+			wantResp: "#AI#NA;0\r\n",
+		},
+		{
+			name:     "bad crc",
+			data:     "3;1;2;240123;141838;NA;1234\r\nabc",
+			wantErr:  ErrWialonIPSCRC16Validation,
+			wantResp: "#AI#1;01\r\n",
+		},
+		{
+			name:     "header crc only",
+			data:     "3;1;2;240123;141838;NA;B4CB\r\nabc",
+			wantResp: "#AI#1;1\r\n",
+		},
+		{
+			name:     "short block",
+			data:     "4;1;2;240123;141838;NA;A2AD\r\nabc",
+			wantErr:  ErrWialonIPSImageData,
+			wantResp: "#AI#1;0\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &ImageMessage{message: message{ver: V2_0}}
+			err := m.Decode([]byte(tt.data))
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tt.wantErr), "got error %v", err)
+			}
+			assert.Equal(t, tt.wantResp, string(m.Response()))
+		})
+	}
+}
+
+func TestImageAssembler_Add(t *testing.T) {
+	at := time.Date(2023, time.January, 24, 14, 18, 38, 0, time.UTC)
+	blocks := SplitImage("photo.jpg", at, testImage, 10)
+	require.Len(t, blocks, 4)
+	for _, b := range blocks {
+		b.setHeader(V2_0, testIMEI)
+	}
+
+	a := NewImageAssembler(time.Minute, 0)
+	// the blocks may come in any order and be repeated
+	for _, i := range []int{2, 0, 2, 3} {
+		resp, img, err := a.Add(blocks[i])
+		require.NoError(t, err)
+		assert.Nil(t, img)
+		assert.Equal(t, "#AI#"+string(rune('0'+i))+";1\r\n", string(resp))
+	}
+	assert.Equal(t, 1, a.Pending())
+
+	resp, img, err := a.Add(blocks[1])
+	require.NoError(t, err)
+	assert.Equal(t, "#AI#1\r\n", string(resp))
+	if assert.NotNil(t, img) {
+		assert.Equal(t, &Image{IMEI: testIMEI, Name: null.NewString("photo.jpg", true), RegisteredAt: at,
+			Data: testImage}, img)
+	}
+	assert.Equal(t, 0, a.Pending())
+}
+
+func TestImageAssembler_Limits(t *testing.T) {
+	at := time.Date(2023, time.January, 24, 14, 18, 38, 0, time.UTC)
+	blocks := SplitImage("", at, testImage, 10)
+	for _, b := range blocks {
+		b.setHeader(V2_0, testIMEI)
+	}
+
+	t.Run("timeout", func(t *testing.T) {
+		now := at
+		a := NewImageAssembler(time.Minute, 0)
+		a.now = func() time.Time { return now }
+
+		_, _, err := a.Add(blocks[0])
+		require.NoError(t, err)
+		now = now.Add(2 * time.Minute)
+		_, _, err = a.Add(blocks[1])
+		require.NoError(t, err)
+
+		// the first block is dropped with the expired image
+		for _, b := range blocks[2:] {
+			_, img, err := a.Add(b)
+			require.NoError(t, err)
+			assert.Nil(t, img)
+		}
+		assert.Equal(t, 1, a.Pending())
+	})
+
+	t.Run("max size", func(t *testing.T) {
+		a := NewImageAssembler(0, 25)
+		_, _, err := a.Add(blocks[0])
+		require.NoError(t, err)
+		_, _, err = a.Add(blocks[1])
+		require.NoError(t, err)
+		resp, img, err := a.Add(blocks[2])
+		assert.True(t, errors.Is(err, ErrWialonIPSImageData))
+		assert.Nil(t, img)
+		assert.Equal(t, "#AI#2;0\r\n", string(resp))
+		assert.Equal(t, 0, a.Pending())
+	})
+
+	t.Run("max pending", func(t *testing.T) {
+		now := at
+		a := &ImageAssembler{MaxPending: 2}
+		a.now = func() time.Time { return now }
+		for i := 0; i < 3; i++ {
+			m := *blocks[0]
+			m.Name = null.StringFrom(fmt.Sprintf("photo%d.jpg", i))
+			_, _, err := a.Add(&m)
+			require.NoError(t, err)
+			now = now.Add(time.Second)
+		}
+		assert.Equal(t, 2, a.Pending())
+
+		// the first image is dropped, its block starts it anew.
+		m := *blocks[1]
+		m.Name = null.StringFrom("photo0.jpg")
+		_, _, err := a.Add(&m)
+		require.NoError(t, err)
+		assert.Equal(t, 2, a.Pending())
+
+		other := *blocks[0]
+		other.setHeader(V2_0, "860000000000000")
+		_, _, err = a.Add(&other)
+		require.NoError(t, err)
+		assert.Equal(t, 3, a.Pending())
+	})
+
+	t.Run("zero value", func(t *testing.T) {
+		var a ImageAssembler
+		for _, b := range blocks[:len(blocks)-1] {
+			_, img, err := a.Add(b)
+			require.NoError(t, err)
+			assert.Nil(t, img)
+		}
+		_, img, err := a.Add(blocks[len(blocks)-1])
+		require.NoError(t, err)
+		if assert.NotNil(t, img) {
+			assert.Equal(t, testImage, img.Data)
+		}
+		assert.Equal(t, 0, a.Pending())
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		a := NewImageAssembler(0, 0)
+		_, _, err := a.Add(blocks[0])
+		require.NoError(t, err)
+		a.Drop(testIMEI)
+		assert.Equal(t, 0, a.Pending())
+	})
+}
@@ -1,7 +1,6 @@
 package wialonips
 
 import (
-	"bytes"
 	"fmt"
 )
 
@@ -21,18 +20,18 @@ type LoginMessage struct {
 // Decode decodes a WialonIPS message.
 func (l *LoginMessage) Decode(data []byte) error {
 	l.ver = UnknownVersion
-	bytesSet := bytes.Split(data, fieldsDelimiter)
-	length := len(bytesSet)
+	length := countFields(data, fieldsDelimiter)
 	if length < 2 { //nolint:gomnd
 		l.err = ErrWialonIPSInvalidLoginMessage // "0"
 		return l.err
 	}
 
+	fields := newFieldScanner(data, fieldsDelimiter)
 	if length == 2 { //nolint:gomnd
 		l.ver = V1_1
 	} else {
-		l.ver = V2_0 // bytesSet[0] contains version number
-		bytesSet = bytesSet[1:length]
+		l.ver = V2_0
+		fields.next() // version number
 	}
 
 	if l.ver == V2_0 {
@@ -42,12 +41,29 @@ func (l *LoginMessage) Decode(data []byte) error {
 		}
 	}
 
-	l.imei = string(bytesSet[0])
-	l.Password = string(bytesSet[1])
+	l.imei = string(fields.next())
+	l.Password = string(fields.next())
 
 	return nil
 }
 
+// Encode encodes a WialonIPS message.
+func (l *LoginMessage) Encode() ([]byte, error) {
+	var buf []byte
+	if l.ver == V2_0 {
+		buf = append(buf, l.ver.String()...)
+		buf = append(buf, fieldsDelimiter...)
+	}
+	buf = append(buf, l.imei...)
+	buf = append(buf, fieldsDelimiter...)
+	if l.Password == "" {
+		buf = append(buf, na...)
+	} else {
+		buf = append(buf, l.Password...)
+	}
+	return l.seal(buf, fieldsDelimiter), nil
+}
+
 // Response returns a WialonIPS response message.
 func (l *LoginMessage) Response() []byte {
 	return []byte(fmt.Sprintf(responseTemplate, LoginPacket, MapErrToRespCode(LoginPacket, l.err)))
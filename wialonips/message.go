@@ -23,6 +23,12 @@ var (
 	blackBoxDelimiter = []byte{0x7c} // |
 )
 
+// header is implemented by the messages embedding message.
+type header interface {
+	setHeader(v Version, imei string)
+	reset(v Version, imei string)
+}
+
 type message struct {
 	imei string
 	ver  Version
@@ -41,6 +47,25 @@ func (m *message) Error() error {
 	return m.err
 }
 
+// setHeader sets the version and IMEI of the packet the message is encoded into.
+func (m *message) setHeader(v Version, imei string) {
+	m.ver = v
+	m.imei = imei
+}
+
+// reset resets the message state before decoding the message of the packet.
+func (m *message) reset(v Version, imei string) {
+	*m = message{imei: imei, ver: v}
+}
+
+// seal appends the delimiter and CRC16 to the message of version 2.0.
+func (m *message) seal(buf []byte, delim []byte) []byte {
+	if m.ver != V2_0 {
+		return buf
+	}
+	return appendCRC(buf, delim)
+}
+
 func validateCRC(data []byte, delim []byte) error {
 	i := bytes.LastIndex(data, delim)
 	crc, err := parseCRC(data[i+1:])
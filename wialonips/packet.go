@@ -18,6 +18,8 @@ const (
 	DataPacket          PacketType = "D"  // Data packet.
 	BlackBoxPacket      PacketType = "B"  // Black box packet.
 	PingPacket          PacketType = "P"  // Ping packet.
+	ImagePacket         PacketType = "I"  // Image packet.
+	TextPacket          PacketType = "M"  // Message to/from the driver.
 )
 
 var (
@@ -26,10 +28,6 @@ var (
 	crc16Table               = crc16.MakeTable(crc16.CRC16_ARC)
 )
 
-const (
-	ddmmyyhhmiss = "020106150405"
-)
-
 // Version is the version of Wialon IPS protocol.
 type Version int
 
@@ -62,6 +60,9 @@ func (s Version) IsValid() bool {
 // Message is the message of Wialon IPS protocol.
 type Message interface {
 	Decode(data []byte) error
+	// Encode returns the message as it is placed between the packet type and the line break,
+	// in the syntax of the message version.
+	Encode() ([]byte, error)
 	// Version returns version of Wialon IPS protocol. To avoid reflection use.
 	Version() Version
 	IMEI() string
@@ -71,11 +72,19 @@ type Message interface {
 // Packet is the packet of Wialon IPS protocol.
 // All data is received in text format as a packet which looks as follows:
 // #PT#msgCRC\r\n.
+// Version 2.0 devices may compress the packet, see Compressed. The packet sent in UDP datagram
+// has the version and IMEI prefix, see Datagram.
 type Packet struct {
 	Type    PacketType
 	Version Version
 	IMEI    string // IMEI is the unique identifier of the device.
 	Message Message
+	// Compressed is set when the packet came compressed: 0xFF, the length (uint16) and zlib data
+	// containing the text packet.
+	Compressed bool
+	// Datagram is set when the packet came in UDP datagram: IMEI#PT#msg\r\n for version 1.1 and
+	// Protocol_version;IMEI;#PT#msg;CRC16\r\n for version 2.0, CRC16 is calculated for the whole datagram.
+	Datagram bool
 }
 
 // NewPacket creates a new packet of Wialon IPS protocol.
@@ -84,42 +93,71 @@ func NewPacket(v Version, imei string) Packet {
 }
 
 // Decode decodes bytes to the package of Wialon IPS protocol.
-func (p *Packet) Decode(data []byte) error { //nolint:cyclop
-	// ToDo add deflate
-	bytesSet := bytes.SplitN(data, packetTypeDelimiter, 3) //nolint:gomnd
-	if len(bytesSet) != 3 {                                //nolint:gomnd
+func (p *Packet) Decode(data []byte) error {
+	return p.decode(data, newMessage)
+}
+
+// decode decodes the package into the message got from messages for the packet type.
+func (p *Packet) decode(data []byte, messages func(PacketType) Message) error { //nolint:cyclop
+	if len(data) > 0 && data[0] == compressionMark {
+		inflated, err := inflate(data)
+		if err != nil {
+			return fmt.Errorf("failed to decompress packet: %w", err)
+		}
+		p.Compressed = true
+		data = inflated
+	}
+
+	i := bytes.IndexByte(data, packetTypeDelimiter[0])
+	j := -1
+	if i >= 0 {
+		j = bytes.IndexByte(data[i+1:], packetTypeDelimiter[0])
+	}
+	if j < 0 {
 		return fmt.Errorf("invalid package structure: %w", common.ErrBadData)
 	}
-	// ToDo UDP bytesSet[0] contains Protocol_version;imei - v2.0 and imei - v1.0
-	p.parsePackageType(bytesSet[1])
+	prefix, typ, msg := data[:i], data[i+1:i+1+j], data[i+j+2:]
+	if len(prefix) > 0 {
+		if err := p.parseDatagramPrefix(prefix); err != nil {
+			return err
+		}
+	}
+	p.parsePackageType(typ)
 	if p.Type == UnknownPacket {
 		return ErrWialonIPSUnsupportedPacketType
 	}
 
-	msg := bytes.TrimRight(bytesSet[2], "\r\n")
+	if p.Type != ImagePacket {
+		// the image packet ends with the binary block which may end with these bytes too.
+		msg = bytes.TrimRight(msg, "\r\n")
+	}
 
-	switch p.Type {
-	case UnknownPacket:
-		return ErrWialonIPSUnsupportedPacketType
-	case LoginPacket:
-		p.Message = &LoginMessage{message: message{imei: p.IMEI, ver: p.Version}}
-	case ShortenedDataPacket:
-		p.Message = &ShortenedDataMessage{message: message{imei: p.IMEI, ver: p.Version}}
-	case DataPacket:
-		p.Message = &DataMessage{ShortenedDataMessage: ShortenedDataMessage{message: message{imei: p.IMEI, ver: p.Version}}}
-	case BlackBoxPacket:
-		p.Message = &BlackBoxMessage{message: message{imei: p.IMEI, ver: p.Version}}
-	case PingPacket:
-		return ErrWialonIPSUnsupportedPacketType // TODO implement this.
+	// the message of version 2.0 datagram has no CRC16 of its own, it is decoded in version 1.1 syntax.
+	ver := p.Version
+	if p.Datagram && p.Version == V2_0 {
+		var err error
+		if msg, err = datagramMessage(bytes.TrimRight(data, "\r\n"), msg); err != nil {
+			return err
+		}
+		ver = V1_1
 	}
 
+	p.Message = messages(p.Type)
+	h, ok := p.Message.(header)
+	if ok {
+		h.reset(ver, p.IMEI)
+	}
 	err := p.Message.Decode(msg)
 	if err != nil {
 		p.Message = nil
 		return fmt.Errorf("failed to decode message: %w", err)
 	}
 
-	if p.Type == LoginPacket {
+	if ok && ver != p.Version {
+		h.setHeader(p.Version, p.IMEI)
+	}
+
+	if p.Type == LoginPacket && !p.Datagram {
 		p.Version = p.Message.Version()
 		p.IMEI = p.Message.IMEI()
 	}
@@ -127,11 +165,118 @@ func (p *Packet) Decode(data []byte) error { //nolint:cyclop
 	return nil
 }
 
+// newMessage returns the new message of the packet type.
+func newMessage(t PacketType) Message {
+	switch t {
+	case LoginPacket:
+		return &LoginMessage{}
+	case ShortenedDataPacket:
+		return &ShortenedDataMessage{}
+	case DataPacket:
+		return &DataMessage{}
+	case BlackBoxPacket:
+		return &BlackBoxMessage{}
+	case PingPacket:
+		return &PingMessage{}
+	case ImagePacket:
+		return &ImageMessage{}
+	case TextPacket:
+		return &TextMessage{}
+	case UnknownPacket:
+	}
+	return nil
+}
+
+// Encode encodes the package of Wialon IPS protocol: #PT#msgCRC\r\n, the datagram gets the prefix and
+// CRC16 of the whole datagram.
+// The message is encoded in the syntax of the packet version and gets the packet IMEI.
+// The packet type is taken from the message when it is not set. Compressed packet is compressed by zlib.
+func (p *Packet) Encode() ([]byte, error) {
+	if p.Message == nil {
+		return nil, fmt.Errorf("no message to encode: %w", ErrWialonIPSUnsupportedPacketType)
+	}
+	if p.Type == UnknownPacket {
+		p.Type = messagePacketType(p.Message)
+	}
+	if p.Type == UnknownPacket {
+		return nil, ErrWialonIPSUnsupportedPacketType
+	}
+	h, ok := p.Message.(header)
+	if ok {
+		h.setHeader(p.Version, p.IMEI)
+		if p.Datagram {
+			// the message of version 2.0 datagram has no CRC16 of its own.
+			h.setHeader(V1_1, p.IMEI)
+			defer h.setHeader(p.Version, p.IMEI)
+		}
+	}
+
+	msg, err := p.Message.Encode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	result := make([]byte, 0, len(msg)+len(p.Type)+len(p.IMEI)+16) //nolint:gomnd
+	if p.Datagram {
+		result = p.appendDatagramPrefix(result)
+	}
+	result = append(result, packetTypeDelimiter...)
+	result = append(result, p.Type...)
+	result = append(result, packetTypeDelimiter...)
+	result = append(result, msg...)
+	if p.Datagram && p.Version == V2_0 {
+		result = appendCRC(result, fieldsDelimiter)
+	}
+	if p.Type != ImagePacket {
+		result = append(result, lineBreak...)
+	}
+
+	if p.Compressed {
+		if result, err = deflate(result); err != nil {
+			return nil, fmt.Errorf("failed to compress packet: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// messagePacketType returns the packet type of the message.
+func messagePacketType(m Message) PacketType {
+	switch m.(type) {
+	case *LoginMessage:
+		return LoginPacket
+	case *ShortenedDataMessage:
+		return ShortenedDataPacket
+	case *DataMessage:
+		return DataPacket
+	case *BlackBoxMessage:
+		return BlackBoxPacket
+	case *PingMessage:
+		return PingPacket
+	case *ImageMessage:
+		return ImagePacket
+	case *TextMessage:
+		return TextPacket
+	}
+	return UnknownPacket
+}
+
 func (p *Packet) parsePackageType(data []byte) {
-	p.Type = PacketType(data)
-	switch p.Type { //nolint:exhaustive
-	case LoginPacket, ShortenedDataPacket, DataPacket, BlackBoxPacket, PingPacket:
-		return
+	// the constants are assigned to not allocate the string of the type.
+	switch string(data) {
+	case string(LoginPacket):
+		p.Type = LoginPacket
+	case string(ShortenedDataPacket):
+		p.Type = ShortenedDataPacket
+	case string(DataPacket):
+		p.Type = DataPacket
+	case string(BlackBoxPacket):
+		p.Type = BlackBoxPacket
+	case string(PingPacket):
+		p.Type = PingPacket
+	case string(ImagePacket):
+		p.Type = ImagePacket
+	case string(TextPacket):
+		p.Type = TextPacket
 	default:
 		p.Type = UnknownPacket
 	}
@@ -584,6 +584,64 @@ func TestDecode(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Ping Packet v1.1",
+			args: args{
+				data: "2350230d0a",
+				v:    V1_1,
+				imei: "862462031400566",
+			},
+			want: Packet{
+				Type:    PingPacket,
+				Version: V1_1,
+				IMEI:    "862462031400566",
+				Message: &PingMessage{message: message{imei: "862462031400566", ver: V1_1}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Ping Packet v1.1 - unexpected data",
+			args: args{
+				data: "235023303030300d0a",
+				v:    V1_1,
+				imei: "862462031400566",
+			},
+			want: Packet{
+				Type:    PingPacket,
+				Version: V1_1,
+				IMEI:    "862462031400566",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Ping Packet v2.0 with CRC",
+			args: args{
+				data: "235023303030300d0a",
+				v:    V2_0,
+				imei: "862462031400566",
+			},
+			want: Packet{
+				Type:    PingPacket,
+				Version: V2_0,
+				IMEI:    "862462031400566",
+				Message: &PingMessage{message: message{imei: "862462031400566", ver: V2_0}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Ping Packet v2.0 - invalid CRC",
+			args: args{
+				data: "235023313233340d0a",
+				v:    V2_0,
+				imei: "862462031400566",
+			},
+			want: Packet{
+				Type:    PingPacket,
+				Version: V2_0,
+				IMEI:    "862462031400566",
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -607,6 +665,16 @@ func TestDecode(t *testing.T) {
 	}
 }
 
+func TestPingMessage_Response(t *testing.T) {
+	p := NewPacket(V2_0, "862462031400566")
+	if err := p.Decode([]byte("#P#\r\n")); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got := string(p.Message.Response()); got != "#AP#\r\n" {
+		t.Errorf("Response() = %q, want %q", got, "#AP#\r\n")
+	}
+}
+
 func TestVersion_String(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -2,53 +2,89 @@ package wialonips
 
 import (
 	"bytes"
-	"encoding/binary"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/gotrackery/protocol/common"
 	"gopkg.in/guregu/null.v4"
 )
 
+const (
+	// maxNumberLen is the length of the number fitting the stack buffer of the comma replacement.
+	maxNumberLen = 32
+	// maxReusedNames is the number of the parameter names reused by parseAttrs.
+	maxReusedNames = 64
+)
+
+// fieldScanner scans the fields of the message in one pass without copying, the fields are subslices of the data.
+type fieldScanner struct {
+	data  []byte
+	delim byte
+	done  bool
+}
+
+// newFieldScanner creates a new fieldScanner of the fields delimited by delim.
+func newFieldScanner(data []byte, delim []byte) fieldScanner {
+	return fieldScanner{data: data, delim: delim[0]}
+}
+
+// next returns the next field, nil when all fields are scanned.
+func (s *fieldScanner) next() []byte {
+	if s.done {
+		return nil
+	}
+	i := bytes.IndexByte(s.data, s.delim)
+	if i < 0 {
+		s.done = true
+		return s.data
+	}
+	field := s.data[:i]
+	s.data = s.data[i+1:]
+	return field
+}
+
+// countFields returns the number of the fields delimited by delim.
+func countFields(data []byte, delim []byte) int {
+	return bytes.Count(data, delim) + 1
+}
+
 //nolint:nakedret
-func parseBaseFields(data [][]byte) (
+func parseBaseFields(s *fieldScanner) (
 	t time.Time, p common.PointWGS84, speed null.Float, course null.Int, alt null.Float, sat null.Int, err error) {
-	t, err = parseTime(data[0:2])
+	t, err = parseTime(s.next(), s.next())
 	if err != nil {
 		err = fmt.Errorf("parse time: %w", errors.Join(err, ErrWialonIPSParseDateTime)) // "0"
 		return
 	}
 
-	p, err = parsePoint(data[2:6])
+	p, err = parsePoint(s.next(), s.next(), s.next(), s.next())
 	if err != nil {
 		err = fmt.Errorf("parse point: %w", errors.Join(err, ErrWialonIPSParsePoint)) // "10"
 		return
 	}
 
-	speed, err = parseFloat(data[6])
+	speed, err = parseFloat(s.next())
 	if err != nil {
 		err = fmt.Errorf("parse speed: %w", errors.Join(err, ErrWialonIPSParseSCA)) // "11"
 		return
 	}
 
-	course, err = parseInt(data[7])
+	course, err = parseInt(s.next())
 	if err != nil {
 		// ToDo if i>359 return error
 		err = fmt.Errorf("parse course: %w", errors.Join(err, ErrWialonIPSParseSCA)) // "11"
 		return
 	}
 
-	alt, err = parseFloat(data[8])
+	alt, err = parseFloat(s.next())
 	if err != nil {
 		err = fmt.Errorf("parse altitude: %w", errors.Join(err, ErrWialonIPSParseSCA)) // "11"
 		return
 	}
 
-	sat, err = parseInt(data[9])
+	sat, err = parseInt(s.next())
 	if err != nil {
 		err = fmt.Errorf("parse sats: %w", errors.Join(err, ErrWialonIPSParseSats)) // "12"
 		return
@@ -57,80 +93,114 @@ func parseBaseFields(data [][]byte) (
 	return
 }
 
-func parseAdditionalFields(data [][]byte) (
+// parseAdditionalFields parses HDOP;Inputs;Outputs;ADC;Ibutton fields, the ADC values are appended to buf[:0].
+//
+//nolint:nakedret
+func parseAdditionalFields(s *fieldScanner, buf []null.Float) (
 	hdop null.Float, inputs, outputs null.Int, adc []null.Float, ibutton null.String, err error) {
-	hdop, err = parseFloat(data[0])
+	hdop, err = parseFloat(s.next())
 	if err != nil {
 		err = fmt.Errorf("parse hdop: %w", errors.Join(err, ErrWialonIPSParseHDOP)) // 12
 		return
 	}
 
-	inputs, err = parseInt(data[1])
+	inputs, err = parseInt(s.next())
 	if err != nil {
 		err = fmt.Errorf("parse input: %w", errors.Join(err, ErrWialonIPSParseInOutput)) // 13
 		return
 	}
 
-	outputs, err = parseInt(data[2])
+	outputs, err = parseInt(s.next())
 	if err != nil {
 		err = fmt.Errorf("parse output: %w", errors.Join(err, ErrWialonIPSParseInOutput)) // 13
 		return
 	}
 
-	adc, err = parseADC(data[3])
+	adc, err = parseADC(s.next(), buf)
 	if err != nil {
 		err = fmt.Errorf("parse adc: %w", errors.Join(err, ErrWialonIPSParseADC)) // 14
 		return
 	}
 
-	ibutton = parseString(data[4])
+	ibutton = parseString(s.next())
 	return
 }
 
-func parseADC(data []byte) (adc []null.Float, err error) {
+// parseADC parses the analog inputs appending them to adc[:0].
+func parseADC(data []byte, adc []null.Float) ([]null.Float, error) {
+	adc = adc[:0]
 	if len(data) == 0 {
-		return
+		return adc, nil
 	}
-	bytesSet := bytes.Split(data, analogDelimiter)
-	adc = make([]null.Float, 0, len(bytesSet))
-	for _, v := range bytesSet {
+	if n := countFields(data, analogDelimiter); cap(adc) < n {
+		adc = make([]null.Float, 0, n)
+	}
+	s := newFieldScanner(data, analogDelimiter)
+	for v := s.next(); v != nil; v = s.next() {
 		f, err := parseFloat(v)
 		if err != nil {
 			return nil, err
 		}
 		adc = append(adc, f)
 	}
-	return
+	return adc, nil
 }
 
-func parseAttrs(data []byte) (a common.Attributes, err error) {
-	if len(data) == 0 || (len(data) == 2 && string(data) == na) {
-		return
+// parseAttrs parses the parameters into a, which is cleared before. The map is created if a is nil.
+// The names of the cleared parameters are reused for the same parameters to not allocate them again.
+func parseAttrs(data []byte, a common.Attributes) (common.Attributes, error) {
+	var buf [maxReusedNames]string
+	names := buf[:0]
+	for k := range a {
+		if len(names) < len(buf) {
+			names = append(names, k)
+		}
+		delete(a, k)
+	}
+	if len(data) == 0 || string(data) == na {
+		return a, nil
 	}
 
-	bytesSet := bytes.Split(data, valuesDelimiter)
-	a = make(common.Attributes)
-	for _, attr := range bytesSet {
-		k, v, err := parseAttr(attr)
+	if a == nil {
+		a = make(common.Attributes, countFields(data, valuesDelimiter))
+	}
+	s := newFieldScanner(data, valuesDelimiter)
+	for attr := s.next(); attr != nil; attr = s.next() {
+		name, v, err := parseAttr(attr)
 		if err != nil {
 			return nil, fmt.Errorf("parse attribute: %w", errors.Join(err, ErrWialonIPSParseAttribute))
 		}
-		a[k] = v
+		a[reuseName(names, name)] = v
 	}
-	return
+	return a, nil
 }
 
-func parseAttr(attr []byte) (k string, v interface{}, err error) {
-	var bytesSet = bytes.Split(attr, paramsDelimiter)
-	if len(bytesSet) != 3 { //nolint:gomnd
-		return "", nil, errors.New("invalid attribute format") //nolint:goerr113
+// reuseName returns the name from names or the new string of the name.
+func reuseName(names []string, name []byte) string {
+	for _, n := range names {
+		if n == string(name) {
+			return n
+		}
+	}
+	return string(name)
+}
+
+// parseAttr parses the parameter Name:Type:Value.
+func parseAttr(attr []byte) (name []byte, v interface{}, err error) {
+	if bytes.Count(attr, paramsDelimiter) != 2 { //nolint:gomnd
+		return nil, nil, errors.New("invalid attribute format") //nolint:goerr113
 	}
-	value, err := parseValue(bytesSet[1][0], bytesSet[2])
+	s := newFieldScanner(attr, paramsDelimiter)
+	name, typ, value := s.next(), s.next(), s.next()
+	if len(typ) != 1 {
+		return nil, nil, fmt.Errorf("invalid parameter type %s", typ) //nolint:goerr113
+	}
+	v, err = parseValue(typ[0], value)
 	if err != nil {
-		return "", nil, err
+		return nil, nil, err
 	}
 
-	return string(bytesSet[0]), value, nil
+	return name, v, nil
 }
 
 func parseValue(b byte, data []byte) (val interface{}, err error) {
@@ -145,88 +215,163 @@ func parseValue(b byte, data []byte) (val interface{}, err error) {
 	return nil, fmt.Errorf("invalid parameter type %v", b) //nolint:goerr113
 }
 
-func parseTime(data [][]byte) (t time.Time, err error) {
-	t, err = time.Parse(ddmmyyhhmiss, string(bytes.Join(data, []byte{})))
-	if err != nil && string(data[0]) == na {
+// parseTime parses the date ddmmyy and the time hhmmss in UTC. The current time is taken when the date is NA.
+func parseTime(date, tm []byte) (time.Time, error) {
+	const fieldLen = 6
+	var v [fieldLen]int
+	ok := len(date) == fieldLen && len(tm) == fieldLen
+	for i := 0; ok && i < fieldLen; i++ {
+		field := date
+		if i >= fieldLen/2 {
+			field = tm
+		}
+		j := i % (fieldLen / 2) * 2
+		v[i], ok = parseDigits(field[j], field[j+1])
+	}
+	day, month, year, hour, minute, sec := v[0], time.Month(v[1]), v[2], v[3], v[4], v[5]
+	// the century is chosen as time.Parse does it for the two-digit year.
+	if year >= 69 { //nolint:gomnd
+		year += 1900
+	} else {
+		year += 2000
+	}
+	if ok && month >= time.January && month <= time.December && day >= 1 && day <= daysIn(month, year) &&
+		hour < 24 && minute < 60 && sec < 60 {
+		return time.Date(year, month, day, hour, minute, sec, 0, time.UTC), nil
+	}
+
+	if string(date) == na {
 		// according spec
 		return time.Now(), nil
 	}
-	return
+	return time.Time{}, fmt.Errorf("invalid date %s or time %s", date, tm) //nolint:goerr113
+}
+
+// parseDigits parses the two-digit number.
+func parseDigits(hi, lo byte) (int, bool) {
+	if hi < '0' || hi > '9' || lo < '0' || lo > '9' {
+		return 0, false
+	}
+	return int(hi-'0')*10 + int(lo-'0'), true //nolint:gomnd
 }
 
-func parsePoint(data [][]byte) (common.PointWGS84, error) {
-	latC, err := common.ParseCardinalAxis(string(data[1]))
+// daysIn returns the number of days in the month of the year.
+func daysIn(m time.Month, year int) int {
+	return time.Date(year, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// parsePoint parses Lat1;Lat2;Lon1;Lon2 fields. NA cardinal axes are taken as N and E,
+// NA coordinates give the invalid point.
+func parsePoint(lat, latC, lon, lonC []byte) (common.PointWGS84, error) {
+	cardLat, err := parseCardinalAxis(latC, common.North)
 	if err != nil {
-		if string(data[1]) == na {
-			latC = common.North
-		} else {
-			return common.PointWGS84{}, fmt.Errorf("parse latitude: %w", err)
-		}
+		return common.PointWGS84{}, fmt.Errorf("parse latitude: %w", err)
 	}
-	lonC, err := common.ParseCardinalAxis(string(data[3]))
+	cardLon, err := parseCardinalAxis(lonC, common.East)
 	if err != nil {
-		if string(data[1]) == na {
-			lonC = common.East
-		} else {
-			return common.PointWGS84{}, fmt.Errorf("parse logitude: %w", err)
-		}
+		return common.PointWGS84{}, fmt.Errorf("parse logitude: %w", err)
 	}
-	wgs84, err := common.ParsePointWGS84(string(data[2]), lonC, string(data[0]), latC)
+	if string(lat) == na && string(lon) == na {
+		return common.PointWGS84{
+			Lon: common.AxisWGS84{Cardinal: cardLon},
+			Lat: common.AxisWGS84{Cardinal: cardLat},
+		}, nil
+	}
+
+	axisLon, err := parseAxis(lon, cardLon)
 	if err != nil {
-		if string(data[0]) == na && string(data[2]) == na {
-			wgs84, _ = common.ParsePointWGS84("0.0", lonC, "0.0", latC)
-			wgs84.Valid = false
-		} else {
-			return common.PointWGS84{}, fmt.Errorf("parse point: %w", err)
-		}
+		return common.PointWGS84{}, fmt.Errorf("failed to parse longitude value %s: %w", lon, err)
 	}
-	return wgs84, nil
+	axisLat, err := parseAxis(lat, cardLat)
+	if err != nil {
+		return common.PointWGS84{}, fmt.Errorf("failed to parse latitude value %s: %w", lat, err)
+	}
+	return common.PointWGS84{Lon: axisLon, Lat: axisLat, Valid: true}, nil
+}
+
+// parseCardinalAxis parses the cardinal axis, NA gives def.
+func parseCardinalAxis(data []byte, def common.CardinalAxis) (common.CardinalAxis, error) {
+	switch string(data) {
+	case string(common.North):
+		return common.North, nil
+	case string(common.South):
+		return common.South, nil
+	case string(common.East):
+		return common.East, nil
+	case string(common.West):
+		return common.West, nil
+	case na:
+		return def, nil
+	}
+	return "", fmt.Errorf("not allowed cardinal value %s", data) //nolint:goerr113
+}
+
+// parseAxis parses the coordinate in dddmm.mmmm form.
+func parseAxis(data []byte, c common.CardinalAxis) (common.AxisWGS84, error) {
+	f, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return common.AxisWGS84{}, fmt.Errorf("failed to parse coordinate value %s: %w", data, err)
+	}
+	if f < 0.0 || f > 18000.0 {
+		return common.AxisWGS84{}, fmt.Errorf("not allowed coordinate value %s", data) //nolint:goerr113
+	}
+	return common.AxisWGS84{Coordinate: f, Cardinal: c}, nil
 }
 
 func parseInt(data []byte) (null.Int, error) {
-	s := string(data)
-	if s == na || s == "" {
+	if len(data) == 0 || string(data) == na {
 		return null.NewInt(0, false), nil
 	}
-	i, err := strconv.ParseInt(s, 10, 0)
+	i, err := strconv.ParseInt(string(data), 10, 0)
 	if err != nil {
-		return null.NewInt(0, false), err
+		return null.NewInt(0, false), err //nolint:wrapcheck
 	}
 	return null.NewInt(i, true), nil
 }
 
+// parseFloat parses the float which may have the comma as the decimal separator.
 func parseFloat(data []byte) (null.Float, error) {
-	s := string(data)
-	if s == na || s == "" {
+	if len(data) == 0 || string(data) == na {
 		return null.NewFloat(0.0, false), nil
 	}
-	s = strings.Replace(s, ",", ".", 1)
-	f, err := strconv.ParseFloat(s, 64)
+	var buf [maxNumberLen]byte
+	if i := bytes.IndexByte(data, ','); i >= 0 && len(data) <= maxNumberLen {
+		n := copy(buf[:], data)
+		buf[i] = '.'
+		data = buf[:n]
+	}
+	f, err := strconv.ParseFloat(string(data), 64)
 	if err != nil {
-		return null.NewFloat(0.0, false), err
+		return null.NewFloat(0.0, false), err //nolint:wrapcheck
 	}
 	return null.NewFloat(f, true), nil
 }
 
 func parseString(data []byte) null.String {
-	s := string(data)
-	if s == na {
+	if string(data) == na {
 		return null.NewString("", false)
 	}
-	return null.NewString(s, true)
+	return null.NewString(string(data), true)
 }
 
+// parseCRC parses CRC16 of 3 or 4 hex digits.
 func parseCRC(data []byte) (crc uint16, err error) {
-	s := string(data)
-	if len(s)%2 != 0 {
-		s = fmt.Sprintf("0%s", s)
+	if len(data) < 3 || len(data) > 4 {
+		return 0, fmt.Errorf("invalid crc length %v", (len(data)+1)/2) //nolint:goerr113,gomnd
 	}
-	data, err = hex.DecodeString(s)
-	if err != nil {
-		return 0, fmt.Errorf("failed parse crc: %w", err)
-	}
-	if len(data) != 2 { //nolint:gomnd
-		return 0, fmt.Errorf("invalid crc length %v", len(data)) //nolint:goerr113
+	for _, c := range data {
+		var d byte
+		switch {
+		case c >= '0' && c <= '9':
+			d = c - '0'
+		case c >= 'a' && c <= 'f':
+			d = c - 'a' + 10 //nolint:gomnd
+		case c >= 'A' && c <= 'F':
+			d = c - 'A' + 10 //nolint:gomnd
+		default:
+			return 0, fmt.Errorf("failed parse crc: invalid byte %#U", rune(c)) //nolint:goerr113
+		}
+		crc = crc<<4 | uint16(d)
 	}
-	return binary.BigEndian.Uint16(data), nil
+	return crc, nil
 }
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/gotrackery/protocol/common"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
 )
 
 func Test_parseValue(t *testing.T) {
@@ -106,3 +109,69 @@ func Test_parseCRC(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		date    string
+		tm      string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "valid", date: "240123", tm: "141838", want: time.Date(2023, time.January, 24, 14, 18, 38, 0, time.UTC)},
+		{name: "leap day", date: "290224", tm: "000000", want: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{name: "last century", date: "311299", tm: "235959", want: time.Date(1999, time.December, 31, 23, 59, 59, 0, time.UTC)},
+		{name: "no leap day", date: "290223", tm: "000000", wantErr: true},
+		{name: "month", date: "011323", tm: "000000", wantErr: true},
+		{name: "hour", date: "010123", tm: "240000", wantErr: true},
+		{name: "short", date: "10123", tm: "000000", wantErr: true},
+		{name: "not digits", date: "01a123", tm: "000000", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTime([]byte(tt.date), []byte(tt.tm))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+
+	got, err := parseTime([]byte(na), []byte(na))
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), got, time.Minute)
+}
+
+func Test_parseFloat(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    null.Float
+		wantErr bool
+	}{
+		{name: "dot", data: "12.5", want: null.NewFloat(12.5, true)},
+		{name: "comma", data: "12,5", want: null.NewFloat(12.5, true)},
+		{name: "na", data: "NA", want: null.NewFloat(0, false)},
+		{name: "empty", data: "", want: null.NewFloat(0, false)},
+		{name: "invalid", data: "12,5,1", want: null.NewFloat(0, false), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFloat([]byte(tt.data))
+			assert.Equal(t, tt.wantErr, err != nil, "error %v", err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_parseAttrsReuse(t *testing.T) {
+	a, err := parseAttrs([]byte("odo:2:1.5,hw:3:V4.5"), nil)
+	assert.NoError(t, err)
+	got, err := parseAttrs([]byte("odo:2:2.5,cnt:1:7"), a)
+	assert.NoError(t, err)
+	assert.Equal(t, common.Attributes{"odo": 2.5, "cnt": int64(7)}, got)
+	assert.Equal(t, common.Attributes{"odo": 2.5, "cnt": int64(7)}, a)
+}
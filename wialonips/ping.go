@@ -0,0 +1,45 @@
+package wialonips
+
+import (
+	"fmt"
+
+	"github.com/sigurn/crc16"
+)
+
+var _ Message = (*PingMessage)(nil)
+
+// PingMessage is a WialonIPS ping message.
+// The packet carries no data, the device sends it to keep the TCP connection alive,
+// so it can be taken as a liveness signal of the device. The packet looks as follows:
+// #P#\r\n for v1.1 and #P#CRC16\r\n for v2.0 devices which append the checksum to every packet,
+// the checksum of no data is 0000.
+type PingMessage struct {
+	message
+}
+
+// Decode decodes a WialonIPS message. The data is rejected in version 1.1, in version 2.0 it must be CRC16.
+// The ping without CRC16 is accepted in both versions.
+func (p *PingMessage) Decode(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if p.ver != V2_0 {
+		p.err = ErrWialonIPSInvalidPingMessage
+		return p.err
+	}
+	p.err = validateCRC(data, fieldsDelimiter)
+	return p.err
+}
+
+// Encode encodes a WialonIPS message: no data in version 1.1 and CRC16 in version 2.0.
+func (p *PingMessage) Encode() ([]byte, error) {
+	if p.ver != V2_0 {
+		return []byte{}, nil
+	}
+	return fmt.Appendf(nil, crcFormat, crc16.Checksum(nil, crc16Table)), nil
+}
+
+// Response returns a WialonIPS response message.
+func (p *PingMessage) Response() []byte {
+	return []byte(fmt.Sprintf(responseTemplate, PingPacket, MapErrToRespCode(PingPacket, p.err)))
+}
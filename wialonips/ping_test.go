@@ -0,0 +1,49 @@
+package wialonips
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPingMessage_Decode(t *testing.T) {
+	tests := []struct {
+		name     string
+		v        Version
+		data     string
+		wantErr  error
+		wantResp string
+	}{
+		{name: "v1.1", v: V1_1, data: "#P#\r\n", wantResp: "#AP#\r\n"},
+		{name: "v1.1 payload", v: V1_1, data: "#P#hello\r\n", wantErr: ErrWialonIPSInvalidPingMessage,
+			wantResp: "#AP#\r\n"},
+		{name: "v1.1 crc", v: V1_1, data: "#P#0000\r\n", wantErr: ErrWialonIPSInvalidPingMessage,
+			wantResp: "#AP#\r\n"},
+		// Wialon IPS 2.0 specification, ping packet: #P#CRC16\r\n, CRC16 of no data is 0000.
+		{name: "v2.0 spec", v: V2_0, data: "#P#0000\r\n", wantResp: "#AP#\r\n"},
+		{name: "v2.0 no crc", v: V2_0, data: "#P#\r\n", wantResp: "#AP#\r\n"},
+		{name: "v2.0 payload", v: V2_0, data: "#P#hello\r\n", wantErr: ErrWialonIPSCRC16Validation,
+			wantResp: "#AP#\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPacket(tt.v, testIMEI)
+			err := p.Decode([]byte(tt.data))
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				data, err := p.Encode()
+				require.NoError(t, err)
+				if tt.v == V2_0 {
+					assert.Equal(t, "#P#0000\r\n", string(data))
+				} else {
+					assert.Equal(t, "#P#\r\n", string(data))
+				}
+			} else {
+				assert.True(t, errors.Is(err, tt.wantErr), "got error %v", err)
+			}
+			assert.Equal(t, tt.wantResp, string(response(p, err)))
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package wialonips
+
+import (
+	"fmt"
+
+	"github.com/gotrackery/protocol/common"
+	"github.com/peterstace/simplefeatures/geom"
+	"gopkg.in/guregu/null.v4"
+)
+
+// ProtocolName is the name of the protocol set in common.Position.
+const ProtocolName = "wialonips"
+
+// Positions converts the messages of the data packets (SD, D and B) into positions.
+func (p *Packet) Positions() []common.Position {
+	switch m := p.Message.(type) {
+	case *ShortenedDataMessage:
+		return []common.Position{m.Position()}
+	case *DataMessage:
+		return []common.Position{m.Position()}
+	case *BlackBoxMessage:
+		return m.Positions()
+	}
+	return nil
+}
+
+// Position converts the message into position. NA or out of range coordinates give the invalid position.
+func (s *ShortenedDataMessage) Position() common.Position {
+	lon, lat := s.Point.Lon.Float64(), s.Point.Lat.Float64()
+	pos := common.Position{
+		Location: common.Location{
+			Coordinates: geom.Coordinates{XY: geom.XY{X: lon, Y: lat}, Type: geom.DimXY},
+			Valid:       s.Point.Valid && lon >= -180 && lon <= 180 && lat >= -90 && lat <= 90,
+		},
+		Protocol:   ProtocolName,
+		DeviceID:   s.imei,
+		DeviceTime: s.RegisteredAt,
+		Speed:      s.Speed,
+		Course:     null.NewFloat(float64(s.Course.Int64), s.Course.Valid),
+		Attributes: common.Attributes{
+			common.Datum: string(common.WGS84),
+		},
+	}
+	if s.Altitude.Valid {
+		pos.Type = geom.DimXYZ
+		pos.Z = s.Altitude.Float64
+	}
+	if s.Sat.Valid {
+		pos.Attributes[common.Satellites] = s.Sat.Int64
+	}
+	return pos
+}
+
+// Position converts the message into position. The parameters of the message are kept as they are,
+// ADC inputs are keyed as ainput_N starting from 1, iButton is the driver identifier.
+func (d *DataMessage) Position() common.Position {
+	pos := d.ShortenedDataMessage.Position()
+	for k, v := range d.Attributes {
+		pos.Attributes[k] = v
+	}
+	if d.HDOP.Valid {
+		pos.Attributes[common.HDOP] = d.HDOP.Float64
+	}
+	if d.Inputs.Valid {
+		pos.Attributes[common.DigInput] = d.Inputs.Int64
+	}
+	if d.Outputs.Valid {
+		pos.Attributes[common.DigOutput] = d.Outputs.Int64
+	}
+	for i, v := range d.ADC {
+		if v.Valid {
+			pos.Attributes[fmt.Sprintf("%s_%d", common.AnInput, i+1)] = v.Float64
+		}
+	}
+	if d.IButton.Valid {
+		pos.Attributes[common.DriverID] = d.IButton.String
+	}
+	return pos
+}
+
+// Positions converts the accepted records into positions of the device sent the packet
+// in the order of the packet.
+func (bb *BlackBoxMessage) Positions() []common.Position {
+	result := make([]common.Position, 0, len(bb.ShortenedMessages)+len(bb.DataMessages))
+	_ = bb.records(func(sd *ShortenedDataMessage, d *DataMessage) error {
+		var pos common.Position
+		if sd != nil {
+			pos = sd.Position()
+		} else {
+			pos = d.Position()
+		}
+		pos.DeviceID = bb.imei
+		result = append(result, pos)
+		return nil
+	})
+	return result
+}
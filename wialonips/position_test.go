@@ -0,0 +1,79 @@
+package wialonips
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gotrackery/protocol/common"
+	"github.com/peterstace/simplefeatures/geom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacket_Positions(t *testing.T) {
+	p := NewPacket(V1_1, testIMEI)
+	require.NoError(t, p.Decode([]byte("#D#240123;141838;5547.7850;S;03734.7740;W;12.5;184;188;12;0.9;5;2;"+
+		"12.3,NA,4;0A12FF;odo:2:1.5,hw:3:V4.5\r\n")))
+
+	positions := p.Positions()
+	require.Len(t, positions, 1)
+	pos := positions[0]
+	assert.Equal(t, ProtocolName, pos.Protocol)
+	assert.Equal(t, testIMEI, pos.DeviceID)
+	assert.Equal(t, time.Date(2023, time.January, 24, 14, 18, 38, 0, time.UTC), pos.DeviceTime)
+	assert.True(t, pos.Valid)
+	assert.Equal(t, geom.DimXYZ, pos.Type)
+	assert.InDelta(t, -55.796416667, pos.Y, 1e-9)
+	assert.InDelta(t, -37.579566667, pos.X, 1e-9)
+	assert.Equal(t, 188.0, pos.Z)
+	assert.Equal(t, 12.5, pos.Speed.Float64)
+	assert.Equal(t, 184.0, pos.Course.Float64)
+	assert.Equal(t, common.Attributes{
+		common.Datum:      string(common.WGS84),
+		common.Satellites: int64(12),
+		common.HDOP:       0.9,
+		common.DigInput:   int64(5),
+		common.DigOutput:  int64(2),
+		"ainput_1":        12.3,
+		"ainput_3":        4.0,
+		common.DriverID:   "0A12FF",
+		"odo":             1.5,
+		"hw":              "V4.5",
+	}, pos.Attributes)
+}
+
+func TestPacket_PositionsInvalid(t *testing.T) {
+	p := NewPacket(V1_1, testIMEI)
+	require.NoError(t, p.Decode([]byte("#SD#240123;141838;NA;NA;NA;NA;NA;NA;NA;NA\r\n")))
+
+	positions := p.Positions()
+	require.Len(t, positions, 1)
+	pos := positions[0]
+	assert.False(t, pos.Valid)
+	assert.Equal(t, geom.DimXY, pos.Type)
+	assert.False(t, pos.Speed.Valid)
+	assert.False(t, pos.Course.Valid)
+	assert.Equal(t, common.Attributes{common.Datum: string(common.WGS84)}, pos.Attributes)
+}
+
+func TestBlackBoxMessage_Positions(t *testing.T) {
+	// the data record is between the shortened ones.
+	p := Packet{Version: V2_0, IMEI: testIMEI, Message: &BlackBoxMessage{
+		ShortenedMessages: []ShortenedDataMessage{testShortenedData(1), testShortenedData(3)},
+		DataMessages:      []DataMessage{testData(2)},
+		Order:             []BlackBoxRecord{{Shortened: true, Index: 0}, {Index: 0}, {Shortened: true, Index: 1}},
+	}}
+	data, err := p.Encode()
+	require.NoError(t, err)
+
+	got := NewPacket(V2_0, testIMEI)
+	require.NoError(t, got.Decode(data))
+	positions := got.Positions()
+	require.Len(t, positions, 3)
+	for i, pos := range positions {
+		assert.Equal(t, testIMEI, pos.DeviceID)
+		assert.Equal(t, i+1, pos.DeviceTime.Second())
+		assert.True(t, pos.Valid)
+	}
+	assert.Equal(t, int64(2), positions[1].Attributes["engine_hours"])
+}
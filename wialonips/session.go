@@ -0,0 +1,154 @@
+package wialonips
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Authenticator checks the password the device sends in the login packet.
+type Authenticator interface {
+	// Authenticate returns an error when the device is not allowed to send data.
+	Authenticate(imei, password string) error
+}
+
+// AuthenticatorFunc is an adapter to use the function as Authenticator.
+type AuthenticatorFunc func(imei, password string) error
+
+// Authenticate calls f(imei, password).
+func (f AuthenticatorFunc) Authenticate(imei, password string) error {
+	return f(imei, password)
+}
+
+// Session is the state of TCP connection of the device. The connection starts with the login packet,
+// the IMEI and version of the login are set to every message decoded later.
+// The data packets (SD, D and B) are rejected with ErrWialonIPSNotLoggedIn error until the device logs in:
+// #ASD#-1, #AD#-1 and #AB#0 are answered. Every packet received, the ping included, is the liveness signal
+// of the device: use LastSeen or Idle to close the idle connections. It is safe for concurrent use.
+type Session struct {
+	// Authenticator checks the password of the login, nil accepts any password.
+	Authenticator Authenticator
+
+	mu       sync.Mutex
+	imei     string
+	ver      Version
+	loggedIn bool
+	lastSeen time.Time
+	now      func() time.Time
+}
+
+// NewSession creates a new Session checking the logins by auth.
+func NewSession(auth Authenticator) *Session {
+	return &Session{Authenticator: auth}
+}
+
+// Decode decodes the packet received from the device. It returns the packet, the answer to send to the device
+// and the error of decoding or authentication. The answer is nil for the packet of unknown type.
+// The failed login resets the session.
+func (s *Session) Decode(data []byte) (Packet, []byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.now == nil {
+		s.now = time.Now
+	}
+	s.lastSeen = s.now()
+
+	p := NewPacket(s.ver, s.imei)
+	err := p.Decode(data)
+	switch {
+	case p.Type == LoginPacket:
+		err = s.login(&p, err)
+	case err == nil && !s.loggedIn && isDataPacket(p.Type):
+		p.Message = nil
+		err = fmt.Errorf("%s packet: %w", p.Type, ErrWialonIPSNotLoggedIn)
+	case err == nil:
+		p.Version, p.IMEI = s.ver, s.imei
+		if h, ok := p.Message.(header); ok {
+			h.setHeader(s.ver, s.imei)
+		}
+	}
+	return p, response(p, err), err
+}
+
+// login authenticates the device of the decoded login packet.
+func (s *Session) login(p *Packet, err error) error {
+	s.imei, s.ver, s.loggedIn = "", UnknownVersion, false
+	if err != nil {
+		return err
+	}
+
+	l, ok := p.Message.(*LoginMessage)
+	if !ok {
+		return fmt.Errorf("unexpected login message %T: %w", p.Message, ErrWialonIPSInvalidLoginMessage)
+	}
+	if s.Authenticator != nil {
+		if err = s.Authenticator.Authenticate(l.IMEI(), l.Password); err != nil {
+			l.err = fmt.Errorf("device %s: %w", l.IMEI(), errors.Join(err, ErrWialonIPSInvalidPassword))
+			return l.err
+		}
+	}
+	s.imei, s.ver, s.loggedIn = l.IMEI(), l.Version(), true
+	return nil
+}
+
+// IMEI returns IMEI of the logged in device.
+func (s *Session) IMEI() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.imei
+}
+
+// Version returns the protocol version of the logged in device.
+func (s *Session) Version() Version {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ver
+}
+
+// LoggedIn returns true if the device has logged in successfully.
+func (s *Session) LoggedIn() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.loggedIn
+}
+
+// LastSeen returns the time the last packet was received from the device, zero time if none was received.
+func (s *Session) LastSeen() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastSeen
+}
+
+// Idle returns the time passed since the last packet was received from the device till now,
+// zero if none was received.
+func (s *Session) Idle(now time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lastSeen.IsZero() {
+		return 0
+	}
+	return now.Sub(s.lastSeen)
+}
+
+// isDataPacket returns true for the packets carrying the device data.
+func isDataPacket(t PacketType) bool {
+	return t == ShortenedDataPacket || t == DataPacket || t == BlackBoxPacket
+}
+
+// response returns the answer to the packet, the message answers itself when it is decoded.
+func response(p Packet, err error) []byte {
+	if p.Type == UnknownPacket {
+		return nil
+	}
+	if p.Message != nil {
+		return p.Message.Response()
+	}
+	return []byte(fmt.Sprintf(responseTemplate, p.Type, MapErrToRespCode(p.Type, err)))
+}
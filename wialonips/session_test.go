@@ -0,0 +1,148 @@
+package wialonips
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePacket(t *testing.T, v Version, m Message) []byte {
+	t.Helper()
+	p := Packet{Version: v, IMEI: testIMEI, Message: m}
+	data, err := p.Encode()
+	require.NoError(t, err)
+	return data
+}
+
+func TestSession_Decode(t *testing.T) {
+	auth := AuthenticatorFunc(func(imei, password string) error {
+		if imei == testIMEI && password == "secret" {
+			return nil
+		}
+		return errors.New("unknown device") //nolint:goerr113
+	})
+	s := NewSession(auth)
+	sd := testShortenedData(38)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		wantErr  error
+		wantResp string
+		loggedIn bool
+	}{
+		{
+			name:     "short data before login",
+			data:     encodePacket(t, V2_0, &sd),
+			wantErr:  ErrWialonIPSNotLoggedIn,
+			wantResp: "#ASD#-1\r\n",
+		},
+		{
+			name:     "black box before login",
+			data:     encodePacket(t, V1_1, &BlackBoxMessage{ShortenedMessages: []ShortenedDataMessage{sd}}),
+			wantErr:  ErrWialonIPSNotLoggedIn,
+			wantResp: "#AB#0\r\n",
+		},
+		{
+			name:     "ping before login",
+			data:     []byte("#P#\r\n"),
+			wantResp: "#AP#\r\n",
+		},
+		{
+			name:     "wrong password",
+			data:     encodePacket(t, V2_0, &LoginMessage{Password: "guess"}),
+			wantErr:  ErrWialonIPSInvalidPassword,
+			wantResp: "#AL#01\r\n",
+		},
+		{
+			name:     "bad login crc",
+			data:     []byte("#L#2.0;866795037163746;secret;0000\r\n"),
+			wantErr:  ErrWialonIPSCRC16Validation,
+			wantResp: "#AL#10\r\n",
+		},
+		{
+			name:     "login",
+			data:     encodePacket(t, V2_0, &LoginMessage{Password: "secret"}),
+			wantResp: "#AL#1\r\n",
+			loggedIn: true,
+		},
+		{
+			name:     "short data",
+			data:     encodePacket(t, V2_0, &sd),
+			wantResp: "#ASD#1\r\n",
+			loggedIn: true,
+		},
+		{
+			name:     "short data crc",
+			data:     []byte("#SD#240123;141838;5547.7850;N;03734.7740;E;12.5;184;188;12;0000\r\n"),
+			wantErr:  ErrWialonIPSCRC16Validation,
+			wantResp: "#ASD#13\r\n",
+			loggedIn: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, resp, err := s.Decode(tt.data)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.True(t, errors.Is(err, tt.wantErr), "got error %v", err)
+			}
+			assert.Equal(t, tt.wantResp, string(resp))
+			assert.Equal(t, tt.loggedIn, s.LoggedIn())
+			if tt.loggedIn && err == nil {
+				assert.Equal(t, testIMEI, p.IMEI)
+				assert.Equal(t, V2_0, p.Version)
+				assert.Equal(t, testIMEI, p.Message.IMEI())
+				assert.Equal(t, V2_0, p.Message.Version())
+			}
+		})
+	}
+	assert.Equal(t, testIMEI, s.IMEI())
+	assert.Equal(t, V2_0, s.Version())
+}
+
+func TestSession_DecodeNoAuthenticator(t *testing.T) {
+	s := NewSession(nil)
+	_, resp, err := s.Decode(encodePacket(t, V1_1, &LoginMessage{}))
+	require.NoError(t, err)
+	assert.Equal(t, "#AL#1\r\n", string(resp))
+
+	d := testData(40)
+	p, resp, err := s.Decode(encodePacket(t, V1_1, &d))
+	require.NoError(t, err)
+	assert.Equal(t, "#AD#1\r\n", string(resp))
+	assert.Equal(t, testIMEI, p.Message.IMEI())
+	assert.Equal(t, V1_1, p.Message.Version())
+}
+
+func TestSession_LastSeen(t *testing.T) {
+	at := time.Date(2023, time.January, 24, 14, 18, 38, 0, time.UTC)
+	now := at
+	s := NewSession(nil)
+	s.now = func() time.Time { return now }
+
+	assert.True(t, s.LastSeen().IsZero())
+	assert.Equal(t, time.Duration(0), s.Idle(now))
+
+	_, _, err := s.Decode(encodePacket(t, V2_0, &LoginMessage{}))
+	require.NoError(t, err)
+	assert.Equal(t, at, s.LastSeen())
+
+	// the ping is the liveness signal.
+	now = now.Add(time.Minute)
+	_, resp, err := s.Decode([]byte("#P#0000\r\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "#AP#\r\n", string(resp))
+	assert.Equal(t, at.Add(time.Minute), s.LastSeen())
+	assert.Equal(t, 30*time.Second, s.Idle(now.Add(30*time.Second)))
+
+	// so is the packet failed to decode.
+	now = now.Add(time.Minute)
+	_, _, err = s.Decode([]byte("#P#hello\r\n"))
+	require.Error(t, err)
+	assert.Equal(t, at.Add(2*time.Minute), s.LastSeen())
+}
@@ -1,7 +1,6 @@
 package wialonips
 
 import (
-	"bytes"
 	"fmt"
 	"time"
 
@@ -28,8 +27,7 @@ type ShortenedDataMessage struct {
 
 // Decode decodes a WialonIPS message.
 func (s *ShortenedDataMessage) Decode(data []byte) error {
-	bytesSet := bytes.Split(data, fieldsDelimiter)
-	length := len(bytesSet)
+	length := countFields(data, fieldsDelimiter)
 	if (length != 10 && s.ver == V1_1) || (length != 11 && s.ver == V2_0) {
 		s.err = ErrWialonIPSInvalidDataMessage // -1
 		return s.err
@@ -42,10 +40,21 @@ func (s *ShortenedDataMessage) Decode(data []byte) error {
 		}
 	}
 
-	s.RegisteredAt, s.Point, s.Speed, s.Course, s.Altitude, s.Sat, s.err = parseBaseFields(bytesSet[0:10])
+	fields := newFieldScanner(data, fieldsDelimiter)
+	s.RegisteredAt, s.Point, s.Speed, s.Course, s.Altitude, s.Sat, s.err = parseBaseFields(&fields)
 	return s.err
 }
 
+// Encode encodes a WialonIPS message.
+func (s *ShortenedDataMessage) Encode() ([]byte, error) {
+	return s.seal(s.appendFields(nil), fieldsDelimiter), nil
+}
+
+// appendFields appends the fields of the message without CRC16.
+func (s *ShortenedDataMessage) appendFields(buf []byte) []byte {
+	return appendBaseFields(buf, s.RegisteredAt, s.Point, s.Speed, s.Course, s.Altitude, s.Sat)
+}
+
 // Response returns a WialonIPS response message.
 func (s *ShortenedDataMessage) Response() []byte {
 	return []byte(fmt.Sprintf(responseTemplate, ShortenedDataPacket, MapErrToRespCode(ShortenedDataPacket, s.err)))
@@ -38,7 +38,13 @@ func (s *Splitter) Splitter() bufio.SplitFunc {
 			s.err = common.ErrBadData
 			return 0, nil, s.err
 		}
+		if len(data) > 0 && data[0] == compressionMark {
+			return s.splitCompressed(data, atEOF)
+		}
 		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			if bytes.HasPrefix(data, imagePrefix) && i > 0 && data[i-1] == '\r' {
+				return s.splitImage(data, i, atEOF)
+			}
 			if len(data) > 1 && data[i-1] == '\r' {
 				// We have a full newline-terminated line.
 				return i + 1, data[0 : i+1], nil
@@ -55,6 +61,40 @@ func (s *Splitter) Splitter() bufio.SplitFunc {
 	}
 }
 
+// splitCompressed extracts the compressed packet which is framed by its length, not by line break.
+func (s *Splitter) splitCompressed(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	n, ok := compressedFrameLen(data)
+	if ok && len(data) >= n {
+		return n, data[0:n], nil
+	}
+	if atEOF {
+		s.badData = data
+		s.err = common.ErrBadData
+		return 0, nil, s.err
+	}
+	// Request more data.
+	return 0, nil, nil
+}
+
+// splitImage extracts the image packet which header ending at lineEnd is followed by the binary block.
+func (s *Splitter) splitImage(data []byte, lineEnd int, atEOF bool) (advance int, token []byte, err error) {
+	n, ok := imageFrameLen(data, lineEnd)
+	if !ok {
+		// Let the decoder report the broken header.
+		return lineEnd + 1, data[0 : lineEnd+1], nil
+	}
+	if len(data) >= n {
+		return n, data[0:n], nil
+	}
+	if atEOF {
+		s.badData = data
+		s.err = common.ErrBadData
+		return 0, nil, s.err
+	}
+	// Request more data.
+	return 0, nil, nil
+}
+
 func allowedFirstByte(first byte) bool {
 	return first != packetTypeDelimiter[0] &&
 		first != allowedFirstByte1 &&
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -49,6 +50,13 @@ func TestSplitter_Splitter(t *testing.T) {
 			wantLen:   9793,
 			wantErr:   assert.NoError,
 		},
+		{
+			name:      "0005.data compressed",
+			args:      args{path: "./testdata/0005.data"},
+			wantCount: 4,
+			wantLen:   303,
+			wantErr:   assert.NoError,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -209,6 +217,46 @@ func TestScanPackage(t *testing.T) {
 			wantToken:   nil,
 			wantErr:     true,
 		},
+		{
+			name: "compressed header cut",
+			args: args{
+				data:  []byte{0xFF, 0x04},
+				atEOF: false,
+			},
+			wantAdvance: 0,
+			wantToken:   nil,
+			wantErr:     false,
+		},
+		{
+			name: "compressed data cut",
+			args: args{
+				data:  []byte{0xFF, 0x04, 0x00, '\r', '\n'},
+				atEOF: false,
+			},
+			wantAdvance: 0,
+			wantToken:   nil,
+			wantErr:     false,
+		},
+		{
+			name: "compressed data cut eof",
+			args: args{
+				data:  []byte{0xFF, 0x04, 0x00, '\r', '\n'},
+				atEOF: true,
+			},
+			wantAdvance: 0,
+			wantToken:   nil,
+			wantErr:     true,
+		},
+		{
+			name: "compressed with line breaks inside",
+			args: args{
+				data:  []byte{0xFF, 0x04, 0x00, '\r', '\n', '\r', '\n', '#'},
+				atEOF: false,
+			},
+			wantAdvance: 7,
+			wantToken:   []byte{0xFF, 0x04, 0x00, '\r', '\n', '\r', '\n'},
+			wantErr:     false,
+		},
 		{
 			name: "alone break eof",
 			args: args{
@@ -237,3 +285,21 @@ func TestScanPackage(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitter_SplitterImageSize(t *testing.T) {
+	for _, size := range []string{"9223372036854775807", "-1", "1048577"} {
+		t.Run(size, func(t *testing.T) {
+			data := "#I#" + size + ";0;0;010120;000000;a.jpg\r\n#P#\r\n"
+			scanner := bufio.NewScanner(strings.NewReader(data))
+			scanner.Split(NewSplitter().Splitter())
+			var tokens []string
+			assert.NotPanics(t, func() {
+				for scanner.Scan() {
+					tokens = append(tokens, scanner.Text())
+				}
+			})
+			// the broken header is passed to the decoder as is.
+			assert.Equal(t, []string{"#I#" + size + ";0;0;010120;000000;a.jpg\r\n", "#P#\r\n"}, tokens)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package wialonips
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+var _ Message = (*TextMessage)(nil)
+
+var (
+	textEscaper   = strings.NewReplacer(`\`, `\\`, "\r", `\r`, "\n", `\n`, ";", `\;`, "#", `\#`)
+	textUnescapes = map[byte]byte{'\\': '\\', 'r': '\r', 'n': '\n', ';': ';', '#': '#'}
+)
+
+// TextMessage is a WialonIPS message to or from the driver.
+// The dispatcher sends the text to the driver's terminal and the driver replies in the same way.
+// The packet looks as follows:
+// #M#msg\r\n for v1.1 and #M#msg;CRC16\r\n for v2.0.
+// Backslash, line breaks, ';' and '#' are escaped with backslash in the text: \\, \r, \n, \;, \#.
+type TextMessage struct {
+	Text string
+	message
+}
+
+// Decode decodes a WialonIPS message.
+func (tm *TextMessage) Decode(data []byte) error {
+	if tm.ver == V2_0 {
+		tm.err = validateCRC(data, fieldsDelimiter)
+		if tm.err != nil {
+			return tm.err
+		}
+		// the delimiters of the text are escaped, so the last one precedes CRC16.
+		i := bytes.LastIndex(data, fieldsDelimiter)
+		if i < 0 {
+			i = 0
+		}
+		data = data[:i]
+	}
+	tm.Text = unescapeText(data)
+	return nil
+}
+
+// Encode encodes a WialonIPS message.
+func (tm *TextMessage) Encode() ([]byte, error) {
+	return tm.seal([]byte(textEscaper.Replace(tm.Text)), fieldsDelimiter), nil
+}
+
+// Response returns a WialonIPS response message.
+func (tm *TextMessage) Response() []byte {
+	return []byte(fmt.Sprintf(responseTemplate, TextPacket, MapErrToRespCode(TextPacket, tm.err)))
+}
+
+// unescapeText restores the escaped characters of the text, unknown escapes are kept as is.
+func unescapeText(data []byte) string {
+	var sb strings.Builder
+	sb.Grow(len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] == '\\' && i+1 < len(data) {
+			if c, ok := textUnescapes[data[i+1]]; ok {
+				sb.WriteByte(c)
+				i++
+				continue
+			}
+		}
+		sb.WriteByte(data[i])
+	}
+	return sb.String()
+}
@@ -0,0 +1,84 @@
+package wialonips
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextMessage_Decode(t *testing.T) {
+	tests := []struct {
+		name     string
+		v        Version
+		data     string
+		want     string
+		wantErr  error
+		wantResp string
+	}{
+		{
+			name:     "v1.1",
+			v:        V1_1,
+			data:     "#M#Hello\r\n",
+			want:     "Hello",
+			wantResp: "#AM#1\r\n",
+		},
+		{
+			name:     "v2.0",
+			v:        V2_0,
+			data:     "#M#Hello;EEF2\r\n",
+			want:     "Hello",
+			wantResp: "#AM#1\r\n",
+		},
+		{
+			name:     "v2.0 escaped",
+			v:        V2_0,
+			data:     "#M#" + `Stop\; go\#1\\2\r\nok;473C` + "\r\n",
+			want:     "Stop; go#1\\2\r\nok",
+			wantResp: "#AM#1\r\n",
+		},
+		{
+			name:     "v2.0 bad crc",
+			v:        V2_0,
+			data:     "#M#Hello;EEF3\r\n",
+			wantErr:  ErrWialonIPSCRC16Validation,
+			wantResp: "#AM#01\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPacket(tt.v, testIMEI)
+			err := p.Decode([]byte(tt.data))
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "got error %v", err)
+				assert.Equal(t, tt.wantResp, "#AM#"+MapErrToRespCode(TextPacket, err)+"\r\n")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, TextPacket, p.Type)
+			m, ok := p.Message.(*TextMessage)
+			if assert.True(t, ok) {
+				assert.Equal(t, tt.want, m.Text)
+				assert.Equal(t, tt.wantResp, string(m.Response()))
+			}
+		})
+	}
+}
+
+func TestTextMessage_Encode(t *testing.T) {
+	text := "Stop; go#1\\2\r\nok"
+	p := Packet{Version: V2_0, Message: &TextMessage{Text: text}}
+	data, err := p.Encode()
+	require.NoError(t, err)
+	assert.Equal(t, "#M#"+`Stop\; go\#1\\2\r\nok;473C`+"\r\n", string(data))
+
+	for _, v := range []Version{V1_1, V2_0} {
+		p = Packet{Version: v, Message: &TextMessage{Text: text}}
+		data, err = p.Encode()
+		require.NoError(t, err)
+		got := NewPacket(v, "")
+		require.NoError(t, got.Decode(data))
+		assert.Equal(t, p, got)
+	}
+}
@@ -0,0 +1,73 @@
+package wialonips
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// parseDatagramPrefix parses the prefix of UDP datagram: IMEI for version 1.1 and Protocol_version;IMEI;
+// for version 2.0.
+func (p *Packet) parseDatagramPrefix(prefix []byte) error {
+	bytesSet := bytes.Split(bytes.TrimSuffix(prefix, fieldsDelimiter), fieldsDelimiter)
+	switch len(bytesSet) {
+	case 1:
+		p.Version = V1_1
+		p.IMEI = string(bytesSet[0])
+	case 2: //nolint:gomnd
+		if string(bytesSet[0]) != V2_0.String() {
+			return fmt.Errorf("unsupported version %s: %w", string(bytesSet[0]), ErrWialonIPSInvalidDatagram)
+		}
+		p.Version = V2_0
+		p.IMEI = string(bytesSet[1])
+	default:
+		return fmt.Errorf("invalid prefix %s: %w", string(prefix), ErrWialonIPSInvalidDatagram)
+	}
+	if p.IMEI == "" {
+		return fmt.Errorf("no IMEI: %w", ErrWialonIPSInvalidDatagram)
+	}
+	p.Datagram = true
+	return nil
+}
+
+// datagramMessage validates CRC16 of version 2.0 datagram without the line break
+// and returns the message without CRC16.
+func datagramMessage(data, msg []byte) ([]byte, error) {
+	if err := validateCRC(data, fieldsDelimiter); err != nil {
+		return nil, err
+	}
+	i := bytes.LastIndex(msg, fieldsDelimiter)
+	if i < 0 {
+		return nil, fmt.Errorf("no CRC16 after message: %w", ErrWialonIPSInvalidDatagram)
+	}
+	return msg[:i], nil
+}
+
+// appendDatagramPrefix appends the prefix of UDP datagram.
+func (p *Packet) appendDatagramPrefix(buf []byte) []byte {
+	if p.Version == V2_0 {
+		buf = append(buf, V2_0.String()...)
+		buf = append(buf, fieldsDelimiter...)
+		buf = append(buf, p.IMEI...)
+		return append(buf, fieldsDelimiter...)
+	}
+	return append(buf, p.IMEI...)
+}
+
+// DatagramResponse returns the answer to UDP datagram, err is the error of Decode.
+// The answer is framed as the datagram: IMEI#AD#1\r\n for version 1.1 and
+// Protocol_version;IMEI;#AD#1;CRC16\r\n for version 2.0, so the server may answer many devices
+// from one socket. It returns nil when the packet type is unknown.
+func (p *Packet) DatagramResponse(err error) []byte {
+	if p.Type == UnknownPacket {
+		return nil
+	}
+	answer := bytes.TrimSuffix(response(*p, err), lineBreak)
+
+	result := make([]byte, 0, len(answer)+len(p.IMEI)+16) //nolint:gomnd
+	result = p.appendDatagramPrefix(result)
+	result = append(result, answer...)
+	if p.Version == V2_0 {
+		result = appendCRC(result, fieldsDelimiter)
+	}
+	return append(result, lineBreak...)
+}
@@ -0,0 +1,100 @@
+package wialonips
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacket_DecodeDatagram(t *testing.T) {
+	sd := testShortenedData(38)
+	sd.setHeader(V1_1, testIMEI)
+	sd20 := testShortenedData(38)
+	sd20.setHeader(V2_0, testIMEI)
+
+	tests := []struct {
+		name     string
+		data     string
+		want     Packet
+		wantErr  error
+		wantResp string
+	}{
+		{
+			name: "ShortData v1.1",
+			data: "866795037163746#SD#240123;141838;5547.7850;N;03734.7740;E;12.5;184;188;12\r\n",
+			want: Packet{Type: ShortenedDataPacket, Version: V1_1, IMEI: testIMEI, Message: &sd,
+				Datagram: true},
+			wantResp: "866795037163746#ASD#1\r\n",
+		},
+		{
+			name: "ShortData v2.0",
+			data: "2.0;866795037163746;#SD#240123;141838;5547.7850;N;03734.7740;E;12.5;184;188;12;843A\r\n",
+			want: Packet{Type: ShortenedDataPacket, Version: V2_0, IMEI: testIMEI, Message: &sd20,
+				Datagram: true},
+			wantResp: "2.0;866795037163746;#ASD#1;23D2\r\n",
+		},
+		{
+			name: "Ping v2.0",
+			data: "2.0;866795037163746;#P#;9314\r\n",
+			want: Packet{Type: PingPacket, Version: V2_0, IMEI: testIMEI,
+				Message: &PingMessage{message: message{imei: testIMEI, ver: V2_0}}, Datagram: true},
+			wantResp: "2.0;866795037163746;#AP#;3B2C\r\n",
+		},
+		{
+			name:     "ShortData v2.0 bad crc",
+			data:     "2.0;866795037163746;#SD#240123;141838;5547.7850;N;03734.7740;E;12.5;184;188;12;843B\r\n",
+			want:     Packet{Type: ShortenedDataPacket, Version: V2_0, IMEI: testIMEI, Datagram: true},
+			wantErr:  ErrWialonIPSCRC16Validation,
+			wantResp: "2.0;866795037163746;#ASD#13;4EE5\r\n",
+		},
+		{
+			name:    "unsupported version",
+			data:    "3.0;866795037163746;#P#;9314\r\n",
+			wantErr: ErrWialonIPSInvalidDatagram,
+		},
+		{
+			name:    "no IMEI",
+			data:    "2.0;;#P#;9314\r\n",
+			wantErr: ErrWialonIPSInvalidDatagram,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Packet
+			err := p.Decode([]byte(tt.data))
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.True(t, errors.Is(err, tt.wantErr), "got error %v", err)
+			}
+			if tt.want.Type != UnknownPacket {
+				assert.Equal(t, tt.want, p)
+			}
+			assert.Equal(t, tt.wantResp, string(p.DatagramResponse(err)))
+		})
+	}
+}
+
+func TestPacket_EncodeDatagram(t *testing.T) {
+	d := testData(40)
+	sd := testShortenedData(38)
+	for _, v := range []Version{V1_1, V2_0} {
+		for _, msg := range []Message{&d, &sd, &PingMessage{}} {
+			p := Packet{Version: v, IMEI: testIMEI, Message: msg, Datagram: true}
+			data, err := p.Encode()
+			require.NoError(t, err)
+
+			var got Packet
+			require.NoError(t, got.Decode(data), string(data))
+			assert.Equal(t, p, got)
+		}
+	}
+
+	p := Packet{Version: V2_0, IMEI: testIMEI, Message: &sd, Datagram: true}
+	data, err := p.Encode()
+	require.NoError(t, err)
+	assert.Equal(t,
+		"2.0;866795037163746;#SD#240123;141838;5547.7850;N;03734.7740;E;12.5;184;188;12;843A\r\n", string(data))
+}
@@ -0,0 +1,79 @@
+package wialonretr
+
+import "strconv"
+
+// BitSet is the state of digital inputs or outputs, the bit 0 is the first one.
+type BitSet uint32
+
+// IsSet returns true if the input or output n is on, n starts from 1 as in Wialon (in1, out1).
+func (b BitSet) IsSet(n int) bool {
+	if n < 1 || n > 32 { //nolint:gomnd
+		return false
+	}
+	return b&(1<<(n-1)) != 0
+}
+
+// DigitalInputs returns the state of digital inputs from avl_inputs block,
+// false if the packet has no such block or its value is not integer.
+func (p *Packet) DigitalInputs() (BitSet, bool) {
+	return p.bitSet(AvlInputsName)
+}
+
+// DigitalOutputs returns the state of digital outputs from avl_outputs block,
+// false if the packet has no such block or its value is not integer.
+func (p *Packet) DigitalOutputs() (BitSet, bool) {
+	return p.bitSet(AvlOutputsName)
+}
+
+// DriverID returns the driver code from avl_driver block, integer codes are formatted in decimal.
+// It returns false if the packet has no such block or the code is empty.
+func (p *Packet) DriverID() (string, bool) {
+	db, ok := p.DataBlocks.Get(AvlDriverName)
+	if !ok {
+		return "", false
+	}
+	var id string
+	switch v := db.Value.(type) {
+	case string:
+		id = v
+	case int32:
+		id = strconv.FormatInt(int64(v), 10)
+	case int64:
+		id = strconv.FormatInt(v, 10)
+	}
+	return id, id != ""
+}
+
+// Alarm returns true if the alarm bit of the packet is set, i.e. the device reports an alarm (panic button).
+func (p *Packet) Alarm() bool {
+	return p.HasAlerts()
+}
+
+func (p *Packet) bitSet(name string) (BitSet, bool) {
+	db, ok := p.DataBlocks.Get(name)
+	if !ok {
+		return 0, false
+	}
+	switch v := db.Value.(type) {
+	case int32:
+		return BitSet(v), true
+	case int64:
+		return BitSet(v), true
+	}
+	return 0, false
+}
+
+// AddDigitalInputs sets avl_inputs block with the state of digital inputs.
+func (db *DataBlocks) AddDigitalInputs(b BitSet) {
+	db.set(DataBlock{securityParam: hiddenParam, name: AvlInputsName, Value: int32(b)})
+}
+
+// AddDigitalOutputs sets avl_outputs block with the state of digital outputs.
+func (db *DataBlocks) AddDigitalOutputs(b BitSet) {
+	db.set(DataBlock{securityParam: hiddenParam, name: AvlOutputsName, Value: int32(b)})
+}
+
+// AddDriverID sets avl_driver block with the driver code.
+func (db *DataBlocks) AddDriverID(id string) {
+	db.set(DataBlock{securityParam: hiddenParam, name: AvlDriverName, Value: id})
+}
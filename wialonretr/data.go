@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+	"strings"
 )
 
 type dataType byte
@@ -30,9 +31,13 @@ const (
 	hiddenParam
 )
 
-// Data provides contract for decoding data blocks of WialonRetranslator protocol.
+// maxBlockNameLen is the maximum length of the data block name.
+const maxBlockNameLen = 38
+
+// Data provides contract for decoding and encoding data blocks of WialonRetranslator protocol.
 type Data interface {
 	Decode([]byte) error
+	Encode() ([]byte, error)
 }
 
 var _ Data = (*DataBlock)(nil)
@@ -44,27 +49,88 @@ type DataBlock struct {
 	Value         interface{}
 }
 
-type DataBlocks map[string]DataBlock
+// Name returns the name of the data block.
+func (d *DataBlock) Name() string {
+	return d.name
+}
+
+// Hidden returns true if the stealth attribute of the data block is set.
+func (d *DataBlock) Hidden() bool {
+	return d.securityParam == hiddenParam
+}
+
+// DataBlocks is the ordered collection of WialonRetranslator data blocks. The blocks are encoded in the order
+// they are added or decoded, the names may repeat.
+type DataBlocks []DataBlock
 
+// Get returns the first data block with the given name, false if there is no such block.
+func (db DataBlocks) Get(name string) (DataBlock, bool) {
+	for _, b := range db {
+		if b.name == name {
+			return b, true
+		}
+	}
+	return DataBlock{}, false
+}
+
+// GetAll returns all data blocks with the given name in their order.
+func (db DataBlocks) GetAll(name string) []DataBlock {
+	var result []DataBlock
+	for _, b := range db {
+		if b.name == name {
+			result = append(result, b)
+		}
+	}
+	return result
+}
+
+// AddPosInfo sets posinfo block, the existing one is replaced.
 func (db *DataBlocks) AddPosInfo(pi PositionInfo) {
-	db.initIfNil()
-	(*db)[PosInfoName] = DataBlock{securityParam: hiddenParam, name: PosInfoName, Value: pi}
+	db.set(DataBlock{securityParam: hiddenParam, name: PosInfoName, Value: pi})
 }
 
+// AddImage sets imag block, the existing one is replaced.
 func (db *DataBlocks) AddImage(i Image) {
-	db.initIfNil()
-	(*db)[ImageName] = DataBlock{securityParam: hiddenParam, name: ImageName, Value: i}
+	db.set(DataBlock{securityParam: shownParam, name: ImageName, Value: i})
 }
 
+// AddDataBlock appends the block with the given name. The stealth attribute of the decoded block is kept,
+// for the new one it is set for the standard blocks (posinfo, avl_inputs, avl_outputs and avl_driver).
 func (db *DataBlocks) AddDataBlock(name string, b DataBlock) {
-	db.initIfNil()
-	(*db)[b.name] = DataBlock{securityParam: determineSecParam(name), name: name, Value: b.Value}
+	sec := b.securityParam
+	if b.name == "" {
+		sec = determineSecParam(name)
+	}
+	*db = append(*db, DataBlock{securityParam: sec, name: name, Value: b.Value})
 }
 
-func (db *DataBlocks) initIfNil() {
-	if *db == nil {
-		*db = make(map[string]DataBlock)
+// set replaces the first block with the same name or appends the block.
+func (db *DataBlocks) set(b DataBlock) {
+	for i := range *db {
+		if (*db)[i].name == b.name {
+			(*db)[i] = b
+			return
+		}
+	}
+	*db = append(*db, b)
+}
+
+// bitFlags returns the bit flags of the packet having the blocks.
+func (db DataBlocks) bitFlags() uint32 {
+	var flags uint32
+	for _, b := range db {
+		switch b.name {
+		case PosInfoName:
+			flags |= infoLocationBitFlag
+		case AvlInputsName:
+			flags |= infoDigitalInputsBitFlag
+		case AvlOutputsName:
+			flags |= infoDigitalOutputsBitFlag
+		case AvlDriverName:
+			flags |= infoDriverIDBitFlag
+		}
 	}
+	return flags
 }
 
 func determineSecParam(name string) byte {
@@ -81,7 +147,11 @@ func determineSecParam(name string) byte {
 func (d *DataBlock) Decode(data []byte) error { //nolint:cyclop
 	const dataBlockHeaderLen = 6
 	if len(data) < dataBlockHeaderLen {
-		return fmt.Errorf("invalid len %d of data block", len(data)) //nolint:goerr113
+		return fmt.Errorf("data block of %d bytes: %w", len(data), ErrWialonRetranslatorBadBlockSize)
+	}
+	if size := binary.BigEndian.Uint32(data); int64(size) != int64(len(data)-4) {
+		return fmt.Errorf("data block of %d bytes, %d declared: %w", len(data)-4, size,
+			ErrWialonRetranslatorBadBlockSize)
 	}
 
 	d.securityParam = data[4]
@@ -94,10 +164,13 @@ func (d *DataBlock) Decode(data []byte) error { //nolint:cyclop
 	}
 
 	d.name = string(b)
+	if err := checkValueSize(dt, a); err != nil {
+		return fmt.Errorf("%s: %w", d.name, err)
+	}
 
 	switch dt {
 	case dataTypeText:
-		d.Value = string(a)
+		d.Value = string(bytes.TrimSuffix(a, eol))
 	case dataTypeBinary:
 		switch d.name {
 		case PosInfoName:
@@ -115,7 +188,7 @@ func (d *DataBlock) Decode(data []byte) error { //nolint:cyclop
 			d.Value = i
 			return nil
 		}
-		d.Value = a
+		d.Value = append([]byte(nil), a...)
 	case dataTypeInt32:
 		d.Value = int32(binary.BigEndian.Uint32(a))
 	case dataTypeFloat64:
@@ -128,3 +201,65 @@ func (d *DataBlock) Decode(data []byte) error { //nolint:cyclop
 
 	return nil
 }
+
+// checkValueSize checks the size of the value of fixed size data types.
+func checkValueSize(dt dataType, value []byte) error {
+	size := -1
+	switch dt {
+	case dataTypeInt32:
+		size = 4
+	case dataTypeFloat64, dataTypeInt64:
+		size = 8
+	}
+	if size >= 0 && len(value) != size {
+		return fmt.Errorf("value of type %d has %d bytes, %d expected: %w", dt, len(value), size,
+			ErrWialonRetranslatorBadBlockSize)
+	}
+	return nil
+}
+
+// Encode encodes WialonRetranslator data block into bytes starting with the block separator.
+// The data type is taken from the value: string is text, []byte, PositionInfo and Image are binary,
+// int32 is integer, float64 is double and int64 is long.
+func (d *DataBlock) Encode() ([]byte, error) {
+	if d.name == "" || len(d.name) > maxBlockNameLen || strings.IndexByte(d.name, 0) >= 0 {
+		return nil, fmt.Errorf("%q: %w", d.name, ErrWialonRetranslatorBadBlockName)
+	}
+
+	dt, value, err := d.encodeValue()
+	if err != nil {
+		return nil, fmt.Errorf("encode %s: %w", d.name, err)
+	}
+
+	size := 2 + len(d.name) + len(eol) + len(value)
+	buf := make([]byte, 0, len(blockMark)+4+size) //nolint:gomnd
+	buf = append(buf, blockMark...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(size))
+	buf = append(buf, d.securityParam, byte(dt))
+	buf = append(buf, d.name...)
+	buf = append(buf, eol...)
+	return append(buf, value...), nil
+}
+
+// encodeValue returns the data type and the bytes of the value.
+func (d *DataBlock) encodeValue() (dataType, []byte, error) {
+	switch v := d.Value.(type) {
+	case string:
+		return dataTypeText, append([]byte(v), eol...), nil
+	case []byte:
+		return dataTypeBinary, v, nil
+	case PositionInfo:
+		b, err := v.Encode()
+		return dataTypeBinary, b, err
+	case Image:
+		b, err := v.Encode()
+		return dataTypeBinary, b, err
+	case int32:
+		return dataTypeInt32, binary.BigEndian.AppendUint32(nil, uint32(v)), nil
+	case float64:
+		return dataTypeFloat64, binary.LittleEndian.AppendUint64(nil, math.Float64bits(v)), nil
+	case int64:
+		return dataTypeInt64, binary.BigEndian.AppendUint64(nil, uint64(v)), nil
+	}
+	return 0, nil, fmt.Errorf("%T: %w", d.Value, ErrWialonRetranslatorBadValue)
+}
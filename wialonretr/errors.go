@@ -8,6 +8,12 @@ import (
 )
 
 var (
-	ErrWialonRetranslatorBadDeviceID  = fmt.Errorf("failed to get device id: %w", common.ErrBadData)
-	ErrWialonRetranslatorCutBlockName = errors.New("cut block data name")
+	ErrWialonRetranslatorBadDeviceID   = fmt.Errorf("failed to get device id: %w", common.ErrBadData)
+	ErrWialonRetranslatorCutBlockName  = errors.New("cut block data name")
+	ErrWialonRetranslatorBadBlockName  = errors.New("invalid data block name")
+	ErrWialonRetranslatorBadValue      = errors.New("unsupported data block value")
+	ErrWialonRetranslatorBadBlock      = fmt.Errorf("invalid data block: %w", common.ErrBadData)
+	ErrWialonRetranslatorBadImage      = errors.New("invalid image data block")
+	ErrWialonRetranslatorBadPacketSize = fmt.Errorf("invalid packet size: %w", common.ErrBadData)
+	ErrWialonRetranslatorBadBlockSize  = fmt.Errorf("invalid data block size: %w", ErrWialonRetranslatorBadBlock)
 )
@@ -8,26 +8,49 @@ import (
 
 var _ Data = (*Image)(nil)
 
-const title = 0
+const (
+	title          = 0
+	imageHeaderLen = 12
+)
+
+// jpegMark is the start of image marker of JPEG data.
+var jpegMark = []byte{0xFF, 0xD8}
 
 // Image represents WialonRetranslator image data block.
-type Image []byte
+type Image struct {
+	// Size is the size of JPEG data declared in the block.
+	Size int
+	// Data is JPEG data.
+	Data []byte
+}
 
-// Decode decodes WialonRetranslator image data block from bytes.
+// Decode decodes WialonRetranslator image data block from bytes: the title, the size and JPEG data.
 func (i *Image) Decode(data []byte) error {
-	var img struct {
-		title uint64
-		size  uint32
-		jpeg  []byte
+	if len(data) < imageHeaderLen {
+		return fmt.Errorf("image header of %d bytes: %w", len(data), ErrWialonRetranslatorBadImage)
 	}
-	reader := bytes.NewReader(data)
-	err := binary.Read(reader, binary.BigEndian, &img)
-	if err != nil {
-		return fmt.Errorf("read title, size, jpeg: %w", err)
+	if t := binary.BigEndian.Uint64(data); t != title {
+		return fmt.Errorf("invalid title %d: %w", t, ErrWialonRetranslatorBadImage)
 	}
-	if img.title != title {
-		return fmt.Errorf("invalid title: %d", img.title) //nolint:goerr113
+	i.Size = int(binary.BigEndian.Uint32(data[8:imageHeaderLen]))
+	if len(data)-imageHeaderLen != i.Size {
+		return fmt.Errorf("image of %d bytes, %d declared: %w", len(data)-imageHeaderLen, i.Size,
+			ErrWialonRetranslatorBadImage)
 	}
-	*i = img.jpeg
+	i.Data = append([]byte(nil), data[imageHeaderLen:]...)
 	return nil
 }
+
+// Encode encodes WialonRetranslator image data block into bytes. Size is taken from the data.
+func (i *Image) Encode() ([]byte, error) {
+	i.Size = len(i.Data)
+	buf := make([]byte, 0, imageHeaderLen+len(i.Data))
+	buf = binary.BigEndian.AppendUint64(buf, title)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(i.Size))
+	return append(buf, i.Data...), nil
+}
+
+// IsJPEG returns true if the data starts with JPEG marker.
+func (i *Image) IsJPEG() bool {
+	return bytes.HasPrefix(i.Data, jpegMark)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gotrackery/protocol/common"
@@ -16,11 +17,11 @@ const (
 )
 
 const (
-	infoLocationBitFlag uint32 = 1 << iota
-	infoDigitalInputsBitFlag
-	infoDigitalOutputsBitFlag
-	infoAlertBitsBitFlag
-	infoDriverIDBitFlag
+	infoLocationBitFlag       uint32 = 0x01
+	infoDigitalInputsBitFlag  uint32 = 0x02
+	infoDigitalOutputsBitFlag uint32 = 0x04
+	infoAlertBitsBitFlag      uint32 = 0x10
+	infoDriverIDBitFlag       uint32 = 0x20
 )
 
 var (
@@ -37,8 +38,19 @@ type Packet struct {
 	err          error
 }
 
-// Decode decodes WialonRetranslator data packet from bytes.
+// Decode decodes WialonRetranslator data packet from bytes. The packet size declared in the header
+// must match the size of data, each block must fit the packet.
 func (p *Packet) Decode(data []byte) error {
+	if len(data) < packageHeaderLen {
+		p.err = fmt.Errorf("packet of %d bytes: %w", len(data), ErrWialonRetranslatorBadPacketSize)
+		return p.err
+	}
+	if size := binary.LittleEndian.Uint32(data); int64(size) != int64(len(data)-packageHeaderLen) {
+		p.err = fmt.Errorf("packet body of %d bytes, %d declared: %w", len(data)-packageHeaderLen, size,
+			ErrWialonRetranslatorBadPacketSize)
+		return p.err
+	}
+
 	body := data[packageHeaderLen:] // skip header length
 	b, a, f := bytes.Cut(body, eol) // get imei
 	if !f {
@@ -61,21 +73,84 @@ func (p *Packet) Decode(data []byte) error {
 		return p.err
 	}
 
-	p.DataBlocks = make(map[string]DataBlock)
+	p.DataBlocks = nil
 	scanner := bufio.NewScanner(buf)
-	scanner.Split(scanBlock)
+	// the image block may be longer than the default buffer of the scanner.
+	scanner.Buffer(nil, len(a)+1)
+	scanner.Split(scanSizedBlock)
 	for scanner.Scan() {
 		var db DataBlock
 		if err := db.Decode(scanner.Bytes()); err != nil {
-			p.err = fmt.Errorf("decode data block: %w", errors.Join(err, common.ErrBadData))
+			p.err = fmt.Errorf("decode data block %d: %w", len(p.DataBlocks), errors.Join(err, common.ErrBadData))
 			return p.err
 		}
-		p.DataBlocks[db.name] = db
+		p.DataBlocks = append(p.DataBlocks, db)
+	}
+	if err := scanner.Err(); err != nil {
+		p.err = fmt.Errorf("scan data block %d: %w", len(p.DataBlocks), err)
+		return p.err
 	}
 
 	return nil
 }
 
+// Encode encodes WialonRetranslator data packet into bytes. The bit flags of the location, digital inputs,
+// outputs and driver id are set by the presence of their blocks in addition to the decoded ones.
+// The blocks are encoded in their order.
+func (p *Packet) Encode() ([]byte, error) {
+	if p.DeviceID == "" || strings.IndexByte(p.DeviceID, 0) >= 0 {
+		p.err = ErrWialonRetranslatorBadDeviceID
+		return nil, p.err
+	}
+
+	buf := make([]byte, packageHeaderLen, 256) //nolint:gomnd
+	buf = append(buf, p.DeviceID...)
+	buf = append(buf, eol...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(p.RegisteredAt.Unix()))
+	buf = binary.BigEndian.AppendUint32(buf, p.bitFlags|p.DataBlocks.bitFlags())
+
+	for i := range p.DataBlocks {
+		b, err := p.DataBlocks[i].Encode()
+		if err != nil {
+			p.err = fmt.Errorf("encode data block: %w", err)
+			return nil, p.err
+		}
+		buf = append(buf, b...)
+	}
+
+	binary.LittleEndian.PutUint32(buf, uint32(len(buf)-packageHeaderLen))
+	return buf, nil
+}
+
+// SetAlert sets or clears the alarm bit of the packet.
+func (p *Packet) SetAlert(on bool) {
+	if on {
+		p.bitFlags |= infoAlertBitsBitFlag
+	} else {
+		p.bitFlags &^= infoAlertBitsBitFlag
+	}
+}
+
+// PositionInfo returns the location of posinfo block, false if the packet has no such block.
+func (p *Packet) PositionInfo() (PositionInfo, bool) {
+	db, ok := p.DataBlocks.Get(PosInfoName)
+	if !ok {
+		return PositionInfo{}, false
+	}
+	pi, ok := db.Value.(PositionInfo)
+	return pi, ok
+}
+
+// Image returns the image of the packet, false if the packet has no image block.
+func (p *Packet) Image() (Image, bool) {
+	db, ok := p.DataBlocks.Get(ImageName)
+	if !ok {
+		return Image{}, false
+	}
+	img, ok := db.Value.(Image)
+	return img, ok
+}
+
 // Response returns WialonRetranslator response bytes.
 func (p *Packet) Response() []byte {
 	return []byte{0x11}
@@ -111,7 +186,7 @@ func (p *Packet) HasDriverID() bool {
 	return p.bitFlags&infoDriverIDBitFlag != 0
 }
 
-// Not suitable for image data block.
+// scanBlock splits the data blocks by the block separator. Not suitable for image data block.
 func scanBlock(data []byte, atEOF bool) (advance int, token []byte, err error) {
 	if atEOF && len(data) == 0 {
 		return 0, nil, nil
@@ -129,35 +204,25 @@ func scanBlock(data []byte, atEOF bool) (advance int, token []byte, err error) {
 	return 0, nil, nil
 }
 
-// Alternative implementation. More accuracy (suitable for image block) but less fast.
-// func scanBlock(data []byte, atEOF bool) (advance int, token []byte, err error) {
-// 	const blockHeaderLen = 6
-// 	if atEOF && len(data) == 0 {
-// 		return 0, nil, nil
-// 	}
-//
-// 	// if there is no blockMark then it's not a Wialon Retranslator packet.
-// 	if data[0] != blockMark[0] || data[1] != blockMark[1] {
-// 		fmt.Println(data[0:2])
-// 		return 0, nil, protocol.ErrInconsistentData
-// 	}
-//
-// 	if len(data) < blockHeaderLen && !atEOF { // wait to get whole block header
-// 		return 0, nil, nil
-// 	}
-//
-// 	blockLen := binary.BigEndian.Uint32(data[2:6])
-// 	ln := int(blockHeaderLen + blockLen)
-// 	if len(data) < ln && !atEOF { // wait to get whole block
-// 		return 0, nil, nil
-// 	}
-//
-// 	if len(data) >= ln {
-// 		return ln, data[:ln], nil
-// 	}
-//
-// 	if atEOF { // return rest of data
-// 		return len(data), data, nil
-// 	}
-// 	return 0, nil, nil
-// }
+// scanSizedBlock splits the data blocks by the block size, the token is the block without the separator.
+// Unlike scanBlock it is suitable for the blocks containing the separator bytes such as images.
+func scanSizedBlock(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	const blockHeaderLen = 6
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if len(data) >= len(blockMark) && !bytes.HasPrefix(data, blockMark) {
+		return 0, nil, fmt.Errorf("no block separator: %w", ErrWialonRetranslatorBadBlock)
+	}
+
+	if len(data) >= blockHeaderLen {
+		ln := blockHeaderLen + int(binary.BigEndian.Uint32(data[len(blockMark):blockHeaderLen]))
+		if len(data) >= ln {
+			return ln, data[len(blockMark):ln], nil
+		}
+	}
+	if atEOF {
+		return 0, nil, fmt.Errorf("truncated block of %d bytes: %w", len(data), ErrWialonRetranslatorBadBlockSize)
+	}
+	return 0, nil, nil
+}
@@ -4,11 +4,15 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gotrackery/protocol/common"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -197,6 +201,16 @@ func Test_scanBlockRealData(t *testing.T) {
 	}
 }
 
+// testJPEG is a synthetic JPEG: the markers of JFIF header with the block separator bytes inside
+// to check the framing of image blocks by size. It is not a capture from a device.
+var testJPEG, _ = hex.DecodeString("ffd8ffe000104a46494600010100000100010000ffdb00430008060607060508070707" +
+	"0909080bbb000000050bbbffd9")
+
+// imageFrame is the packet of the controller of testdata/0001.data with the image block followed by its
+// posinfo block. The image is video-001.221212.jpeg of Go image package test data, it contains the block
+// separator bytes.
+var imageFrame, _ = os.ReadFile("./testdata/0005.data")
+
 func TestPacket_Decode(t *testing.T) {
 	type args struct {
 		data string
@@ -218,8 +232,8 @@ func TestPacket_Decode(t *testing.T) {
 			want: Packet{
 				DeviceID:     "353976013445485",
 				RegisteredAt: time.Unix(1565613499, 0),
-				DataBlocks: map[string]DataBlock{
-					PosInfoName: {
+				DataBlocks: DataBlocks{
+					{
 						securityParam: hiddenParam,
 						name:          PosInfoName,
 						Value: PositionInfo{
@@ -231,12 +245,12 @@ func TestPacket_Decode(t *testing.T) {
 							Sats:   11,
 						},
 					},
-					"pwr_ext": {
+					{
 						securityParam: shownParam,
 						name:          "pwr_ext",
 						Value:         27.593,
 					},
-					AvlInputsName: {
+					{
 						securityParam: hiddenParam,
 						name:          AvlInputsName,
 						Value:         int32(1),
@@ -258,8 +272,8 @@ func TestPacket_Decode(t *testing.T) {
 			want: Packet{
 				DeviceID:     "301785",
 				RegisteredAt: time.Unix(1681216191, 0),
-				DataBlocks: map[string]DataBlock{
-					PosInfoName: {
+				DataBlocks: DataBlocks{
+					{
 						securityParam: hiddenParam,
 						name:          PosInfoName,
 						Value: PositionInfo{
@@ -271,12 +285,12 @@ func TestPacket_Decode(t *testing.T) {
 							Sats:   18,
 						},
 					},
-					"pwr_ext": {
+					{
 						securityParam: shownParam,
 						name:          "pwr_ext",
 						Value:         26.149,
 					},
-					"pwr_int": {
+					{
 						securityParam: shownParam,
 						name:          "pwr_int",
 						Value:         4.361,
@@ -292,6 +306,73 @@ func TestPacket_Decode(t *testing.T) {
 			wantHasDriverID:       false,
 			wantErr:               false,
 		},
+		{
+			// synthetic frame: the posinfo block of "another example" and the image block of testJPEG.
+			name: "image synthetic",
+			args: args{data: "8500000033303137383500643552bf000000010bbb000000270102706f73696e666f00840d4faf94fd4b401b12f758fad44a400000000000000000000000aa120bbb000000430002696d616700000000000000000000000030ffd8ffe000104a46494600010100000100010000ffdb004300080606070605080707070909080bbb000000050bbbffd9"},
+			want: Packet{
+				DeviceID:     "301785",
+				RegisteredAt: time.Unix(1681216191, 0),
+				DataBlocks: DataBlocks{
+					{
+						securityParam: hiddenParam,
+						name:          PosInfoName,
+						Value: PositionInfo{
+							Lon:    55.9811,
+							Lat:    53.66389,
+							Alt:    0.0,
+							Speed:  0,
+							Course: 170,
+							Sats:   18,
+						},
+					},
+					{
+						securityParam: shownParam,
+						name:          ImageName,
+						Value: Image{
+							Size: 48,
+							Data: testJPEG,
+						},
+					},
+				},
+				bitFlags: 1,
+				err:      nil,
+			},
+			wantHasLocation: true,
+		},
+		{
+			name: "image",
+			args: args{data: hex.EncodeToString(imageFrame)},
+			want: Packet{
+				DeviceID:     "862631037611646",
+				RegisteredAt: time.Unix(1681283289, 0),
+				DataBlocks: DataBlocks{
+					{
+						securityParam: shownParam,
+						name:          ImageName,
+						Value: Image{
+							Size: 19263,
+							Data: imageFrame[53 : 53+19263],
+						},
+					},
+					{
+						securityParam: shownParam,
+						name:          PosInfoName,
+						Value: PositionInfo{
+							Lon:    37.526798,
+							Lat:    56.412445,
+							Alt:    0.0,
+							Speed:  0,
+							Course: 223,
+							Sats:   11,
+						},
+					},
+				},
+				bitFlags: 1,
+				err:      nil,
+			},
+			wantHasLocation: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -322,6 +403,234 @@ func TestPacket_Decode(t *testing.T) {
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Decode() got = %v, want %v", got, tt.want)
 			}
+			if img, ok := got.Image(); ok {
+				assert.True(t, img.IsJPEG(), "Decode() got.Image().IsJPEG()")
+			}
 		})
 	}
 }
+
+func TestPacket_Encode(t *testing.T) {
+	var p Packet
+	p.DeviceID = "353976013445485"
+	p.RegisteredAt = time.Unix(1565613499, 0)
+	p.DataBlocks.AddPosInfo(PositionInfo{Lon: 49.1903648, Lat: 55.7305664, Alt: 106.0, Speed: 54, Course: 326, Sats: 11})
+	p.DataBlocks.AddDataBlock("pwr_ext", DataBlock{Value: 27.593})
+	p.DataBlocks.AddDigitalInputs(1)
+
+	got, err := p.Encode()
+	require.NoError(t, err)
+	// the spec example.
+	assert.Equal(t, "74000000333533393736303133343435343835005d515dbb00000003"+
+		"0bbb000000270102706f73696e666f00a027afdf5d9848403ac7253383dd4b400000000000805a40003601460b"+
+		"0bbb0000001200047077725f657874002b8716d9ce973b40"+
+		"0bbb00000011010361766c5f696e707574730000000001", hex.EncodeToString(got))
+}
+
+func TestDataBlock_Encode(t *testing.T) {
+	tests := []struct {
+		name    string
+		block   DataBlock
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "text",
+			block: DataBlock{name: "im1", Value: "318"},
+			want:  "0bbb0000000a0001696d310033313800",
+		},
+		{
+			name:  "binary",
+			block: DataBlock{name: "raw", Value: []byte{0x01, 0x02}},
+			want:  "0bbb000000080002726177000102",
+		},
+		{
+			name:  "int64",
+			block: DataBlock{name: "msg_number", Value: int64(776073)},
+			want:  "0bbb0000001500056d73675f6e756d6265720000000000000bd789",
+		},
+		{
+			name:    "unsupported value",
+			block:   DataBlock{name: "flag", Value: true},
+			wantErr: ErrWialonRetranslatorBadValue,
+		},
+		{
+			name:    "long name",
+			block:   DataBlock{name: strings.Repeat("n", maxBlockNameLen+1), Value: int32(1)},
+			wantErr: ErrWialonRetranslatorBadBlockName,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.block.Encode()
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "got error %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, hex.EncodeToString(got))
+
+			var db DataBlock
+			require.NoError(t, db.Decode(got[len(blockMark):]))
+			assert.Equal(t, tt.block, db)
+		})
+	}
+}
+
+func TestPacket_EncodeRoundTrip(t *testing.T) {
+	for _, name := range []string{"0001", "0002", "0003", "0004", "0005"} {
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile("./testdata/" + name + ".data")
+			require.NoError(t, err)
+
+			scanner := bufio.NewScanner(bytes.NewReader(data))
+			scanner.Buffer(nil, 1<<20)
+			scanner.Split(NewSplitter().Splitter())
+			for scanner.Scan() {
+				var want Packet
+				require.NoError(t, want.Decode(scanner.Bytes()))
+
+				b, err := want.Encode()
+				require.NoError(t, err)
+				assert.Equal(t, scanner.Bytes(), b)
+				var got Packet
+				require.NoError(t, got.Decode(b))
+				assert.Equal(t, want, got)
+			}
+			require.NoError(t, scanner.Err())
+		})
+	}
+}
+
+func TestPacket_Image(t *testing.T) {
+	var p Packet
+	p.DeviceID = "301785"
+	p.RegisteredAt = time.Unix(1681216191, 0)
+	p.DataBlocks.AddImage(Image{Data: testJPEG})
+	data, err := p.Encode()
+	require.NoError(t, err)
+
+	var got Packet
+	require.NoError(t, got.Decode(data))
+	img, ok := got.Image()
+	require.True(t, ok)
+	assert.Equal(t, Image{Size: len(testJPEG), Data: testJPEG}, img)
+	assert.True(t, img.IsJPEG())
+
+	_, ok = (&Packet{}).Image()
+	assert.False(t, ok)
+}
+
+func TestImage_DecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "short header", data: "0000000000000000000000"},
+		{name: "title", data: "000000000000000100000001ff"},
+		{name: "truncated", data: "000000000000000000000003ffd8"},
+		{name: "extra data", data: "000000000000000000000001ffd8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := hex.DecodeString(tt.data)
+			require.NoError(t, err)
+			var img Image
+			err = img.Decode(b)
+			assert.True(t, errors.Is(err, ErrWialonRetranslatorBadImage), "got error %v", err)
+		})
+	}
+}
+
+func TestPacket_DecodeTruncatedBlock(t *testing.T) {
+	// the image block declares more bytes than the packet has.
+	b, err := hex.DecodeString("2000000033303137383500643552bf000000000bbb000000430002696d61670000000000")
+	require.NoError(t, err)
+	var p Packet
+	err = p.Decode(b)
+	assert.True(t, errors.Is(err, ErrWialonRetranslatorBadBlock), "got error %v", err)
+}
+
+func TestPacket_DecodeSizeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr error
+	}{
+		{
+			name:    "no header",
+			data:    "7400",
+			wantErr: ErrWialonRetranslatorBadPacketSize,
+		},
+		{
+			name:    "packet longer than declared",
+			data:    "0e00000033303137383500643552bf0000000000",
+			wantErr: ErrWialonRetranslatorBadPacketSize,
+		},
+		{
+			name:    "packet shorter than declared",
+			data:    "1000000033303137383500643552bf00000000",
+			wantErr: ErrWialonRetranslatorBadPacketSize,
+		},
+		{
+			name:    "no block separator",
+			data:    "1300000033303137383500643552bf000000000bbc0000",
+			wantErr: ErrWialonRetranslatorBadBlock,
+		},
+		{
+			name:    "short int value",
+			data:    "2100000033303137383500643552bf000000000bbb0000000c0103617669735f696e000001",
+			wantErr: ErrWialonRetranslatorBadBlockSize,
+		},
+		{
+			name:    "short posinfo",
+			data:    "1f00000033303137383500643552bf000000010bbb0000000a0102706f73696e666f00",
+			wantErr: ErrWialonRetranslatorBadBlockSize,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := hex.DecodeString(tt.data)
+			require.NoError(t, err)
+			var p Packet
+			err = p.Decode(b)
+			assert.True(t, errors.Is(err, tt.wantErr), "got error %v", err)
+			assert.True(t, errors.Is(err, common.ErrBadData), "got error %v", err)
+		})
+	}
+}
+
+func TestDataBlocks(t *testing.T) {
+	var db DataBlocks
+	db.AddDataBlock("temp", DataBlock{Value: 21.5})
+	db.AddDataBlock(AvlInputsName, DataBlock{Value: int32(1)})
+	db.AddDataBlock("temp", DataBlock{Value: 22.5})
+	db.AddPosInfo(PositionInfo{Lon: 1})
+	db.AddPosInfo(PositionInfo{Lon: 2})
+
+	names := make([]string, 0, len(db))
+	for i := range db {
+		names = append(names, db[i].Name())
+	}
+	assert.Equal(t, []string{"temp", AvlInputsName, "temp", PosInfoName}, names)
+
+	b, ok := db.Get("temp")
+	require.True(t, ok)
+	assert.Equal(t, 21.5, b.Value)
+	assert.False(t, b.Hidden())
+	assert.Len(t, db.GetAll("temp"), 2)
+	_, ok = db.Get("none")
+	assert.False(t, ok)
+
+	in, ok := db.Get(AvlInputsName)
+	require.True(t, ok)
+	assert.True(t, in.Hidden())
+	pi, ok := db.Get(PosInfoName)
+	require.True(t, ok)
+	assert.Equal(t, PositionInfo{Lon: 2}, pi.Value)
+
+	// the stealth attribute of the decoded block is kept.
+	var fwd DataBlocks
+	fwd.AddDataBlock("pwr_ext", DataBlock{securityParam: hiddenParam, name: "pwr_ext", Value: 27.593})
+	assert.True(t, fwd[0].Hidden())
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"math"
 )
 
 var _ Data = (*PositionInfo)(nil)
@@ -18,8 +19,15 @@ type PositionInfo struct {
 	Sats   int8
 }
 
+// posInfoLen is the size of posinfo data block value.
+const posInfoLen = 8*3 + 2*2 + 1
+
 // Decode decodes WialonRetranslator posinfo data block from bytes.
 func (p *PositionInfo) Decode(data []byte) error {
+	if len(data) != posInfoLen {
+		return fmt.Errorf("posinfo of %d bytes, %d expected: %w", len(data), posInfoLen,
+			ErrWialonRetranslatorBadBlockSize)
+	}
 	var (
 		le struct {
 			Lon float64
@@ -51,3 +59,14 @@ func (p *PositionInfo) Decode(data []byte) error {
 
 	return nil
 }
+
+// Encode encodes WialonRetranslator posinfo data block into bytes.
+func (p *PositionInfo) Encode() ([]byte, error) {
+	buf := make([]byte, 0, posInfoLen)
+	buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(p.Lon))
+	buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(p.Lat))
+	buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(p.Alt))
+	buf = binary.BigEndian.AppendUint16(buf, uint16(p.Speed))
+	buf = binary.BigEndian.AppendUint16(buf, uint16(p.Course))
+	return append(buf, byte(p.Sats)), nil
+}
@@ -0,0 +1,70 @@
+package wialonretr
+
+import (
+	"github.com/gotrackery/protocol/common"
+	"github.com/peterstace/simplefeatures/geom"
+	"gopkg.in/guregu/null.v4"
+)
+
+// ProtocolName is the name of the protocol set in common.Position.
+const ProtocolName = "wialonretr"
+
+// Position converts the packet into position. The location, speed and course are taken from posinfo block,
+// the position is invalid without it. Text, integer and double blocks are kept in attributes by their names,
+// the binary ones are skipped. The inputs, outputs, driver code and alarm bit are set as common attributes.
+func (p *Packet) Position() common.Position {
+	pos := common.Position{
+		Protocol:   ProtocolName,
+		DeviceID:   p.DeviceID,
+		DeviceTime: p.RegisteredAt,
+		Attributes: common.Attributes{},
+	}
+	for _, db := range p.DataBlocks {
+		name := db.name
+		if _, ok := pos.Attributes[name]; ok {
+			continue // the first block of the name is used as Get does.
+		}
+		switch v := db.Value.(type) {
+		case string:
+			pos.Attributes[name] = v
+		case int32:
+			pos.Attributes[name] = int64(v)
+		case int64:
+			pos.Attributes[name] = v
+		case float64:
+			pos.Attributes[name] = v
+		}
+	}
+	delete(pos.Attributes, AvlInputsName)
+	delete(pos.Attributes, AvlOutputsName)
+	delete(pos.Attributes, AvlDriverName)
+
+	if pi, ok := p.PositionInfo(); ok {
+		setPositionInfo(&pos, pi)
+	}
+	if in, ok := p.DigitalInputs(); ok {
+		pos.Attributes[common.DigInput] = int64(in)
+	}
+	if out, ok := p.DigitalOutputs(); ok {
+		pos.Attributes[common.DigOutput] = int64(out)
+	}
+	if id, ok := p.DriverID(); ok {
+		pos.Attributes[common.DriverID] = id
+	}
+	if p.Alarm() {
+		pos.Attributes[common.Alarm] = int64(1)
+	}
+	return pos
+}
+
+// setPositionInfo sets the location, speed and course of posinfo block.
+func setPositionInfo(pos *common.Position, pi PositionInfo) {
+	pos.Location = common.Location{
+		Coordinates: geom.Coordinates{XY: geom.XY{X: pi.Lon, Y: pi.Lat}, Z: pi.Alt, Type: geom.DimXYZ},
+		Valid:       pi.Lon >= -180 && pi.Lon <= 180 && pi.Lat >= -90 && pi.Lat <= 90,
+	}
+	pos.Speed = null.FloatFrom(float64(pi.Speed))
+	pos.Course = null.FloatFrom(float64(pi.Course))
+	pos.Attributes[common.Datum] = string(common.WGS84)
+	pos.Attributes[common.Satellites] = int64(pi.Sats)
+}
@@ -0,0 +1,90 @@
+package wialonretr
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/gotrackery/protocol/common"
+	"github.com/peterstace/simplefeatures/geom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestPacket_Position(t *testing.T) {
+	b, err := hex.DecodeString("74000000333533393736303133343435343835005D515DBB000000030BBB000000270102706F73696E666F00A0" +
+		"27AFDF5D9848403AC7253383DD4B400000000000805A40003601460B0BBB0000001200047077725F657874002B8716D9CE973B400BBB0000" +
+		"0011010361766C5F696E707574730000000001")
+	require.NoError(t, err)
+	var p Packet
+	require.NoError(t, p.Decode(b))
+
+	in, ok := p.DigitalInputs()
+	require.True(t, ok)
+	assert.True(t, in.IsSet(1))
+	assert.False(t, in.IsSet(2))
+	_, ok = p.DigitalOutputs()
+	assert.False(t, ok)
+	_, ok = p.DriverID()
+	assert.False(t, ok)
+	assert.False(t, p.Alarm())
+
+	want := common.Position{
+		Location: common.Location{
+			Coordinates: geom.Coordinates{XY: geom.XY{X: 49.1903648, Y: 55.7305664}, Z: 106, Type: geom.DimXYZ},
+			Valid:       true,
+		},
+		Protocol:   ProtocolName,
+		DeviceID:   "353976013445485",
+		DeviceTime: time.Unix(1565613499, 0),
+		Speed:      null.FloatFrom(54),
+		Course:     null.FloatFrom(326),
+		Attributes: common.Attributes{
+			common.Datum:      string(common.WGS84),
+			common.Satellites: int64(11),
+			common.DigInput:   int64(1),
+			"pwr_ext":         27.593,
+		},
+	}
+	assert.Equal(t, want, p.Position())
+}
+
+func TestPacket_PositionAVL(t *testing.T) {
+	p := Packet{DeviceID: "301785", RegisteredAt: time.Unix(1681216191, 0)}
+	p.DataBlocks.AddDigitalInputs(0x05)
+	p.DataBlocks.AddDigitalOutputs(0x80000000)
+	p.DataBlocks.AddDriverID("0000157BF1A3")
+	p.DataBlocks.AddDataBlock("counter", DataBlock{Value: int64(42)})
+	p.DataBlocks.AddDataBlock("counter", DataBlock{Value: int64(43)})
+	p.DataBlocks.AddDataBlock("raw", DataBlock{Value: []byte{0x01}})
+	p.SetAlert(true)
+	data, err := p.Encode()
+	require.NoError(t, err)
+
+	var got Packet
+	require.NoError(t, got.Decode(data))
+	assert.True(t, got.HasDigitalInputs())
+	assert.True(t, got.HasDigitalOutputs())
+	assert.True(t, got.HasDriverID())
+	assert.True(t, got.Alarm())
+
+	out, ok := got.DigitalOutputs()
+	require.True(t, ok)
+	assert.True(t, out.IsSet(32))
+	assert.False(t, out.IsSet(33))
+
+	want := common.Position{
+		Protocol:   ProtocolName,
+		DeviceID:   "301785",
+		DeviceTime: time.Unix(1681216191, 0),
+		Attributes: common.Attributes{
+			common.DigInput:  int64(5),
+			common.DigOutput: int64(0x80000000),
+			common.DriverID:  "0000157BF1A3",
+			common.Alarm:     int64(1),
+			"counter":        int64(42),
+		},
+	}
+	assert.Equal(t, want, got.Position())
+}